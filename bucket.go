@@ -41,6 +41,9 @@ type Bucket struct {
 	Description         string        `json:"description"`
 	RetentionPolicyName string        `json:"rp,omitempty"` // This to support v1 sources
 	RetentionPeriod     time.Duration `json:"retentionPeriod"`
+	// ShardGroupDuration is the duration of time covered by each shard group
+	// of the bucket, zero implies the default shard group duration.
+	ShardGroupDuration time.Duration `json:"shardGroupDuration,omitempty"`
 	CRUDLog
 }
 
@@ -104,6 +107,10 @@ type BucketUpdate struct {
 	Name            *string        `json:"name,omitempty"`
 	Description     *string        `json:"description,omitempty"`
 	RetentionPeriod *time.Duration `json:"retentionPeriod,omitempty"`
+	// ShardGroupDuration of zero resets the bucket to the default shard group duration.
+	// Over HTTP the shard group duration is only updated when the retention rule of
+	// the update has a positive one, a zero duration leaves the bucket's as is.
+	ShardGroupDuration *time.Duration `json:"shardGroupDuration,omitempty"`
 }
 
 // BucketFilter represents a set of filter that restrict the returned results.
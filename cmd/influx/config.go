@@ -16,7 +16,7 @@ func cmdConfig(f *globalFlags, opt genericCLIOpts) *cobra.Command {
 	builder := cmdConfigBuilder{
 		genericCLIOpts: opt,
 		globalFlags:    f,
-		svc: config.LocalConfigsSVC{
+		svc: &config.LocalConfigsSVC{
 			Path: path,
 			Dir:  dir,
 		},
@@ -34,6 +34,9 @@ type cmdConfigBuilder struct {
 	token  string
 	active bool
 	org    string
+	verify bool
+	host   string
+	format string
 
 	json        bool
 	hideHeaders bool
@@ -100,6 +103,8 @@ func (b *cmdConfigBuilder) cmdCreate() *cobra.Command {
 
 	cmd.Flags().BoolVarP(&b.active, "active", "a", false, "Set it to be the active config")
 	cmd.Flags().StringVarP(&b.org, "org", "o", "", "The optional organization name")
+	b.registerFormatFlag(cmd)
+	cmd.Flags().BoolVar(&b.verify, "verify", false, "Verify the url and token are valid before creating the config")
 	return cmd
 }
 
@@ -114,6 +119,7 @@ func (b *cmdConfigBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
 		Token:  b.token,
 		Org:    b.org,
 		Active: b.active,
+		Format: config.OutputFormat(b.format),
 	}
 	if _, ok := pp[b.name]; ok {
 		return &influxdb.Error{
@@ -121,6 +127,15 @@ func (b *cmdConfigBuilder) cmdCreateRunEFn(*cobra.Command, []string) error {
 			Msg:  fmt.Sprintf("name %q already exists", b.name),
 		}
 	}
+	if err := (config.Configs{b.name: p}).Validate(); err != nil {
+		return err
+	}
+
+	if b.verify {
+		if err := b.svc.PingConfig(p); err != nil {
+			return err
+		}
+	}
 
 	pp[b.name] = p
 	if p.Active {
@@ -193,6 +208,7 @@ func (b *cmdConfigBuilder) cmdUpdate() *cobra.Command {
 	cmd.Flags().StringVarP(&b.url, "url", "u", "", "The config url (required)")
 	cmd.Flags().BoolVarP(&b.active, "active", "a", false, "Set it to be the active config")
 	cmd.Flags().StringVarP(&b.org, "org", "o", "", "The optional organization name")
+	b.registerFormatFlag(cmd)
 	return cmd
 }
 
@@ -218,6 +234,12 @@ func (b *cmdConfigBuilder) cmdUpdateRunEFn(*cobra.Command, []string) error {
 	if b.org != "" {
 		p0.Org = b.org
 	}
+	if b.format != "" {
+		p0.Format = config.OutputFormat(b.format)
+	}
+	if err := (config.Configs{b.name: p0}).Validate(); err != nil {
+		return err
+	}
 
 	pp[b.name] = p0
 	if b.active {
@@ -243,11 +265,19 @@ func (b *cmdConfigBuilder) cmdList() *cobra.Command {
 	cmd.Aliases = []string{"ls"}
 	cmd.Short = "List configs"
 	b.registerPrintFlags(cmd)
+	cmd.Flags().StringVar(&b.host, "host", "", "Only list the configs with a url pointing at the host")
 	return cmd
 }
 
 func (b *cmdConfigBuilder) cmdListRunEFn(*cobra.Command, []string) error {
-	pp, err := b.svc.ParseConfigs()
+	listFn := b.svc.ParseConfigs
+	if b.host != "" {
+		listFn = func() (config.Configs, error) {
+			return b.svc.ListConfigsByHost(b.host)
+		}
+	}
+
+	pp, err := listFn()
 	if err != nil {
 		return err
 	}
@@ -263,6 +293,11 @@ func (b *cmdConfigBuilder) cmdListRunEFn(*cobra.Command, []string) error {
 	return b.printConfigs(configPrintOpts{configs: cfgs})
 }
 
+func (b *cmdConfigBuilder) registerFormatFlag(cmd *cobra.Command) {
+	desc := fmt.Sprintf("The output format preferred when the config is active, one of [%s %s]", config.OutputFormatTable, config.OutputFormatJSON)
+	cmd.Flags().StringVar(&b.format, "format", "", desc)
+}
+
 func (b *cmdConfigBuilder) registerPrintFlags(cmd *cobra.Command) {
 	registerPrintOptions(cmd, &b.hideHeaders, &b.json)
 }
@@ -281,7 +316,7 @@ func (b *cmdConfigBuilder) printConfigs(opts configPrintOpts) error {
 
 	w.HideHeaders(b.hideHeaders)
 
-	headers := []string{"Active", "Name", "URL", "Org"}
+	headers := []string{"Active", "Name", "URL", "Org", "Format"}
 	if opts.delete {
 		headers = append(headers, "Deleted")
 	}
@@ -300,6 +335,7 @@ func (b *cmdConfigBuilder) printConfigs(opts configPrintOpts) error {
 			"Name":   c.name,
 			"URL":    c.Host,
 			"Org":    c.Org,
+			"Format": c.ActiveFormat(),
 		}
 		if opts.delete {
 			m["Deleted"] = true
@@ -3,10 +3,15 @@ package config
 import (
 	"bufio"
 	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/url"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/BurntSushi/toml"
 	"github.com/influxdata/influxdb"
@@ -19,6 +24,102 @@ type Config struct {
 	Token  string `toml:"token" json:"token"`
 	Org    string `toml:"org" json:"org"`
 	Active bool   `toml:"active" json:"active"`
+	// Format is the output format preferred when the config is active. The
+	// table format is used when empty.
+	Format OutputFormat `toml:"format,omitempty" json:"format,omitempty"`
+}
+
+// OutputFormat is the format the CLI writes its output in.
+type OutputFormat string
+
+// Output formats.
+const (
+	OutputFormatTable OutputFormat = "table"
+	OutputFormatJSON  OutputFormat = "json"
+)
+
+// OK validates the output format is known. An empty format is valid, the
+// table format is used in its place.
+func (f OutputFormat) OK() error {
+	switch f {
+	case "", OutputFormatTable, OutputFormatJSON:
+		return nil
+	}
+	return fmt.Errorf("%q is not a valid output format, must be one of [%s %s]", string(f), OutputFormatTable, OutputFormatJSON)
+}
+
+// ActiveFormat returns the output format of the config, the table format when
+// the config does not provide one.
+func (c Config) ActiveFormat() OutputFormat {
+	if c.Format == "" {
+		return OutputFormatTable
+	}
+	return c.Format
+}
+
+// String describes the config with its token masked, making it safe to log.
+func (c Config) String() string {
+	return fmt.Sprintf("url=%s org=%s token=%s active=%t", c.Host, c.Org, maskToken(c.Token), c.Active)
+}
+
+// MarshalJSON encodes the config with its token masked, making it safe to
+// display. Use WithToken to encode the config with its token.
+func (c Config) MarshalJSON() ([]byte, error) {
+	masked := c.WithToken()
+	masked.Token = maskToken(c.Token)
+	return json.Marshal(masked)
+}
+
+// WithToken returns the config for encoding with its token unmasked, as is
+// required when the config is written for later use.
+func (c Config) WithToken() ConfigWithToken {
+	return ConfigWithToken(c)
+}
+
+// ConfigWithToken is a config that is encoded with its token unmasked.
+type ConfigWithToken Config
+
+// ConfigFieldError identifies the field of a config that is invalid.
+type ConfigFieldError struct {
+	// Name is the name of the config, when known.
+	Name  string
+	Field string
+	Msg   string
+}
+
+// Error implements the error interface.
+func (e *ConfigFieldError) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("config field %q %s", e.Field, e.Msg)
+	}
+	return fmt.Sprintf("config %q field %q %s", e.Name, e.Field, e.Msg)
+}
+
+// Validate returns an invalid error identifying the first invalid field of the
+// config. A config requires a url with an http or https scheme and a host, and
+// a token. The error wraps a *ConfigFieldError.
+func (c Config) Validate() error {
+	if fieldErr := c.validate(); fieldErr != nil {
+		return &influxdb.Error{Code: influxdb.EInvalid, Err: fieldErr}
+	}
+	return nil
+}
+
+func (c Config) validate() *ConfigFieldError {
+	if c.Host == "" {
+		return &ConfigFieldError{Field: "url", Msg: "is required"}
+	}
+	u, err := url.Parse(c.Host)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &ConfigFieldError{Field: "url", Msg: fmt.Sprintf("%q is not a valid http(s) url", c.Host)}
+	}
+	if c.Token == "" {
+		return &ConfigFieldError{Field: "token", Msg: "is required"}
+	}
+	if err := c.Format.OK(); err != nil {
+		return &ConfigFieldError{Field: "format", Msg: err.Error()}
+	}
+	return nil
 }
 
 // DefaultConfig is default config without token
@@ -30,10 +131,98 @@ var DefaultConfig = Config{
 // Configs is map of configs indexed by name.
 type Configs map[string]Config
 
+// ConfigUpdate is the set of fields to update on a config. Empty fields
+// are left unchanged.
+type ConfigUpdate struct {
+	Host   string
+	Token  string
+	Org    string
+	Format OutputFormat
+}
+
+// apply updates the config with the non empty fields of the update.
+func (u ConfigUpdate) apply(p Config) Config {
+	if u.Host != "" {
+		p.Host = u.Host
+	}
+	if u.Token != "" {
+		p.Token = u.Token
+	}
+	if u.Org != "" {
+		p.Org = u.Org
+	}
+	if u.Format != "" {
+		p.Format = u.Format
+	}
+	return p
+}
+
 // ConfigsService is the service to list and write configs.
 type ConfigsService interface {
 	WriteConfigs(pp Configs) error
 	ParseConfigs() (Configs, error)
+	UpdateActiveConfig(update ConfigUpdate) (Config, error)
+	ExportConfigs() ([]byte, error)
+	ImportConfigs(data []byte, overwrite bool) (Configs, error)
+	DiffConfigs(incoming Configs) ([]ConfigChange, error)
+	PingConfig(p Config) error
+	ListConfigsByHost(host string) (Configs, error)
+	SwitchConfig(name string) (Config, error)
+	OnSwitch(fn func(old, new Config))
+	ActiveFormat() (OutputFormat, error)
+}
+
+// ConfigChangeAction is the action applying a set of configs takes on
+// an individual config.
+type ConfigChangeAction string
+
+// Config change actions.
+const (
+	ConfigChangeAdd    ConfigChangeAction = "add"
+	ConfigChangeUpdate ConfigChangeAction = "update"
+	ConfigChangeDelete ConfigChangeAction = "delete"
+	ConfigChangeNoop   ConfigChangeAction = "noop"
+)
+
+// ConfigFieldChange is a change to a single field of a config. Tokens
+// are masked.
+type ConfigFieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old,omitempty"`
+	New   string `json:"new,omitempty"`
+}
+
+// ConfigChange describes the change to a config, by name, and the fields
+// that differ.
+type ConfigChange struct {
+	Name   string              `json:"name"`
+	Action ConfigChangeAction  `json:"action"`
+	Fields []ConfigFieldChange `json:"fields,omitempty"`
+}
+
+// Validate returns an invalid error identifying the first invalid config, by
+// name, and its invalid field. Every config requires a name, along with the
+// fields required by Config.Validate. The error wraps a *ConfigFieldError.
+func (pp Configs) Validate() error {
+	names := make([]string, 0, len(pp))
+	for name := range pp {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return &influxdb.Error{
+				Code: influxdb.EInvalid,
+				Err:  &ConfigFieldError{Field: "name", Msg: "is required"},
+			}
+		}
+		if fieldErr := pp[name].validate(); fieldErr != nil {
+			fieldErr.Name = name
+			return &influxdb.Error{Code: influxdb.EInvalid, Err: fieldErr}
+		}
+	}
+	return nil
 }
 
 // Switch to another config.
@@ -52,10 +241,172 @@ func (pp *Configs) Switch(name string) error {
 	return nil
 }
 
+// Diff compares the incoming configs to the configs, describing the change
+// replacing the configs with the incoming configs makes to each config. The
+// changes are ordered by name.
+func (pp Configs) Diff(incoming Configs) []ConfigChange {
+	names := make(map[string]bool)
+	for name := range pp {
+		names[name] = true
+	}
+	for name := range incoming {
+		names[name] = true
+	}
+
+	changes := make([]ConfigChange, 0, len(names))
+	for name := range names {
+		old, hasOld := pp[name]
+		p, hasNew := incoming[name]
+
+		change := ConfigChange{
+			Name:   name,
+			Fields: diffConfigFields(old, p),
+		}
+		switch {
+		case !hasOld:
+			change.Action = ConfigChangeAdd
+		case !hasNew:
+			change.Action = ConfigChangeDelete
+		case len(change.Fields) > 0:
+			change.Action = ConfigChangeUpdate
+		default:
+			change.Action = ConfigChangeNoop
+		}
+		changes = append(changes, change)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Name < changes[j].Name
+	})
+	return changes
+}
+
+func diffConfigFields(old, p Config) []ConfigFieldChange {
+	var fields []ConfigFieldChange
+	add := func(field, o, n string) {
+		if o != n {
+			fields = append(fields, ConfigFieldChange{Field: field, Old: o, New: n})
+		}
+	}
+	add("url", old.Host, p.Host)
+	if old.Token != p.Token {
+		fields = append(fields, ConfigFieldChange{
+			Field: "token",
+			Old:   maskToken(old.Token),
+			New:   maskToken(p.Token),
+		})
+	}
+	add("org", old.Org, p.Org)
+	add("active", activeStr(old.Active), activeStr(p.Active))
+	add("format", string(old.Format), string(p.Format))
+	return fields
+}
+
+func activeStr(active bool) string {
+	if !active {
+		return ""
+	}
+	return "true"
+}
+
+// maskToken hides all but the last 4 characters of the token.
+func maskToken(token string) string {
+	const visible = 4
+	if len(token) <= visible {
+		return strings.Repeat("*", len(token))
+	}
+	return strings.Repeat("*", len(token)-visible) + token[len(token)-visible:]
+}
+
+// activeName returns the name of the active config.
+func (pp Configs) activeName() (string, error) {
+	var name string
+	for k, p := range pp {
+		if !p.Active {
+			continue
+		}
+		if name != "" {
+			return "", &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  "more than one activated configs found",
+			}
+		}
+		name = k
+	}
+	if name == "" {
+		return "", &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  "activated config is not found",
+		}
+	}
+	return name, nil
+}
+
+// merge adds the incoming configs. An existing config is only replaced by an
+// incoming config of the same name when overwrite is true. An active incoming
+// config becomes the active config, otherwise the existing active config is
+// preserved. When neither provides one, the first config by name is activated.
+func (pp Configs) merge(incoming Configs, overwrite bool) error {
+	var active string
+	for name, p := range incoming {
+		if _, ok := pp[name]; ok && !overwrite {
+			continue
+		}
+		if p.Active {
+			if active != "" {
+				return &influxdb.Error{
+					Code: influxdb.EConflict,
+					Msg:  "more than one activated configs found",
+				}
+			}
+			active = name
+		}
+		pp[name] = p
+	}
+	if len(pp) == 0 {
+		return nil
+	}
+
+	if active == "" {
+		if _, err := pp.activeName(); err == nil {
+			return nil
+		}
+		names := make([]string, 0, len(pp))
+		for name := range pp {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		active = names[0]
+	}
+	return pp.Switch(active)
+}
+
 // LocalConfigsSVC has the path and dir to write and parse configs.
 type LocalConfigsSVC struct {
 	Path string
 	Dir  string
+
+	// Client is used to ping the host of a config. The http.DefaultClient
+	// is used when not provided.
+	Client *http.Client
+
+	switchFns []func(old, new Config)
+}
+
+// OnSwitch registers a callback that is called whenever SwitchConfig or
+// UpdateActiveConfig changes the active config. The callback is provided the
+// previously active config and the newly active config, once the configs have
+// been written.
+func (svc *LocalConfigsSVC) OnSwitch(fn func(old, new Config)) {
+	svc.switchFns = append(svc.switchFns, fn)
+}
+
+func (svc LocalConfigsSVC) notifySwitch(old, new Config) {
+	if old == new {
+		return
+	}
+	for _, fn := range svc.switchFns {
+		fn(old, new)
+	}
 }
 
 // ParseConfigs from the local path.
@@ -100,6 +451,258 @@ func (svc LocalConfigsSVC) WriteConfigs(pp Configs) error {
 	return ioutil.WriteFile(svc.Path, b1.Bytes(), 0600)
 }
 
+// UpdateActiveConfig updates the active config and writes the configs to the path.
+// The updated config is validated before it is written, an invalid update
+// leaves the configs at the path untouched.
+func (svc LocalConfigsSVC) UpdateActiveConfig(update ConfigUpdate) (Config, error) {
+	if err := update.Format.OK(); err != nil {
+		return Config{}, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Err:  &ConfigFieldError{Field: "format", Msg: err.Error()},
+		}
+	}
+
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return Config{}, err
+	}
+
+	name, err := pp.activeName()
+	if err != nil {
+		return Config{}, err
+	}
+
+	old := pp[name]
+	p := update.apply(old)
+	if err := (Configs{name: p}).Validate(); err != nil {
+		return Config{}, err
+	}
+	pp[name] = p
+	if err := svc.WriteConfigs(pp); err != nil {
+		return Config{}, err
+	}
+	svc.notifySwitch(old, p)
+	return p, nil
+}
+
+// SwitchConfig activates the config of the given name and writes the configs to
+// the path. When no config has the name a not found error is returned and the
+// configs are left untouched, keeping the active config active.
+func (svc LocalConfigsSVC) SwitchConfig(name string) (Config, error) {
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return Config{}, err
+	}
+
+	// no config is active prior to the switch when the active config can not
+	// be determined, the zero config is provided as the old config.
+	var old Config
+	if activeName, err := pp.activeName(); err == nil {
+		old = pp[activeName]
+	}
+
+	if err := pp.Switch(name); err != nil {
+		return Config{}, err
+	}
+	if err := svc.WriteConfigs(pp); err != nil {
+		return Config{}, err
+	}
+	svc.notifySwitch(old, pp[name])
+	return pp[name], nil
+}
+
+// ActiveFormat returns the output format of the active config. The table format
+// is returned when the active config does not provide one.
+func (svc LocalConfigsSVC) ActiveFormat() (OutputFormat, error) {
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return "", err
+	}
+
+	name, err := pp.activeName()
+	if err != nil {
+		return "", err
+	}
+	return pp[name].ActiveFormat(), nil
+}
+
+// ExportConfigs encodes all the configs as JSON, suitable for importing
+// on another machine.
+func (svc LocalConfigsSVC) ExportConfigs() ([]byte, error) {
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	unmasked := make(map[string]ConfigWithToken, len(pp))
+	for name, p := range pp {
+		unmasked[name] = p.WithToken()
+	}
+	return json.MarshalIndent(unmasked, "", "\t")
+}
+
+// ImportConfigs merges the JSON encoded configs with the existing configs and
+// writes the result to the path. Existing configs are only replaced by imported
+// configs of the same name when overwrite is true. The imported configs that
+// are merged are validated before they are written, nothing is written when any
+// is invalid.
+func (svc LocalConfigsSVC) ImportConfigs(data []byte, overwrite bool) (Configs, error) {
+	var incoming Configs
+	if err := json.Unmarshal(data, &incoming); err != nil {
+		return nil, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  "failed to decode configs",
+			Err:  err,
+		}
+	}
+
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	merged := make(Configs, len(incoming))
+	for name, p := range incoming {
+		if _, ok := pp[name]; ok && !overwrite {
+			continue
+		}
+		merged[name] = p
+	}
+	if err := merged.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := pp.merge(incoming, overwrite); err != nil {
+		return nil, err
+	}
+
+	if err := svc.WriteConfigs(pp); err != nil {
+		return nil, err
+	}
+	return pp, nil
+}
+
+// DiffConfigs compares the incoming configs to the configs at the path, without
+// writing any changes.
+func (svc LocalConfigsSVC) DiffConfigs(incoming Configs) ([]ConfigChange, error) {
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return nil, err
+	}
+	return pp.Diff(incoming), nil
+}
+
+// ListConfigsByHost returns the configs whose url points at the host. The host
+// and the url of each config are compared without their scheme, case, or
+// trailing slash.
+func (svc LocalConfigsSVC) ListConfigsByHost(host string) (Configs, error) {
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		return nil, err
+	}
+
+	host = normalizeHost(host)
+	matches := make(Configs)
+	for name, p := range pp {
+		if normalizeHost(p.Host) == host {
+			matches[name] = p
+		}
+	}
+	return matches, nil
+}
+
+// normalizeHost strips the scheme and trailing slashes from the host and
+// lower cases it, so that equivalent urls compare equal.
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+	if i := strings.Index(host, "://"); i >= 0 {
+		host = host[i+len("://"):]
+	}
+	return strings.TrimRight(host, "/")
+}
+
+// PingConfig verifies the host of the config is healthy, and that the token,
+// along with the org when provided, is authorized by the host.
+func (svc LocalConfigsSVC) PingConfig(p Config) error {
+	client := svc.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	status, err := pingConfigPath(client, p, "/health", nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("host %q is not healthy; got status %d", p.Host, status),
+		}
+	}
+
+	params := url.Values{"limit": []string{"1"}}
+	if p.Org != "" {
+		params.Set("org", p.Org)
+	}
+	status, err = pingConfigPath(client, p, "/api/v2/orgs", params)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+		return nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return &influxdb.Error{
+			Code: influxdb.EUnauthorized,
+			Msg:  fmt.Sprintf("token is not authorized by host %q", p.Host),
+		}
+	case http.StatusNotFound:
+		return &influxdb.Error{
+			Code: influxdb.ENotFound,
+			Msg:  fmt.Sprintf("org %q is not found at host %q", p.Org, p.Host),
+		}
+	default:
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("unexpected status %d from host %q", status, p.Host),
+		}
+	}
+}
+
+func pingConfigPath(client *http.Client, p Config, path string, params url.Values) (int, error) {
+	u, err := url.Parse(p.Host)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EInvalid,
+			Msg:  fmt.Sprintf("invalid url %q", p.Host),
+			Err:  err,
+		}
+	}
+	u.Path = path
+	u.RawQuery = params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, u.String(), nil)
+	if err != nil {
+		return 0, err
+	}
+	if p.Token != "" {
+		req.Header.Set("Authorization", "Token "+p.Token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  fmt.Sprintf("failed to reach host %q", p.Host),
+			Err:  err,
+		}
+	}
+	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+
+	return resp.StatusCode, nil
+}
+
 // ParseConfigs decodes configs from io readers
 func ParseConfigs(r io.Reader) (Configs, error) {
 	p := make(Configs)
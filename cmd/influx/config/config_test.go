@@ -2,6 +2,12 @@ package config
 
 import (
 	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -120,3 +126,722 @@ func TestConfigsSwith(t *testing.T) {
 		}
 	}
 }
+
+func TestLocalConfigsSVC_SwitchConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	svc := LocalConfigsSVC{
+		Path: filepath.Join(dir, "configs"),
+		Dir:  dir,
+	}
+	original := Configs{
+		"a1": {Host: "host1", Token: "tok1", Active: true},
+		"a2": {Host: "host2", Token: "tok2"},
+	}
+	if err := svc.WriteConfigs(original); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = svc.SwitchConfig("p1")
+	influxtesting.ErrorsEqual(t, err, &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  `config "p1" is not found`,
+	})
+
+	pp, err := svc.ParseConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(original, pp); diff != "" {
+		t.Fatalf("configs changed by failed switch, diff %s", diff)
+	}
+
+	p, err := svc.SwitchConfig("a2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(Config{Host: "host2", Token: "tok2", Active: true}, p); diff != "" {
+		t.Fatalf("unexpected switched config, diff %s", diff)
+	}
+
+	pp, err = svc.ParseConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Configs{
+		"a1": {Host: "host1", Token: "tok1"},
+		"a2": {Host: "host2", Token: "tok2", Active: true},
+	}
+	if diff := cmp.Diff(expected, pp); diff != "" {
+		t.Fatalf("switch config not written, diff %s", diff)
+	}
+}
+
+func TestLocalConfigsSVC_OnSwitch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	svc := &LocalConfigsSVC{
+		Path: filepath.Join(dir, "configs"),
+		Dir:  dir,
+	}
+	if err := svc.WriteConfigs(Configs{
+		"a1": {Host: "http://host1", Token: "tok1", Active: true},
+		"a2": {Host: "http://host2", Token: "tok2"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	type change struct{ old, new Config }
+	var changes []change
+	svc.OnSwitch(func(old, new Config) {
+		changes = append(changes, change{old: old, new: new})
+	})
+
+	if _, err := svc.SwitchConfig("a2"); err != nil {
+		t.Fatal(err)
+	}
+	// switching to the active config does not change it
+	if _, err := svc.SwitchConfig("a2"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.SwitchConfig("p1"); err == nil {
+		t.Fatal("expected an error switching to a missing config")
+	}
+	if _, err := svc.UpdateActiveConfig(ConfigUpdate{Org: "org2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []change{
+		{
+			old: Config{Host: "http://host1", Token: "tok1", Active: true},
+			new: Config{Host: "http://host2", Token: "tok2", Active: true},
+		},
+		{
+			old: Config{Host: "http://host2", Token: "tok2", Active: true},
+			new: Config{Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+		},
+	}
+	if diff := cmp.Diff(expected, changes, cmp.AllowUnexported(change{})); diff != "" {
+		t.Fatalf("unexpected switches, diff %s", diff)
+	}
+}
+
+func TestLocalConfigsSVC_UpdateActiveConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		old      Configs
+		update   ConfigUpdate
+		expected Configs
+		errCode  string
+	}{
+		{
+			name: "updates only the active config",
+			old: Configs{
+				"a1": {Host: "http://host1", Token: "tok1", Org: "org1"},
+				"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+			},
+			update: ConfigUpdate{Token: "tok3", Org: "org3"},
+			expected: Configs{
+				"a1": {Host: "http://host1", Token: "tok1", Org: "org1"},
+				"a2": {Host: "http://host2", Token: "tok3", Org: "org3", Active: true},
+			},
+		},
+		{
+			name: "invalid configs that are not active are left as is",
+			old: Configs{
+				"a1": {Host: "host1"},
+				"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+			},
+			update: ConfigUpdate{Token: "tok3"},
+			expected: Configs{
+				"a1": {Host: "host1"},
+				"a2": {Host: "http://host2", Token: "tok3", Org: "org2", Active: true},
+			},
+		},
+		{
+			name: "updates the format",
+			old: Configs{
+				"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+			},
+			update: ConfigUpdate{Format: OutputFormatJSON},
+			expected: Configs{
+				"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true, Format: OutputFormatJSON},
+			},
+		},
+		{
+			name: "invalid format",
+			old: Configs{
+				"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+			},
+			update:  ConfigUpdate{Format: "csv"},
+			errCode: influxdb.EInvalid,
+		},
+		{
+			name: "invalid url",
+			old: Configs{
+				"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+			},
+			update:  ConfigUpdate{Host: "host3"},
+			errCode: influxdb.EInvalid,
+		},
+		{
+			name: "no active config",
+			old: Configs{
+				"a1": {Host: "http://host1"},
+			},
+			update:  ConfigUpdate{Token: "tok3"},
+			errCode: influxdb.ENotFound,
+		},
+		{
+			name: "more than one active config",
+			old: Configs{
+				"a1": {Host: "http://host1", Active: true},
+				"a2": {Host: "http://host2", Active: true},
+			},
+			update:  ConfigUpdate{Token: "tok3"},
+			errCode: influxdb.EConflict,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "influx-config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			svc := LocalConfigsSVC{
+				Path: filepath.Join(dir, "configs"),
+				Dir:  dir,
+			}
+			if err := svc.WriteConfigs(c.old); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := svc.UpdateActiveConfig(c.update)
+			if c.errCode != "" {
+				if code := influxdb.ErrorCode(err); code != c.errCode {
+					t.Fatalf("unexpected error code: got %q, exp %q", code, c.errCode)
+				}
+				pp, err := svc.ParseConfigs()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if diff := cmp.Diff(c.old, pp); diff != "" {
+					t.Fatalf("configs changed by failed update, diff %s", diff)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			pp, err := svc.ParseConfigs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expected, pp); diff != "" {
+				t.Fatalf("update active config failed, diff %s", diff)
+			}
+			if diff := cmp.Diff(c.expected["a2"], p); diff != "" {
+				t.Fatalf("unexpected updated config, diff %s", diff)
+			}
+		})
+	}
+}
+
+func TestLocalConfigsSVC_ActiveFormat(t *testing.T) {
+	cases := []struct {
+		name     string
+		pp       Configs
+		expected OutputFormat
+		errCode  string
+	}{
+		{
+			name: "format of the active config",
+			pp: Configs{
+				"a1": {Host: "host1", Token: "tok1", Format: OutputFormatTable},
+				"a2": {Host: "host2", Token: "tok2", Active: true, Format: OutputFormatJSON},
+			},
+			expected: OutputFormatJSON,
+		},
+		{
+			name: "defaults to table",
+			pp: Configs{
+				"a1": {Host: "host1", Token: "tok1", Active: true},
+			},
+			expected: OutputFormatTable,
+		},
+		{
+			name: "no active config",
+			pp: Configs{
+				"a1": {Host: "host1", Token: "tok1", Format: OutputFormatJSON},
+			},
+			errCode: influxdb.ENotFound,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "influx-config")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer os.RemoveAll(dir)
+
+			svc := LocalConfigsSVC{
+				Path: filepath.Join(dir, "configs"),
+				Dir:  dir,
+			}
+			if err := svc.WriteConfigs(c.pp); err != nil {
+				t.Fatal(err)
+			}
+
+			format, err := svc.ActiveFormat()
+			if c.errCode != "" {
+				if code := influxdb.ErrorCode(err); code != c.errCode {
+					t.Fatalf("unexpected error code: got %q, exp %q", code, c.errCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if format != c.expected {
+				t.Fatalf("unexpected format: got %q, exp %q", format, c.expected)
+			}
+
+			// the format is round tripped by the configs
+			pp, err := svc.ParseConfigs()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.pp, pp); diff != "" {
+				t.Fatalf("parse configs failed, diff %s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigsMerge(t *testing.T) {
+	cases := []struct {
+		name      string
+		old       Configs
+		incoming  Configs
+		overwrite bool
+		expected  Configs
+		errCode   string
+	}{
+		{
+			name: "skips existing configs",
+			old: Configs{
+				"a1": {Host: "host1", Active: true},
+			},
+			incoming: Configs{
+				"a1": {Host: "host11"},
+				"a2": {Host: "host2"},
+			},
+			expected: Configs{
+				"a1": {Host: "host1", Active: true},
+				"a2": {Host: "host2"},
+			},
+		},
+		{
+			name: "overwrites existing configs",
+			old: Configs{
+				"a1": {Host: "host1", Active: true},
+				"a2": {Host: "host2"},
+			},
+			incoming: Configs{
+				"a1": {Host: "host11"},
+			},
+			overwrite: true,
+			expected: Configs{
+				"a1": {Host: "host11", Active: true},
+				"a2": {Host: "host2"},
+			},
+		},
+		{
+			name: "imported active config is activated",
+			old: Configs{
+				"a1": {Host: "host1", Active: true},
+			},
+			incoming: Configs{
+				"a2": {Host: "host2", Active: true},
+			},
+			expected: Configs{
+				"a1": {Host: "host1"},
+				"a2": {Host: "host2", Active: true},
+			},
+		},
+		{
+			name: "activates first config when none is active",
+			old:  Configs{},
+			incoming: Configs{
+				"b1": {Host: "host2"},
+				"a1": {Host: "host1"},
+			},
+			expected: Configs{
+				"a1": {Host: "host1", Active: true},
+				"b1": {Host: "host2"},
+			},
+		},
+		{
+			name: "more than one imported active config",
+			old:  Configs{},
+			incoming: Configs{
+				"a1": {Host: "host1", Active: true},
+				"a2": {Host: "host2", Active: true},
+			},
+			errCode: influxdb.EConflict,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.old.merge(c.incoming, c.overwrite)
+			if c.errCode != "" {
+				if code := influxdb.ErrorCode(err); code != c.errCode {
+					t.Fatalf("unexpected error code: got %q, exp %q", code, c.errCode)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(c.expected, c.old); diff != "" {
+				t.Fatalf("merge configs failed, diff %s", diff)
+			}
+		})
+	}
+}
+
+func TestLocalConfigsSVC_ExportImport(t *testing.T) {
+	newSVC := func(t *testing.T) (LocalConfigsSVC, func()) {
+		dir, err := ioutil.TempDir("", "influx-config")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return LocalConfigsSVC{
+			Path: filepath.Join(dir, "configs"),
+			Dir:  dir,
+		}, func() { os.RemoveAll(dir) }
+	}
+
+	src, done := newSVC(t)
+	defer done()
+	expected := Configs{
+		"a1": {Host: "http://host1", Token: "tok1", Org: "org1"},
+		"a2": {Host: "http://host2", Token: "tok2", Org: "org2", Active: true},
+	}
+	if err := src.WriteConfigs(expected); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := src.ExportConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dst, done := newSVC(t)
+	defer done()
+	imported, err := dst.ImportConfigs(data, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, imported); diff != "" {
+		t.Fatalf("import configs failed, diff %s", diff)
+	}
+
+	pp, err := dst.ParseConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, pp); diff != "" {
+		t.Fatalf("imported configs not written, diff %s", diff)
+	}
+
+	if _, err := dst.ImportConfigs([]byte("bad json"), false); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error, got %v", err)
+	}
+
+	invalid := []byte(`{"a3": {"url": "http://host3"}}`)
+	if _, err := dst.ImportConfigs(invalid, false); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected invalid error importing a config without a token, got %v", err)
+	}
+	pp, err = dst.ParseConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(expected, pp); diff != "" {
+		t.Fatalf("configs changed by failed import, diff %s", diff)
+	}
+
+	legacy, done := newSVC(t)
+	defer done()
+	if err := legacy.WriteConfigs(Configs{"a3": {Host: "host3", Active: true}}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := legacy.ImportConfigs(data, false); err != nil {
+		t.Fatalf("unexpected error importing next to an invalid existing config: %v", err)
+	}
+	pp, err = legacy.ParseConfigs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := pp["a1"]; !ok {
+		t.Fatalf("imported configs not written next to an invalid existing config, got %v", pp)
+	}
+}
+
+func TestLocalConfigsSVC_ListConfigsByHost(t *testing.T) {
+	dir, err := ioutil.TempDir("", "influx-config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	svc := LocalConfigsSVC{
+		Path: filepath.Join(dir, "configs"),
+		Dir:  dir,
+	}
+	pp := Configs{
+		"a1": {Host: "http://localhost:9999", Token: "tok1", Active: true},
+		"a2": {Host: "https://LOCALHOST:9999/", Token: "tok2"},
+		"a3": {Host: "http://localhost:8888", Token: "tok3"},
+	}
+	if err := svc.WriteConfigs(pp); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		host     string
+		expected Configs
+	}{
+		{
+			host: "localhost:9999",
+			expected: Configs{
+				"a1": pp["a1"],
+				"a2": pp["a2"],
+			},
+		},
+		{
+			host:     "http://localhost:8888/",
+			expected: Configs{"a3": pp["a3"]},
+		},
+		{
+			host:     "http://example.com",
+			expected: Configs{},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.host, func(t *testing.T) {
+			got, err := svc.ListConfigsByHost(c.host)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(c.expected, got); diff != "" {
+				t.Fatalf("list configs by host failed, diff %s", diff)
+			}
+		})
+	}
+}
+
+func TestConfigsDiff(t *testing.T) {
+	old := Configs{
+		"a1": {Host: "host1", Token: "token1", Org: "org1", Active: true},
+		"a2": {Host: "host2", Token: "token2", Org: "org2"},
+		"a3": {Host: "host3"},
+	}
+	incoming := Configs{
+		"a1": {Host: "host1", Token: "token1", Org: "org1", Active: true},
+		"a2": {Host: "host22", Token: "token22", Org: "org2"},
+		"a4": {Host: "host4"},
+	}
+
+	expected := []ConfigChange{
+		{Name: "a1", Action: ConfigChangeNoop},
+		{
+			Name:   "a2",
+			Action: ConfigChangeUpdate,
+			Fields: []ConfigFieldChange{
+				{Field: "url", Old: "host2", New: "host22"},
+				{Field: "token", Old: "**ken2", New: "***en22"},
+			},
+		},
+		{
+			Name:   "a3",
+			Action: ConfigChangeDelete,
+			Fields: []ConfigFieldChange{
+				{Field: "url", Old: "host3"},
+			},
+		},
+		{
+			Name:   "a4",
+			Action: ConfigChangeAdd,
+			Fields: []ConfigFieldChange{
+				{Field: "url", New: "host4"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, old.Diff(incoming)); diff != "" {
+		t.Fatalf("diff configs failed, diff %s", diff)
+	}
+}
+
+func TestConfigMasksToken(t *testing.T) {
+	p := Config{Host: "host1", Token: "secret-token", Org: "org1", Active: true}
+
+	if got, exp := p.String(), "url=host1 org=org1 token=********oken active=true"; got != exp {
+		t.Fatalf("unexpected string: got %q, exp %q", got, exp)
+	}
+
+	b, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(b), `{"url":"host1","token":"********oken","org":"org1","active":true}`; got != exp {
+		t.Fatalf("unexpected json: got %s, exp %s", got, exp)
+	}
+
+	b, err = json.Marshal(p.WithToken())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, exp := string(b), `{"url":"host1","token":"secret-token","org":"org1","active":true}`; got != exp {
+		t.Fatalf("unexpected json: got %s, exp %s", got, exp)
+	}
+}
+
+func TestConfigsValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		pp    Configs
+		field *ConfigFieldError
+	}{
+		{
+			name: "valid",
+			pp: Configs{
+				"a1": {Host: "http://localhost:9999", Token: "tok1"},
+				"a2": {Host: "https://example.com", Token: "tok2", Org: "org2"},
+			},
+		},
+		{
+			name:  "empty name",
+			pp:    Configs{"": {Host: "http://localhost:9999", Token: "tok1"}},
+			field: &ConfigFieldError{Field: "name", Msg: "is required"},
+		},
+		{
+			name:  "missing host",
+			pp:    Configs{"a1": {Token: "tok1"}},
+			field: &ConfigFieldError{Name: "a1", Field: "url", Msg: "is required"},
+		},
+		{
+			name:  "malformed host",
+			pp:    Configs{"a1": {Host: "localhost:9999", Token: "tok1"}},
+			field: &ConfigFieldError{Name: "a1", Field: "url", Msg: `"localhost:9999" is not a valid http(s) url`},
+		},
+		{
+			name:  "missing token",
+			pp:    Configs{"a1": {Host: "http://localhost:9999"}},
+			field: &ConfigFieldError{Name: "a1", Field: "token", Msg: "is required"},
+		},
+		{
+			name:  "unknown format",
+			pp:    Configs{"a1": {Host: "http://localhost:9999", Token: "tok1", Format: "xml"}},
+			field: &ConfigFieldError{Name: "a1", Field: "format", Msg: `"xml" is not a valid output format, must be one of [table json]`},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := c.pp.Validate()
+			if c.field == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+
+			if code := influxdb.ErrorCode(err); code != influxdb.EInvalid {
+				t.Fatalf("unexpected error code: got %q, exp %q", code, influxdb.EInvalid)
+			}
+			fieldErr, ok := err.(*influxdb.Error).Err.(*ConfigFieldError)
+			if !ok {
+				t.Fatalf("expected a *ConfigFieldError, got %T", err.(*influxdb.Error).Err)
+			}
+			if diff := cmp.Diff(c.field, fieldErr); diff != "" {
+				t.Fatalf("unexpected field error: %s", diff)
+			}
+		})
+	}
+
+	if err := (Config{Host: "http://localhost:9999"}).Validate(); influxdb.ErrorCode(err) != influxdb.EInvalid {
+		t.Fatalf("expected an invalid error for a config without a token, got %v", err)
+	}
+}
+
+func TestLocalConfigsSVC_PingConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		health  int
+		orgs    int
+		org     string
+		errCode string
+	}{
+		{name: "healthy and authorized", health: http.StatusOK, orgs: http.StatusOK, org: "org1"},
+		{name: "unhealthy host", health: http.StatusServiceUnavailable, errCode: influxdb.EUnavailable},
+		{name: "unauthorized token", health: http.StatusOK, orgs: http.StatusUnauthorized, errCode: influxdb.EUnauthorized},
+		{name: "org not found", health: http.StatusOK, orgs: http.StatusNotFound, org: "org2", errCode: influxdb.ENotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/health":
+					w.WriteHeader(c.health)
+				case "/api/v2/orgs":
+					if got := r.Header.Get("Authorization"); got != "Token tok1" {
+						w.WriteHeader(http.StatusUnauthorized)
+						return
+					}
+					if got := r.URL.Query().Get("org"); got != c.org {
+						w.WriteHeader(http.StatusBadRequest)
+						return
+					}
+					w.WriteHeader(c.orgs)
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer srv.Close()
+
+			err := LocalConfigsSVC{}.PingConfig(Config{Host: srv.URL, Token: "tok1", Org: c.org})
+			if c.errCode == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if code := influxdb.ErrorCode(err); code != c.errCode {
+				t.Fatalf("unexpected error code: got %q, exp %q; err %v", code, c.errCode, err)
+			}
+		})
+	}
+
+	t.Run("unreachable host", func(t *testing.T) {
+		srv := httptest.NewServer(http.NotFoundHandler())
+		host := srv.URL
+		srv.Close()
+
+		err := LocalConfigsSVC{}.PingConfig(Config{Host: host, Token: "tok1"})
+		if code := influxdb.ErrorCode(err); code != influxdb.EUnavailable {
+			t.Fatalf("unexpected error code: got %q, exp %q", code, influxdb.EUnavailable)
+		}
+	})
+}
@@ -1,17 +1,115 @@
 package config
 
-// MockConfigService mocks the ConfigService.
+import "sync"
+
+// MockConfigService mocks the ConfigService. The fns stub the return values
+// of each method, the calls to each method are recorded, in the order they
+// were made, by the method's Calls field.
 type MockConfigService struct {
-	WriteConfigsFn func(pp Configs) error
-	ParseConfigsFn func() (Configs, error)
+	WriteConfigsFn       func(pp Configs) error
+	ParseConfigsFn       func() (Configs, error)
+	UpdateActiveConfigFn func(update ConfigUpdate) (Config, error)
+	ExportConfigsFn      func() ([]byte, error)
+	ImportConfigsFn      func(data []byte, overwrite bool) (Configs, error)
+	DiffConfigsFn        func(incoming Configs) ([]ConfigChange, error)
+	PingConfigFn         func(p Config) error
+	ListConfigsByHostFn  func(host string) (Configs, error)
+	SwitchConfigFn       func(name string) (Config, error)
+	OnSwitchFn           func(fn func(old, new Config))
+	ActiveFormatFn       func() (OutputFormat, error)
+
+	mu                      sync.Mutex
+	WriteConfigsCalls       []Configs
+	ParseConfigsCalls       int
+	UpdateActiveConfigCalls []ConfigUpdate
+	ExportConfigsCalls      int
+	ImportConfigsCalls      []MockImportConfigsCall
+	DiffConfigsCalls        []Configs
+	PingConfigCalls         []Config
+	ListConfigsByHostCalls  []string
+	SwitchConfigCalls       []string
+	OnSwitchCalls           []func(old, new Config)
+	ActiveFormatCalls       int
+}
+
+// MockImportConfigsCall is the arguments of a call to ImportConfigs.
+type MockImportConfigsCall struct {
+	Data      []byte
+	Overwrite bool
+}
+
+// record records a call while holding the lock of the mock, allowing for
+// the mock to be called concurrently.
+func (s *MockConfigService) record(fn func()) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn()
 }
 
 // WriteConfigs returns the write fn.
 func (s *MockConfigService) WriteConfigs(pp Configs) error {
+	s.record(func() { s.WriteConfigsCalls = append(s.WriteConfigsCalls, pp) })
 	return s.WriteConfigsFn(pp)
 }
 
 // ParseConfigs returns the parse fn.
 func (s *MockConfigService) ParseConfigs() (Configs, error) {
+	s.record(func() { s.ParseConfigsCalls++ })
 	return s.ParseConfigsFn()
 }
+
+// UpdateActiveConfig returns the update active config fn.
+func (s *MockConfigService) UpdateActiveConfig(update ConfigUpdate) (Config, error) {
+	s.record(func() { s.UpdateActiveConfigCalls = append(s.UpdateActiveConfigCalls, update) })
+	return s.UpdateActiveConfigFn(update)
+}
+
+// ExportConfigs returns the export configs fn.
+func (s *MockConfigService) ExportConfigs() ([]byte, error) {
+	s.record(func() { s.ExportConfigsCalls++ })
+	return s.ExportConfigsFn()
+}
+
+// ImportConfigs returns the import configs fn.
+func (s *MockConfigService) ImportConfigs(data []byte, overwrite bool) (Configs, error) {
+	s.record(func() {
+		s.ImportConfigsCalls = append(s.ImportConfigsCalls, MockImportConfigsCall{Data: data, Overwrite: overwrite})
+	})
+	return s.ImportConfigsFn(data, overwrite)
+}
+
+// DiffConfigs returns the diff configs fn.
+func (s *MockConfigService) DiffConfigs(incoming Configs) ([]ConfigChange, error) {
+	s.record(func() { s.DiffConfigsCalls = append(s.DiffConfigsCalls, incoming) })
+	return s.DiffConfigsFn(incoming)
+}
+
+// PingConfig returns the ping config fn.
+func (s *MockConfigService) PingConfig(p Config) error {
+	s.record(func() { s.PingConfigCalls = append(s.PingConfigCalls, p) })
+	return s.PingConfigFn(p)
+}
+
+// ListConfigsByHost returns the list configs by host fn.
+func (s *MockConfigService) ListConfigsByHost(host string) (Configs, error) {
+	s.record(func() { s.ListConfigsByHostCalls = append(s.ListConfigsByHostCalls, host) })
+	return s.ListConfigsByHostFn(host)
+}
+
+// SwitchConfig returns the switch config fn.
+func (s *MockConfigService) SwitchConfig(name string) (Config, error) {
+	s.record(func() { s.SwitchConfigCalls = append(s.SwitchConfigCalls, name) })
+	return s.SwitchConfigFn(name)
+}
+
+// OnSwitch returns the on switch fn.
+func (s *MockConfigService) OnSwitch(fn func(old, new Config)) {
+	s.record(func() { s.OnSwitchCalls = append(s.OnSwitchCalls, fn) })
+	s.OnSwitchFn(fn)
+}
+
+// ActiveFormat returns the active format fn.
+func (s *MockConfigService) ActiveFormat() (OutputFormat, error) {
+	s.record(func() { s.ActiveFormatCalls++ })
+	return s.ActiveFormatFn()
+}
@@ -41,6 +41,23 @@ func TestCmdConfig(t *testing.T) {
 					},
 				},
 			},
+			{
+				name: "with format",
+				flags: []string{
+					"--name", "default",
+					"--url", "http://localhost:9999",
+					"--token", "tok1",
+					"--format", "json",
+				},
+				original: make(config.Configs),
+				expected: config.Configs{
+					"default": {
+						Token:  "tok1",
+						Host:   "http://localhost:9999",
+						Format: config.OutputFormatJSON,
+					},
+				},
+			},
 			{
 				name: "short",
 				flags: []string{
@@ -99,6 +116,103 @@ func TestCmdConfig(t *testing.T) {
 		}
 	})
 
+	t.Run("create with verify", func(t *testing.T) {
+		tests := []struct {
+			name    string
+			pingErr error
+		}{
+			{name: "valid config"},
+			{
+				name:    "invalid config",
+				pingErr: &influxdb.Error{Code: influxdb.EUnauthorized},
+			},
+		}
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				svc := &config.MockConfigService{
+					ParseConfigsFn: func() (config.Configs, error) {
+						return make(config.Configs), nil
+					},
+					WriteConfigsFn: func(pp config.Configs) error {
+						return nil
+					},
+					PingConfigFn: func(p config.Config) error {
+						if p.Host != "http://localhost:9999" || p.Token != "tok1" {
+							return fmt.Errorf("unexpected config pinged: %s", p)
+						}
+						return tt.pingErr
+					},
+				}
+
+				builder := newInfluxCmdBuilder(
+					in(new(bytes.Buffer)),
+					out(ioutil.Discard),
+				)
+				cmd := builder.cmd(func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+					builder := cmdConfigBuilder{
+						genericCLIOpts: opt,
+						globalFlags:    g,
+						svc:            svc,
+					}
+					return builder.cmd()
+				})
+				cmd.SetArgs([]string{
+					"config", "create",
+					"--name", "default",
+					"--url", "http://localhost:9999",
+					"--token", "tok1",
+					"--verify",
+				})
+
+				err := cmd.Execute()
+				require.Len(t, svc.PingConfigCalls, 1)
+				if tt.pingErr != nil {
+					require.Error(t, err)
+					require.Empty(t, svc.WriteConfigsCalls)
+					return
+				}
+				require.NoError(t, err)
+				require.Len(t, svc.WriteConfigsCalls, 1)
+			}
+			t.Run(tt.name, fn)
+		}
+	})
+
+	t.Run("create rejects an invalid config", func(t *testing.T) {
+		svc := &config.MockConfigService{
+			ParseConfigsFn: func() (config.Configs, error) {
+				return make(config.Configs), nil
+			},
+			WriteConfigsFn: func(pp config.Configs) error {
+				return nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			builder := cmdConfigBuilder{
+				genericCLIOpts: opt,
+				globalFlags:    g,
+				svc:            svc,
+			}
+			return builder.cmd()
+		})
+		cmd.SetArgs([]string{
+			"config", "create",
+			"--name", "default",
+			"--url", "localhost:9999",
+			"--token", "tok1",
+		})
+
+		err := cmd.Execute()
+		require.Error(t, err)
+		require.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+		require.Empty(t, svc.WriteConfigsCalls)
+	})
+
 	t.Run("switch", func(t *testing.T) {
 		tests := []struct {
 			name     string
@@ -403,4 +517,34 @@ func TestCmdConfig(t *testing.T) {
 			t.Run(tt.name, fn)
 		}
 	})
+
+	t.Run("list by host", func(t *testing.T) {
+		svc := &config.MockConfigService{
+			ListConfigsByHostFn: func(host string) (config.Configs, error) {
+				if host != "localhost:9999" {
+					return nil, fmt.Errorf("unexpected host: %s", host)
+				}
+				return config.Configs{
+					"kubone": {Host: "http://localhost:9999", Token: "tok1"},
+				}, nil
+			},
+		}
+
+		builder := newInfluxCmdBuilder(
+			in(new(bytes.Buffer)),
+			out(ioutil.Discard),
+		)
+		cmd := builder.cmd(func(g *globalFlags, opt genericCLIOpts) *cobra.Command {
+			builder := cmdConfigBuilder{
+				genericCLIOpts: opt,
+				globalFlags:    g,
+				svc:            svc,
+			}
+			return builder.cmd()
+		})
+		cmd.SetArgs([]string{"config", "list", "--host", "localhost:9999"})
+		require.NoError(t, cmd.Execute())
+		require.Equal(t, []string{"localhost:9999"}, svc.ListConfigsByHostCalls)
+		require.Zero(t, svc.ParseConfigsCalls)
+	})
 }
@@ -279,6 +279,15 @@ func getConfigFromDefaultPath() config.Config {
 	return activated
 }
 
+// defaultConfigsSVC returns the service of the configs at the default path.
+var defaultConfigsSVC = func() (config.ConfigsService, error) {
+	path, dir, err := defaultConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	return &config.LocalConfigsSVC{Path: path, Dir: dir}, nil
+}
+
 func migrateOldCredential() {
 	dir, err := fs.InfluxDir()
 	if err != nil {
@@ -427,20 +436,24 @@ type flagOpts []cli.Opt
 
 func (f flagOpts) mustRegister(cmd *cobra.Command) {
 	for i := range f {
-		envVar := f[i].Flag
-		if e := f[i].EnvVar; e != "" {
-			envVar = e
-		}
-
 		f[i].Desc = fmt.Sprintf(
-			"%s; Maps to env var $INFLUX_%s",
+			"%s; Maps to env var $%s",
 			f[i].Desc,
-			strings.ToUpper(strings.Replace(envVar, "-", "_", -1)),
+			envVarKey(f[i]),
 		)
 	}
 	cli.BindOptions(cmd, f)
 }
 
+// envVarKey returns the env var the option is read from.
+func envVarKey(o cli.Opt) string {
+	envVar := o.Flag
+	if o.EnvVar != "" {
+		envVar = o.EnvVar
+	}
+	return "INFLUX_" + strings.ToUpper(strings.Replace(envVar, "-", "_", -1))
+}
+
 func registerPrintOptions(cmd *cobra.Command, headersP, jsonOutP *bool) {
 	var opts flagOpts
 	if headersP != nil {
@@ -452,16 +465,39 @@ func registerPrintOptions(cmd *cobra.Command, headersP, jsonOutP *bool) {
 			Default: false,
 		})
 	}
+	jsonOpt := cli.Opt{
+		DestP:   jsonOutP,
+		Flag:    "json",
+		EnvVar:  "OUTPUT_JSON",
+		Desc:    "Output data as json; defaults to the format of the active config",
+		Default: false,
+	}
 	if jsonOutP != nil {
-		opts = append(opts, cli.Opt{
-			DestP:   jsonOutP,
-			Flag:    "json",
-			EnvVar:  "OUTPUT_JSON",
-			Desc:    "Output data as json; defaults false",
-			Default: false,
-		})
+		opts = append(opts, jsonOpt)
 	}
 	opts.mustRegister(cmd)
+
+	if jsonOutP != nil {
+		// the format of the active config is the default, it is read once the
+		// flags are parsed so that the configs are only read by the command
+		// being run. The json flag and its env var take precedence, --json=false
+		// outputs a table.
+		cmd.PreRunE = func(cmd *cobra.Command, args []string) error {
+			if cmd.Flags().Changed(jsonOpt.Flag) || os.Getenv(envVarKey(jsonOpt)) != "" {
+				return nil
+			}
+			svc, err := defaultConfigsSVC()
+			if err != nil {
+				return nil
+			}
+			format, err := svc.ActiveFormat()
+			if err != nil {
+				return nil
+			}
+			*jsonOutP = format == config.OutputFormatJSON
+			return nil
+		}
+	}
 }
 
 func setViperOptions() {
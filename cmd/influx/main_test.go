@@ -2,6 +2,7 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"io/ioutil"
 	"testing"
 
@@ -92,3 +93,76 @@ func Test_influx_cmd(t *testing.T) {
 		t.Run(tt.name, fn)
 	}
 }
+
+func Test_influx_cmd_json_default(t *testing.T) {
+	tests := []struct {
+		name          string
+		args          []string
+		envVars       map[string]string
+		formatErr     error
+		expected      bool
+		expectedCalls int
+	}{
+		{
+			name:          "format of the active config",
+			expected:      true,
+			expectedCalls: 1,
+		},
+		{
+			name:     "json flag takes precedence",
+			args:     []string{"--json=false"},
+			expected: false,
+		},
+		{
+			name:     "env var takes precedence",
+			envVars:  map[string]string{"INFLUX_OUTPUT_JSON": "false"},
+			expected: false,
+		},
+		{
+			name:          "no active config",
+			formatErr:     errors.New("no active config"),
+			expected:      false,
+			expectedCalls: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		fn := func(t *testing.T) {
+			defer addEnvVars(t, tt.envVars)()
+
+			svc := &config.MockConfigService{
+				ActiveFormatFn: func() (config.OutputFormat, error) {
+					return config.OutputFormatJSON, tt.formatErr
+				},
+			}
+			defer func(fn func() (config.ConfigsService, error)) { defaultConfigsSVC = fn }(defaultConfigsSVC)
+			defaultConfigsSVC = func() (config.ConfigsService, error) { return svc, nil }
+
+			builder := newInfluxCmdBuilder(
+				in(new(bytes.Buffer)),
+				out(ioutil.Discard),
+				err(ioutil.Discard),
+				runEMiddlware(func(fn cobraRunEFn) cobraRunEFn { return fn }),
+			)
+
+			var jsonOut bool
+			influxCmd := builder.cmd(func(f *globalFlags, opt genericCLIOpts) *cobra.Command {
+				cmd := &cobra.Command{
+					Use:  "foo",
+					RunE: func(cmd *cobra.Command, args []string) error { return nil },
+				}
+				registerPrintOptions(cmd, nil, &jsonOut)
+				return cmd
+			})
+
+			influxCmd.SetArgs(append([]string{"foo"}, tt.args...))
+
+			require.NoError(t, influxCmd.Execute())
+
+			assert.Equal(t, tt.expected, jsonOut)
+			assert.Equal(t, tt.expectedCalls, svc.ActiveFormatCalls)
+		}
+
+		t.Run(tt.name, fn)
+	}
+}
@@ -70,6 +70,8 @@ type cmdPkgBuilder struct {
 		tasks        string
 		telegrafs    string
 		variables    string
+
+		includeBackingTasks bool
 	}
 }
 
@@ -130,13 +132,20 @@ func (b *cmdPkgBuilder) pkgApplyRunEFn(cmd *cobra.Command, args []string) error
 		return err
 	}
 
-	providedEnvRefs := mapKeys(pkg.Summary().MissingEnvs, b.applyOpts.envRefs)
+	sum := pkg.Summary()
+	providedEnvRefs := mapKeys(sum.MissingEnvs, b.applyOpts.envRefs)
 	if !isTTY {
 		for _, envRef := range missingValKeys(providedEnvRefs) {
 			prompt := "Please provide environment reference value for key " + envRef
 			providedEnvRefs[envRef] = b.getInput(prompt, "")
 		}
 	}
+	// env refs with a default are not prompted for, but may still be overridden
+	for envRef, v := range mapKeys(sum.DefaultedEnvs, b.applyOpts.envRefs) {
+		if v != "" {
+			providedEnvRefs[envRef] = v
+		}
+	}
 
 	drySum, diff, err := svc.DryRun(context.Background(), influxOrgID, 0, pkg, pkger.ApplyWithEnvRefs(providedEnvRefs))
 	if err != nil {
@@ -262,6 +271,7 @@ func (b *cmdPkgBuilder) cmdPkgExportAll() *cobra.Command {
 
 	cmd.Flags().StringVarP(&b.file, "file", "f", "", "output file for created pkg; defaults to std out if no file provided; the extension of provided file (.yml/.json) will dictate encoding")
 	cmd.Flags().StringArrayVar(&b.filters, "filter", nil, "Filter exported resources by labelName or resourceKind (format: --filter=labelName=example)")
+	cmd.Flags().BoolVar(&b.exportOpts.includeBackingTasks, "include-backing-tasks", false, "Include the tasks backing checks and notification rules; applying these creates duplicate tasks")
 
 	b.org.register(cmd, false)
 
@@ -294,7 +304,7 @@ func (b *cmdPkgBuilder) pkgExportAllRunEFn(cmd *cobra.Command, args []string) er
 		case "resourceKind":
 			k := pkger.Kind(val)
 			if err := k.OK(); err != nil {
-				return err
+				return fmt.Errorf("invalid resourceKind %q: %s; resourceKind must be 1 in %v", val, err, pkger.SupportedKinds())
 			}
 			resourceKinds = append(resourceKinds, k)
 		default:
@@ -303,9 +313,10 @@ func (b *cmdPkgBuilder) pkgExportAllRunEFn(cmd *cobra.Command, args []string) er
 	}
 
 	orgOpt := pkger.CreateWithAllOrgResources(pkger.CreateByOrgIDOpt{
-		OrgID:         orgID,
-		LabelNames:    labelNames,
-		ResourceKinds: resourceKinds,
+		OrgID:               orgID,
+		LabelNames:          labelNames,
+		ResourceKinds:       resourceKinds,
+		IncludeBackingTasks: b.exportOpts.includeBackingTasks,
 	})
 	return b.writePkg(cmd.OutOrStdout(), pkgSVC, b.file, orgOpt)
 }
@@ -418,11 +429,11 @@ func (b *cmdPkgBuilder) registerPkgPrintOpts(cmd *cobra.Command) {
 }
 
 func (b *cmdPkgBuilder) registerPkgFileFlags(cmd *cobra.Command) {
-	cmd.Flags().StringSliceVarP(&b.files, "file", "f", nil, "Path to package file")
-	cmd.MarkFlagFilename("file", "yaml", "yml", "json", "jsonnet")
+	cmd.Flags().StringSliceVarP(&b.files, "file", "f", nil, "Path to package file or archive (.tar.gz/.zip)")
+	cmd.MarkFlagFilename("file", "yaml", "yml", "json", "jsonnet", "gz", "tgz", "zip")
 	cmd.Flags().BoolVarP(&b.recurse, "recurse", "R", false, "Process the directory used in -f, --file recursively. Useful when you want to manage related manifests organized within the same directory.")
 
-	cmd.Flags().StringSliceVarP(&b.urls, "url", "u", nil, "URL to a package file")
+	cmd.Flags().StringSliceVarP(&b.urls, "url", "u", nil, "URL to a package file or archive (.tar.gz/.zip)")
 
 	cmd.Flags().StringVarP(&b.encoding, "encoding", "e", "", "Encoding for the input stream. If a file is provided will gather encoding type from file extension. If extension provided will override.")
 	cmd.MarkFlagFilename("encoding", "yaml", "yml", "json", "jsonnet")
@@ -461,6 +472,15 @@ func (b *cmdPkgBuilder) readRawPkgsFromFiles(filePaths []string, recurse bool) (
 
 	var rawPkgs []*pkger.Pkg
 	for f := range mFiles {
+		if pkger.IsArchive(f) {
+			pkg, err := pkger.ParseArchive(pkger.FromFile(f), pkger.ValidSkipParseError())
+			if err != nil {
+				return nil, err
+			}
+			rawPkgs = append(rawPkgs, pkg)
+			continue
+		}
+
 		pkg, err := pkger.Parse(b.convertFileEncoding(f), pkger.FromFile(f), pkger.ValidSkipParseError())
 		if err != nil {
 			return nil, err
@@ -479,6 +499,15 @@ func (b *cmdPkgBuilder) readRawPkgsFromURLs(urls []string) ([]*pkger.Pkg, error)
 
 	var rawPkgs []*pkger.Pkg
 	for u := range mURLs {
+		if pkger.IsArchive(u) {
+			pkg, err := pkger.ParseArchive(pkger.FromHTTPRequest(u), pkger.ValidSkipParseError())
+			if err != nil {
+				return nil, err
+			}
+			rawPkgs = append(rawPkgs, pkg)
+			continue
+		}
+
 		pkg, err := pkger.Parse(b.convertURLEncoding(u), pkger.FromHTTPRequest(u), pkger.ValidSkipParseError())
 		if err != nil {
 			return nil, err
@@ -510,7 +539,7 @@ func (b *cmdPkgBuilder) readPkg() (*pkger.Pkg, bool, error) {
 		return pkg, false, err
 	}
 
-	stdinPkg, err := pkger.Parse(b.convertEncoding(), pkger.FromReader(b.in), pkger.ValidSkipParseError())
+	stdinPkg, err := pkger.ParseReader(b.convertEncoding(), b.in, pkger.ValidSkipParseError())
 	if err != nil {
 		return nil, true, err
 	}
@@ -755,11 +784,12 @@ func (b *cmdPkgBuilder) printPkgDiff(diff pkger.Diff) error {
 	}
 
 	if dashes := diff.Dashboards; len(dashes) > 0 {
-		headers := []string{"New", "Name", "Description", "Num Charts"}
+		headers := []string{"New", "ID", "Name", "Description", "Num Charts"}
 		tablePrintFn("DASHBOARDS", headers, len(dashes), func(i int) []string {
 			d := dashes[i]
 			return []string{
-				boolDiff(true),
+				boolDiff(d.IsNew()),
+				d.ID.String(),
 				d.Name,
 				green(d.Desc),
 				green(strconv.Itoa(len(d.Charts))),
@@ -780,47 +810,60 @@ func (b *cmdPkgBuilder) printPkgDiff(diff pkger.Diff) error {
 	}
 
 	if rules := diff.NotificationRules; len(rules) > 0 {
-		headers := []string{"New", "Name", "Description", "Every", "Offset", "Endpoint Name", "Endpoint ID", "Endpoint Type"}
+		headers := []string{"New", "ID", "Name", "Description", "Every", "Offset", "Endpoint Name", "Endpoint ID", "Endpoint Type"}
 		tablePrintFn("NOTIFICATION RULES", headers, len(rules), func(i int) []string {
 			v := rules[i]
 			return []string{
-				green(true),
+				boolDiff(v.IsNew()),
+				v.ID.String(),
 				v.Name,
 				v.Description,
 				v.Every,
 				v.Offset,
-				v.EndpointName,
-				v.EndpointID.String(),
+				diffLn(v.IsNew(), v.OldEndpointName, v.EndpointName),
+				diffLn(v.IsNew(), v.OldEndpointID.String(), v.EndpointID.String()),
 				v.EndpointType,
 			}
 		})
 	}
 
 	if teles := diff.Telegrafs; len(teles) > 0 {
-		headers := []string{"New", "Name", "Description"}
+		headers := []string{"New", "ID", "Name", "Description"}
 		tablePrintFn("TELEGRAF CONFIGS", headers, len(teles), func(i int) []string {
 			t := teles[i]
+			var old pkger.DiffTelegrafValues
+			if t.Old != nil {
+				old = *t.Old
+			}
 			return []string{
-				boolDiff(true),
+				boolDiff(t.IsNew()),
+				t.ID.String(),
 				t.Name,
-				green(t.Description),
+				diffLn(t.IsNew(), old.Description, t.New.Description),
 			}
 		})
 	}
 
 	if tasks := diff.Tasks; len(tasks) > 0 {
-		headers := []string{"New", "Name", "Description", "Cycle"}
+		headers := []string{"New", "ID", "Name", "Description", "Cycle"}
 		tablePrintFn("TASKS", headers, len(tasks), func(i int) []string {
 			t := tasks[i]
-			timing := fmt.Sprintf("every: %s offset: %s", t.Every, t.Offset)
-			if t.Cron != "" {
-				timing = t.Cron
+			var old pkger.DiffTaskValues
+			if t.Old != nil {
+				old = *t.Old
+			}
+			timingFn := func(v pkger.DiffTaskValues) string {
+				if v.Cron != "" {
+					return v.Cron
+				}
+				return fmt.Sprintf("every: %s offset: %s", v.Every, v.Offset)
 			}
 			return []string{
-				boolDiff(true),
+				boolDiff(t.IsNew()),
+				t.ID.String(),
 				t.Name,
-				green(t.Description),
-				green(timing),
+				diffLn(t.IsNew(), old.Description, t.New.Description),
+				diffLn(t.IsNew(), timingFn(old), timingFn(t.New)),
 			}
 		})
 	}
@@ -841,10 +884,14 @@ func (b *cmdPkgBuilder) printPkgDiff(diff pkger.Diff) error {
 			if v.New.Args != nil {
 				newArgType = v.New.Args.Type
 			}
+			name := v.Name
+			if v.NameCollision {
+				name = red(name + " (name collision)")
+			}
 			return []string{
 				boolDiff(v.IsNew()),
 				v.ID.String(),
-				v.Name,
+				name,
 				diffLn(v.IsNew(), old.Description, v.New.Description),
 				diffLn(v.IsNew(), oldArgType, newArgType),
 				diffLn(v.IsNew(), printVarArgs(old.Args), printVarArgs(v.New.Args)),
@@ -853,11 +900,12 @@ func (b *cmdPkgBuilder) printPkgDiff(diff pkger.Diff) error {
 	}
 
 	if len(diff.LabelMappings) > 0 {
-		headers := []string{"New", "Resource Type", "Resource Name", "Resource ID", "Label Name", "Label ID"}
+		headers := []string{"New", "Not In Pkg", "Resource Type", "Resource Name", "Resource ID", "Label Name", "Label ID"}
 		tablePrintFn("LABEL MAPPINGS", headers, len(diff.LabelMappings), func(i int) []string {
 			m := diff.LabelMappings[i]
 			return []string{
 				boolDiff(m.IsNew),
+				boolDiff(m.NotInPkg),
 				string(m.ResType),
 				m.ResName,
 				m.ResID.String(),
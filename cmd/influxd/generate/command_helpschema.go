@@ -164,6 +164,16 @@ tags = [
 #       v:    float ≥ 1 (required)
 #       imax: integer (required)
 #
+# type: "rand<string>"
+#   generate random string values selected from a fixed number of distinct
+#   strings, which controls how well the generated blocks compress.
+#
+#       seed:         seed to random number generator (default: 0)
+#       cardinality:  number of distinct strings, integer ≥ 1 (required)
+#       length:       length of each string, integer ≥ 1 (default: 8)
+#       distribution: "uniform" or "zipf" (default: "uniform")
+#       s:            float > 1, Zipf parameter (required for "zipf")
+#
 fields = [
     # Example constant float
     { name = "system", count = 5000, source = 2.5 },
@@ -431,6 +431,57 @@ func TestService_handlePostBucket(t *testing.T) {
   "retentionRules": [],
   "labels": []
 }
+`,
+			},
+		},
+		{
+			name: "create a new bucket with infinite retention and a shard group duration",
+			fields: fields{
+				BucketService: &mock.BucketService{
+					CreateBucketFn: func(ctx context.Context, c *platform.Bucket) error {
+						if c.RetentionPeriod != 0 || c.ShardGroupDuration != time.Hour {
+							return fmt.Errorf("unexpected retention %v and shard group duration %v", c.RetentionPeriod, c.ShardGroupDuration)
+						}
+						c.ID = platformtesting.MustIDBase16("020f755c3c082000")
+						return nil
+					},
+				},
+				OrganizationService: &mock.OrganizationService{
+					FindOrganizationF: func(ctx context.Context, f platform.OrganizationFilter) (*platform.Organization, error) {
+						return &platform.Organization{ID: platformtesting.MustIDBase16("6f626f7274697320")}, nil
+					},
+				},
+			},
+			args: args{
+				bucket: &platform.Bucket{
+					Name:               "hello",
+					OrgID:              platformtesting.MustIDBase16("6f626f7274697320"),
+					ShardGroupDuration: time.Hour,
+				},
+			},
+			wants: wants{
+				statusCode:  http.StatusCreated,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/6f626f7274697320",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "write": "/api/v2/write?org=6f626f7274697320&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "6f626f7274697320",
+	"type": "user",
+  "name": "hello",
+  "retentionRules": [{"type": "expire", "everySeconds": 0, "shardGroupDurationSeconds": 3600}],
+  "labels": []
+}
 `,
 			},
 		},
@@ -609,9 +660,10 @@ func TestService_handlePatchBucket(t *testing.T) {
 		BucketService platform.BucketService
 	}
 	type args struct {
-		id        string
-		name      string
-		retention time.Duration
+		id                 string
+		name               string
+		retention          time.Duration
+		shardGroupDuration time.Duration
 	}
 	type wants struct {
 		statusCode  int
@@ -687,6 +739,66 @@ func TestService_handlePatchBucket(t *testing.T) {
   "retentionRules": [{"type": "expire", "everySeconds": 2}],
   "labels": []
 }
+`,
+			},
+		},
+		{
+			name: "update the shard group duration without retention",
+			fields: fields{
+				&mock.BucketService{
+					FindBucketByIDFn: func(ctx context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+						return &platform.Bucket{
+							ID:    platformtesting.MustIDBase16("020f755c3c082000"),
+							Name:  "hello",
+							OrgID: platformtesting.MustIDBase16("020f755c3c082000"),
+						}, nil
+					},
+					UpdateBucketFn: func(ctx context.Context, id platform.ID, upd platform.BucketUpdate) (*platform.Bucket, error) {
+						d := &platform.Bucket{
+							ID:    platformtesting.MustIDBase16("020f755c3c082000"),
+							Name:  "hello",
+							OrgID: platformtesting.MustIDBase16("020f755c3c082000"),
+						}
+
+						if upd.RetentionPeriod != nil {
+							d.RetentionPeriod = *upd.RetentionPeriod
+						}
+
+						if upd.ShardGroupDuration != nil {
+							d.ShardGroupDuration = *upd.ShardGroupDuration
+						}
+
+						return d, nil
+					},
+				},
+			},
+			args: args{
+				id:                 "020f755c3c082000",
+				shardGroupDuration: time.Hour,
+			},
+			wants: wants{
+				statusCode:  http.StatusOK,
+				contentType: "application/json; charset=utf-8",
+				body: `
+{
+  "links": {
+    "org": "/api/v2/orgs/020f755c3c082000",
+    "self": "/api/v2/buckets/020f755c3c082000",
+    "logs": "/api/v2/buckets/020f755c3c082000/logs",
+    "labels": "/api/v2/buckets/020f755c3c082000/labels",
+    "members": "/api/v2/buckets/020f755c3c082000/members",
+    "owners": "/api/v2/buckets/020f755c3c082000/owners",
+    "write": "/api/v2/write?org=020f755c3c082000&bucket=020f755c3c082000"
+  },
+  "createdAt": "0001-01-01T00:00:00Z",
+  "updatedAt": "0001-01-01T00:00:00Z",
+  "id": "020f755c3c082000",
+  "orgID": "020f755c3c082000",
+	"type": "user",
+  "name": "hello",
+  "retentionRules": [{"type": "expire", "everySeconds": 0, "shardGroupDurationSeconds": 3600}],
+  "labels": []
+}
 `,
 			},
 		},
@@ -919,7 +1031,7 @@ func TestService_handlePatchBucket(t *testing.T) {
 			args: args{
 				id:        "020f755c3c082000",
 				name:      "example",
-				retention: -10,
+				retention: -10 * time.Second,
 			},
 			wants: wants{
 				statusCode: http.StatusUnprocessableEntity,
@@ -943,6 +1055,10 @@ func TestService_handlePatchBucket(t *testing.T) {
 				upd.RetentionPeriod = &tt.args.retention
 			}
 
+			if tt.args.shardGroupDuration != 0 {
+				upd.ShardGroupDuration = &tt.args.shardGroupDuration
+			}
+
 			b, err := json.Marshal(newBucketUpdate(&upd))
 			if err != nil {
 				t.Fatalf("failed to unmarshal bucket update: %v", err)
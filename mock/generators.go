@@ -1,6 +1,7 @@
 package mock
 
 import (
+	"sync"
 	"testing"
 	"time"
 
@@ -52,6 +53,29 @@ func (g *MockIDGenerator) ID() platform.ID {
 	return id
 }
 
+// IncrementingIDGenerator is a platform.IDGenerator that hands out
+// sequential IDs, making generated IDs predictable in tests. It is safe
+// for concurrent use.
+type IncrementingIDGenerator struct {
+	mu   sync.Mutex
+	next platform.ID
+}
+
+// NewIncrementingIDGenerator creates an id generator whose first generated
+// ID is start.
+func NewIncrementingIDGenerator(start platform.ID) *IncrementingIDGenerator {
+	return &IncrementingIDGenerator{next: start}
+}
+
+// ID returns the next ID in the sequence.
+func (g *IncrementingIDGenerator) ID() platform.ID {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	id := g.next
+	g.next++
+	return id
+}
+
 // NewTokenGenerator is a simple way to create immutable token generator.
 func NewTokenGenerator(s string, err error) TokenGenerator {
 	return TokenGenerator{
@@ -7,6 +7,8 @@
 package gen
 
 import (
+	"math/rand"
+
 	"github.com/influxdata/influxdb/tsdb/cursors"
 	"github.com/influxdata/influxdb/tsdb/tsm1"
 )
@@ -28,6 +30,32 @@ func newFloatArrayLen(sz int) *floatArray {
 	}
 }
 
+// newFloatArrayFrom returns an array containing a copy of the timestamps
+// ts and values vs, which must have the same length.
+func newFloatArrayFrom(ts []int64, vs []float64) *floatArray {
+	if len(ts) != len(vs) {
+		panic("gen: timestamps and values must have the same length")
+	}
+	a := newFloatArrayLen(len(ts))
+	copy(a.Timestamps, ts)
+	copy(a.Values, vs)
+	return a
+}
+
+// newFloatArrayRand returns an array of sz random values with ascending
+// timestamps. The values are drawn from r, so arrays created from sources
+// with the same seed are identical.
+func newFloatArrayRand(sz int, r *rand.Rand) *floatArray {
+	a := newFloatArrayLen(sz)
+	var ts int64
+	for i := 0; i < sz; i++ {
+		ts += randTimestampDelta(r)
+		a.Timestamps[i] = ts
+		a.Values[i] = randFloat(r)
+	}
+	return a
+}
+
 func (a *floatArray) Encode(b []byte) ([]byte, error) {
 	return tsm1.EncodeFloatArrayBlock(&a.FloatArray, b)
 }
@@ -54,6 +82,32 @@ func newIntegerArrayLen(sz int) *integerArray {
 	}
 }
 
+// newIntegerArrayFrom returns an array containing a copy of the timestamps
+// ts and values vs, which must have the same length.
+func newIntegerArrayFrom(ts []int64, vs []int64) *integerArray {
+	if len(ts) != len(vs) {
+		panic("gen: timestamps and values must have the same length")
+	}
+	a := newIntegerArrayLen(len(ts))
+	copy(a.Timestamps, ts)
+	copy(a.Values, vs)
+	return a
+}
+
+// newIntegerArrayRand returns an array of sz random values with ascending
+// timestamps. The values are drawn from r, so arrays created from sources
+// with the same seed are identical.
+func newIntegerArrayRand(sz int, r *rand.Rand) *integerArray {
+	a := newIntegerArrayLen(sz)
+	var ts int64
+	for i := 0; i < sz; i++ {
+		ts += randTimestampDelta(r)
+		a.Timestamps[i] = ts
+		a.Values[i] = randInteger(r)
+	}
+	return a
+}
+
 func (a *integerArray) Encode(b []byte) ([]byte, error) {
 	return tsm1.EncodeIntegerArrayBlock(&a.IntegerArray, b)
 }
@@ -80,6 +134,32 @@ func newUnsignedArrayLen(sz int) *unsignedArray {
 	}
 }
 
+// newUnsignedArrayFrom returns an array containing a copy of the timestamps
+// ts and values vs, which must have the same length.
+func newUnsignedArrayFrom(ts []int64, vs []uint64) *unsignedArray {
+	if len(ts) != len(vs) {
+		panic("gen: timestamps and values must have the same length")
+	}
+	a := newUnsignedArrayLen(len(ts))
+	copy(a.Timestamps, ts)
+	copy(a.Values, vs)
+	return a
+}
+
+// newUnsignedArrayRand returns an array of sz random values with ascending
+// timestamps. The values are drawn from r, so arrays created from sources
+// with the same seed are identical.
+func newUnsignedArrayRand(sz int, r *rand.Rand) *unsignedArray {
+	a := newUnsignedArrayLen(sz)
+	var ts int64
+	for i := 0; i < sz; i++ {
+		ts += randTimestampDelta(r)
+		a.Timestamps[i] = ts
+		a.Values[i] = randUnsigned(r)
+	}
+	return a
+}
+
 func (a *unsignedArray) Encode(b []byte) ([]byte, error) {
 	return tsm1.EncodeUnsignedArrayBlock(&a.UnsignedArray, b)
 }
@@ -106,6 +186,32 @@ func newStringArrayLen(sz int) *stringArray {
 	}
 }
 
+// newStringArrayFrom returns an array containing a copy of the timestamps
+// ts and values vs, which must have the same length.
+func newStringArrayFrom(ts []int64, vs []string) *stringArray {
+	if len(ts) != len(vs) {
+		panic("gen: timestamps and values must have the same length")
+	}
+	a := newStringArrayLen(len(ts))
+	copy(a.Timestamps, ts)
+	copy(a.Values, vs)
+	return a
+}
+
+// newStringArrayRand returns an array of sz random values with ascending
+// timestamps. The values are drawn from r, so arrays created from sources
+// with the same seed are identical.
+func newStringArrayRand(sz int, r *rand.Rand) *stringArray {
+	a := newStringArrayLen(sz)
+	var ts int64
+	for i := 0; i < sz; i++ {
+		ts += randTimestampDelta(r)
+		a.Timestamps[i] = ts
+		a.Values[i] = randString(r)
+	}
+	return a
+}
+
 func (a *stringArray) Encode(b []byte) ([]byte, error) {
 	return tsm1.EncodeStringArrayBlock(&a.StringArray, b)
 }
@@ -132,6 +238,32 @@ func newBooleanArrayLen(sz int) *booleanArray {
 	}
 }
 
+// newBooleanArrayFrom returns an array containing a copy of the timestamps
+// ts and values vs, which must have the same length.
+func newBooleanArrayFrom(ts []int64, vs []bool) *booleanArray {
+	if len(ts) != len(vs) {
+		panic("gen: timestamps and values must have the same length")
+	}
+	a := newBooleanArrayLen(len(ts))
+	copy(a.Timestamps, ts)
+	copy(a.Values, vs)
+	return a
+}
+
+// newBooleanArrayRand returns an array of sz random values with ascending
+// timestamps. The values are drawn from r, so arrays created from sources
+// with the same seed are identical.
+func newBooleanArrayRand(sz int, r *rand.Rand) *booleanArray {
+	a := newBooleanArrayLen(sz)
+	var ts int64
+	for i := 0; i < sz; i++ {
+		ts += randTimestampDelta(r)
+		a.Timestamps[i] = ts
+		a.Values[i] = randBoolean(r)
+	}
+	return a
+}
+
 func (a *booleanArray) Encode(b []byte) ([]byte, error) {
 	return tsm1.EncodeBooleanArrayBlock(&a.BooleanArray, b)
 }
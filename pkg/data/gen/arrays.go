@@ -0,0 +1,63 @@
+package gen
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+// randTimestampDelta returns the delta between consecutive timestamps of an
+// array created by one of the new*ArrayRand functions.
+func randTimestampDelta(r *rand.Rand) int64 {
+	return 1 + r.Int63n(int64(time.Second))
+}
+
+func randFloat(r *rand.Rand) float64 { return r.Float64() }
+
+func randInteger(r *rand.Rand) int64 { return r.Int63() - r.Int63() }
+
+func randUnsigned(r *rand.Rand) uint64 { return r.Uint64() }
+
+func randBoolean(r *rand.Rand) bool { return r.Intn(2) == 1 }
+
+func randString(r *rand.Rand) string {
+	b := make([]byte, 8)
+	for i := range b {
+		b[i] = stringAlphabet[r.Intn(len(stringAlphabet))]
+	}
+	return string(b)
+}
+
+// newIntegerArrayFromFloat returns an array with the timestamps of src and its
+// values truncated to integers.
+func newIntegerArrayFromFloat(src *cursors.FloatArray) *integerArray {
+	a := newIntegerArrayLen(src.Len())
+	copy(a.Timestamps, src.Timestamps)
+	for i, v := range src.Values {
+		a.Values[i] = int64(v)
+	}
+	return a
+}
+
+// newFloatArrayFromInteger returns an array with the timestamps and values
+// of src converted to floats.
+func newFloatArrayFromInteger(src *cursors.IntegerArray) *floatArray {
+	a := newFloatArrayLen(src.Len())
+	copy(a.Timestamps, src.Timestamps)
+	for i, v := range src.Values {
+		a.Values[i] = float64(v)
+	}
+	return a
+}
+
+// newUnsignedArrayFromInteger returns an array with the timestamps and values
+// of src converted to unsigned integers.
+func newUnsignedArrayFromInteger(src *cursors.IntegerArray) *unsignedArray {
+	a := newUnsignedArrayLen(src.Len())
+	copy(a.Timestamps, src.Timestamps)
+	for i, v := range src.Values {
+		a.Values[i] = uint64(v)
+	}
+	return a
+}
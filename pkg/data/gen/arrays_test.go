@@ -0,0 +1,84 @@
+package gen
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/influxdata/influxdb/tsdb/cursors"
+)
+
+func TestNewArrayRand(t *testing.T) {
+	const sz = 1000
+
+	newRand := func() *rand.Rand { return rand.New(rand.NewSource(10)) }
+
+	a := newIntegerArrayRand(sz, newRand())
+	b := newIntegerArrayRand(sz, newRand())
+	if !cmp.Equal(a.IntegerArray, b.IntegerArray) {
+		t.Errorf("unexpected arrays for the same seed, -got/+exp\n%s", cmp.Diff(a.IntegerArray, b.IntegerArray))
+	}
+
+	c := newIntegerArrayRand(sz, rand.New(rand.NewSource(11)))
+	if cmp.Equal(a.IntegerArray, c.IntegerArray) {
+		t.Error("expected arrays for different seeds to differ")
+	}
+
+	for i := 1; i < sz; i++ {
+		if a.Timestamps[i] <= a.Timestamps[i-1] {
+			t.Fatalf("timestamps not ascending at %d: %d ≤ %d", i, a.Timestamps[i], a.Timestamps[i-1])
+		}
+	}
+
+	s1 := newStringArrayRand(sz, newRand())
+	s2 := newStringArrayRand(sz, newRand())
+	if !cmp.Equal(s1.StringArray, s2.StringArray) {
+		t.Errorf("unexpected arrays for the same seed, -got/+exp\n%s", cmp.Diff(s1.StringArray, s2.StringArray))
+	}
+}
+
+func TestNewArrayFrom(t *testing.T) {
+	ts := []int64{10, 20, 30}
+	vs := []int64{-2, 0, 5}
+
+	a := newIntegerArrayFrom(ts, vs)
+	exp := cursors.IntegerArray{Timestamps: ts, Values: vs}
+	if !cmp.Equal(a.IntegerArray, exp) {
+		t.Errorf("unexpected array, -got/+exp\n%s", cmp.Diff(a.IntegerArray, exp))
+	}
+
+	// the array must not share the backing arrays of its sources
+	ts[0], vs[0] = 0, 0
+	if a.Timestamps[0] != 10 || a.Values[0] != -2 {
+		t.Errorf("array modified by its source: %v, %v", a.Timestamps, a.Values)
+	}
+}
+
+func TestNewArrayConversions(t *testing.T) {
+	t.Run("integer from float", func(t *testing.T) {
+		src := newFloatArrayFrom([]int64{1, 2, 3, 4}, []float64{1.9, -1.9, 0.5, 42})
+		got := newIntegerArrayFromFloat(&src.FloatArray)
+		exp := cursors.IntegerArray{Timestamps: []int64{1, 2, 3, 4}, Values: []int64{1, -1, 0, 42}}
+		if !cmp.Equal(got.IntegerArray, exp) {
+			t.Errorf("unexpected array, -got/+exp\n%s", cmp.Diff(got.IntegerArray, exp))
+		}
+	})
+
+	t.Run("float from integer", func(t *testing.T) {
+		src := newIntegerArrayFrom([]int64{1, 2}, []int64{-3, 7})
+		got := newFloatArrayFromInteger(&src.IntegerArray)
+		exp := cursors.FloatArray{Timestamps: []int64{1, 2}, Values: []float64{-3, 7}}
+		if !cmp.Equal(got.FloatArray, exp) {
+			t.Errorf("unexpected array, -got/+exp\n%s", cmp.Diff(got.FloatArray, exp))
+		}
+	})
+
+	t.Run("unsigned from integer", func(t *testing.T) {
+		src := newIntegerArrayFrom([]int64{1, 2}, []int64{0, 7})
+		got := newUnsignedArrayFromInteger(&src.IntegerArray)
+		exp := cursors.UnsignedArray{Timestamps: []int64{1, 2}, Values: []uint64{0, 7}}
+		if !cmp.Equal(got.UnsignedArray, exp) {
+			t.Errorf("unexpected array, -got/+exp\n%s", cmp.Diff(got.UnsignedArray, exp))
+		}
+	})
+}
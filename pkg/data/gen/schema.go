@@ -172,6 +172,28 @@ func (f *FieldIntegerZipfSource) String() string {
 	return fmt.Sprintf("rand<float>, seed=%d, s=%f, v=%f, imax=%d", f.Seed, f.S, f.V, f.IMAX)
 }
 
+// FieldStringRandomSource generates random strings drawn from a fixed set of
+// Cardinality distinct values, each Length bytes long. When Distribution is
+// "zipf", values are selected using a Zipf distribution with parameter S,
+// otherwise every value is equally likely.
+type FieldStringRandomSource struct {
+	Seed         int64
+	Cardinality  int
+	Length       int
+	Distribution string
+	S            float64
+}
+
+func (*FieldStringRandomSource) node()        {}
+func (*FieldStringRandomSource) fieldsource() {}
+
+func (f *FieldStringRandomSource) String() string {
+	if f.Distribution == "zipf" {
+		return fmt.Sprintf("rand<string>, seed=%d, cardinality=%d, length=%d, distribution=%s, s=%f", f.Seed, f.Cardinality, f.Length, f.Distribution, f.S)
+	}
+	return fmt.Sprintf("rand<string>, seed=%d, cardinality=%d, length=%d, distribution=%s", f.Seed, f.Cardinality, f.Length, f.Distribution)
+}
+
 type VisitorFn func(node SchemaNode) bool
 
 func (fn VisitorFn) Visit(node SchemaNode) (w Visitor) {
@@ -242,7 +264,7 @@ func walk(v Visitor, node SchemaNode, up bool) Visitor {
 	case *Field:
 		walk(v, n.Source, up)
 
-	case *FieldConstantValue, *FieldArraySource, *FieldFloatRandomSource, *FieldIntegerZipfSource:
+	case *FieldConstantValue, *FieldArraySource, *FieldFloatRandomSource, *FieldIntegerZipfSource, *FieldStringRandomSource:
 		// nothing to do
 
 	default:
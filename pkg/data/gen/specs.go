@@ -446,6 +446,18 @@ func (s *schemaToSpec) visit(node SchemaNode) bool {
 		})
 		s.push(&fs)
 
+	case *FieldStringRandomSource:
+		var fs FieldValuesSpec
+		fs.DataType = models.String
+		fs.Values = NewTimeValuesSequenceFn(func(spec TimeSequenceSpec) TimeValuesSequence {
+			return NewTimeStringValuesSequence(
+				spec.Count,
+				NewTimestampSequenceFromSpec(spec),
+				NewStringRandomValuesSequence(n),
+			)
+		})
+		s.push(&fs)
+
 	case *Tag:
 		s.push(&TagValuesSpec{
 			TagKey: n.Name,
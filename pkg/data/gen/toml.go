@@ -374,6 +374,8 @@ func decodeFieldSource(data map[string]interface{}) (FieldSource, error) {
 		return decodeFloatRandomSource(data)
 	case "zipf<integer>":
 		return decodeIntegerZipfSource(data)
+	case "rand<string>":
+		return decodeStringRandomSource(data)
 	default:
 		return nil, fmt.Errorf("invalid type field %q", typ)
 	}
@@ -458,3 +460,59 @@ func decodeIntegerZipfSource(data map[string]interface{}) (FieldSource, error) {
 
 	return &s, nil
 }
+
+func decodeStringRandomSource(data map[string]interface{}) (FieldSource, error) {
+	s := FieldStringRandomSource{Length: 8, Distribution: "uniform"}
+
+	if v, ok := data["seed"]; ok {
+		if v, err := cast.ToInt64E(v); err != nil {
+			return nil, fmt.Errorf("rand<string>: invalid seed, %v", err)
+		} else {
+			s.Seed = v
+		}
+	}
+
+	if v, ok := data["cardinality"]; ok {
+		if v, err := cast.ToIntE(v); err != nil || v < 1 {
+			return nil, fmt.Errorf("rand<string>: invalid value for cardinality (cardinality ≥ 1), %v", err)
+		} else {
+			s.Cardinality = v
+		}
+	} else {
+		return nil, errors.New("rand<string>: missing value for cardinality")
+	}
+
+	if v, ok := data["length"]; ok {
+		if v, err := cast.ToIntE(v); err != nil || v < 1 {
+			return nil, fmt.Errorf("rand<string>: invalid value for length (length ≥ 1), %v", err)
+		} else {
+			s.Length = v
+		}
+	}
+
+	if !stringCardinalityFits(s.Cardinality, s.Length) {
+		return nil, fmt.Errorf("rand<string>: cardinality %d exceeds the distinct strings of length %d", s.Cardinality, s.Length)
+	}
+
+	if v, ok := data["distribution"]; ok {
+		if v, err := cast.ToStringE(v); err != nil || (v != "uniform" && v != "zipf") {
+			return nil, fmt.Errorf("rand<string>: invalid value for distribution (uniform or zipf), %v", err)
+		} else {
+			s.Distribution = v
+		}
+	}
+
+	if s.Distribution == "zipf" {
+		if v, ok := data["s"]; ok {
+			if v, err := cast.ToFloat64E(v); err != nil || v <= 1.0 {
+				return nil, fmt.Errorf("rand<string>: invalid value for s (s > 1), %v", err)
+			} else {
+				s.S = v
+			}
+		} else {
+			return nil, errors.New("rand<string>: missing value for s")
+		}
+	}
+
+	return &s, nil
+}
@@ -107,6 +107,16 @@ name = "random"
         source = { type = "rand<float>", min = 0.5, max = 50.1, seed = 10 }
         time-precision = "us"
 
+    [[measurements.fields]]
+        name   = "stringR"
+        count  = 5000
+        source = { type = "rand<string>", cardinality = 20, seed = 10 }
+
+    [[measurements.fields]]
+        name   = "stringZ"
+        count  = 5000
+        source = { type = "rand<string>", cardinality = 1000, length = 4, distribution = "zipf", s = 1.5 }
+
 [[measurements]]
 name = "array"
 
@@ -154,6 +164,8 @@ name = "array"
     tagSeq: sequence, prefix="value%s", range=[0,100)
   Fields:
     floatR: rand<float>, seed=10, min=50.100000, max=50.100000, count=5000, time-precision=Microsecond
+    stringR: rand<string>, seed=10, cardinality=20, length=8, distribution=uniform, count=5000, time-precision=Millisecond
+    stringZ: rand<string>, seed=0, cardinality=1000, length=4, distribution=zipf, s=1.500000, count=5000, time-precision=Millisecond
 
   Name: array
   Tags:
@@ -44,3 +44,65 @@ func (g *integerRandomValuesSequence) Write(vs []int64) {
 		vs[i] = int64(g.r.Uint64())
 	}
 }
+
+// stringAlphabet is the set of bytes used to build the values of a
+// stringRandomValuesSequence.
+const stringAlphabet = "abcdefghijklmnopqrstuvwxyz0123456789"
+
+// stringCardinalityFits returns true if there are at least card distinct
+// strings of length n built from stringAlphabet.
+func stringCardinalityFits(card, n int) bool {
+	total := 1
+	for i := 0; i < n; i++ {
+		total *= len(stringAlphabet)
+		if total >= card {
+			return true
+		}
+	}
+	return total >= card
+}
+
+type stringRandomValuesSequence struct {
+	vals []string
+	next func() int
+}
+
+// NewStringRandomValuesSequence produces string values selected from a set of
+// s.Cardinality distinct random strings. The values are selected uniformly or
+// using a Zipfian distribution, as described by s, which allows the
+// compressibility of the generated blocks to be controlled.
+func NewStringRandomValuesSequence(s *FieldStringRandomSource) StringValuesSequence {
+	r := rand.New(rand.NewSource(s.Seed))
+
+	seen := make(map[string]struct{}, s.Cardinality)
+	vals := make([]string, 0, s.Cardinality)
+	buf := make([]byte, s.Length)
+	for len(vals) < s.Cardinality {
+		for i := range buf {
+			buf[i] = stringAlphabet[r.Intn(len(stringAlphabet))]
+		}
+		if _, ok := seen[string(buf)]; ok {
+			continue
+		}
+		seen[string(buf)] = struct{}{}
+		vals = append(vals, string(buf))
+	}
+
+	g := &stringRandomValuesSequence{vals: vals}
+	if s.Distribution == "zipf" && len(vals) > 1 {
+		z := rand.NewZipf(r, s.S, 1, uint64(len(vals)-1))
+		g.next = func() int { return int(z.Uint64()) }
+	} else {
+		n := len(vals)
+		g.next = func() int { return r.Intn(n) }
+	}
+	return g
+}
+
+func (g *stringRandomValuesSequence) Reset() {}
+
+func (g *stringRandomValuesSequence) Write(vs []string) {
+	for i := 0; i < len(vs); i++ {
+		vs[i] = g.vals[g.next()]
+	}
+}
@@ -0,0 +1,51 @@
+package gen
+
+import (
+	"testing"
+)
+
+func TestStringRandomValuesSequence(t *testing.T) {
+	tests := []struct {
+		name string
+		src  FieldStringRandomSource
+	}{
+		{
+			name: "uniform",
+			src:  FieldStringRandomSource{Seed: 10, Cardinality: 20, Length: 8, Distribution: "uniform"},
+		},
+		{
+			name: "zipf",
+			src:  FieldStringRandomSource{Seed: 10, Cardinality: 20, Length: 8, Distribution: "zipf", S: 1.1},
+		},
+		{
+			name: "single value",
+			src:  FieldStringRandomSource{Seed: 10, Cardinality: 1, Length: 1, Distribution: "zipf", S: 1.1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vs := make([]string, 10000)
+			NewStringRandomValuesSequence(&tt.src).Write(vs)
+
+			distinct := make(map[string]struct{})
+			for _, v := range vs {
+				if len(v) != tt.src.Length {
+					t.Fatalf("unexpected length for %q: got %d, exp %d", v, len(v), tt.src.Length)
+				}
+				distinct[v] = struct{}{}
+			}
+			if got, exp := len(distinct), tt.src.Cardinality; got != exp {
+				t.Errorf("unexpected cardinality: got %d, exp %d", got, exp)
+			}
+
+			again := make([]string, len(vs))
+			NewStringRandomValuesSequence(&tt.src).Write(again)
+			for i := range vs {
+				if vs[i] != again[i] {
+					t.Fatalf("sequence with the same seed differs at %d: %q != %q", i, vs[i], again[i])
+				}
+			}
+		})
+	}
+}
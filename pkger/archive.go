@@ -0,0 +1,155 @@
+package pkger
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path"
+	"strings"
+)
+
+// MaxArchiveSize is the largest archive, in bytes, ParseArchive reads.
+const MaxArchiveSize = 32 << 20
+
+var (
+	// ErrInvalidArchive indicates the archive is not a gzipped tar or zip archive.
+	ErrInvalidArchive = errors.New("invalid archive provided: must be a gzipped tar or zip archive")
+
+	// ErrArchiveTooLarge indicates the archive is larger than MaxArchiveSize.
+	ErrArchiveTooLarge = fmt.Errorf("invalid archive provided: must be no larger than %d bytes", MaxArchiveSize)
+)
+
+// IsArchive returns true when the name of a file or URL has the extension of
+// an archive supported by ParseArchive.
+func IsArchive(name string) bool {
+	name = strings.ToLower(name)
+	for _, ext := range []string{".tar.gz", ".tgz", ".zip"} {
+		if strings.HasSuffix(name, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseArchive parses a pkg from a gzipped tar or zip archive. Every yaml, json,
+// and jsonnet file within the archive is parsed and the results are combined
+// into a single pkg, as Combine would. Files of any other type are ignored.
+// The archive format is inferred from the contents of the reader. An archive
+// larger than MaxArchiveSize is rejected.
+func ParseArchive(readerFn ReaderFn, opts ...ValidateOptFn) (*Pkg, error) {
+	r, err := readerFn()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(io.LimitReader(r, MaxArchiveSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pkg archive: %s", err)
+	}
+	if len(b) > MaxArchiveSize {
+		return nil, ErrArchiveTooLarge
+	}
+
+	var pkgs []*Pkg
+	parseFile := func(name string, r io.Reader) error {
+		encoding, ok := archiveFileEncoding(name)
+		if !ok {
+			return nil
+		}
+		pkg, err := ParseReader(encoding, r, ValidWithoutResources(), ValidSkipParseError())
+		if err != nil {
+			return fmt.Errorf("failed to parse archive file %q: %s", name, err)
+		}
+		pkgs = append(pkgs, pkg)
+		return nil
+	}
+
+	switch {
+	case bytes.HasPrefix(b, []byte{0x1f, 0x8b}):
+		err = walkTarGz(b, parseFile)
+	case bytes.HasPrefix(b, []byte("PK\x03\x04")):
+		err = walkZip(b, parseFile)
+	default:
+		return nil, ErrInvalidArchive
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return Combine(pkgs, opts...)
+}
+
+func walkTarGz(b []byte, fn func(name string, r io.Reader) error) error {
+	gr, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		return fmt.Errorf("failed to read gzip archive: %s", err)
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %s", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		if err := fn(hdr.Name, tr); err != nil {
+			return err
+		}
+	}
+}
+
+func walkZip(b []byte, fn func(name string, r io.Reader) error) error {
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %s", err)
+	}
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if err := func() error {
+			rc, err := f.Open()
+			if err != nil {
+				return fmt.Errorf("failed to open archive file %q: %s", f.Name, err)
+			}
+			defer rc.Close()
+			return fn(f.Name, rc)
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// archiveFileEncoding returns the encoding of a file within an archive. Hidden
+// files, such as the metadata files some archivers add, are skipped along with
+// any file that is not a pkg.
+func archiveFileEncoding(name string) (Encoding, bool) {
+	base := path.Base(name)
+	if strings.HasPrefix(base, ".") {
+		return EncodingUnknown, false
+	}
+
+	switch strings.ToLower(path.Ext(base)) {
+	case ".json":
+		return EncodingJSON, true
+	case ".jsonnet":
+		return EncodingJsonnet, true
+	case ".yml", ".yaml":
+		return EncodingYAML, true
+	default:
+		return EncodingUnknown, false
+	}
+}
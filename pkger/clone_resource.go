@@ -90,6 +90,10 @@ type resourceExporter struct {
 
 	mObjects  map[exportKey]Object
 	mPkgNames map[string]bool
+
+	// streamFn, when set, receives each object as it is exported. Only a stub
+	// of the object is retained, to resolve references by name.
+	streamFn func(Object) error
 }
 
 func newResourceExporter(svc *Service) *resourceExporter {
@@ -159,6 +163,23 @@ func (ex *resourceExporter) Objects() []Object {
 	return objects
 }
 
+func (ex *resourceExporter) addObject(key exportKey, object Object) error {
+	if ex.streamFn == nil {
+		ex.mObjects[key] = object
+		return nil
+	}
+
+	// a streamed object can not be replaced after it has been written out
+	if _, ok := ex.mObjects[key]; ok {
+		return nil
+	}
+	ex.mObjects[key] = Object{
+		Kind:     object.Kind,
+		Metadata: Resource{fieldName: object.Name()},
+	}
+	return ex.streamFn(object)
+}
+
 func (ex *resourceExporter) uniqByNameResID() influxdb.ID {
 	// we only need an id when we have resources that are not unique by name via the
 	// metastore. resoureces that are unique by name will be provided a default stamp
@@ -184,7 +205,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		return nil
 	}
 
-	mapResource := func(orgID, uniqResID influxdb.ID, k Kind, object Object) {
+	mapResource := func(orgID, uniqResID influxdb.ID, k Kind, object Object) error {
 		// overwrite the default metadata.name field with export generated one here
 		object.Metadata[fieldName] = ex.uniqName()
 
@@ -192,7 +213,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 			object.Spec[fieldAssociations] = ass
 		}
 		key := newExportKey(orgID, uniqResID, k, object.Spec.stringShort(fieldName))
-		ex.mObjects[key] = object
+		return ex.addObject(key, object)
 	}
 
 	uniqByNameResID := ex.uniqByNameResID()
@@ -203,7 +224,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		if err != nil {
 			return err
 		}
-		mapResource(bkt.OrgID, uniqByNameResID, KindBucket, bucketToObject(*bkt, r.Name))
+		return mapResource(bkt.OrgID, uniqByNameResID, KindBucket, bucketToObject(*bkt, r.Name))
 	case r.Kind.is(KindCheck),
 		r.Kind.is(KindCheckDeadman),
 		r.Kind.is(KindCheckThreshold):
@@ -211,19 +232,19 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		if err != nil {
 			return err
 		}
-		mapResource(ch.GetOrgID(), uniqByNameResID, KindCheck, checkToObject(ch, r.Name))
+		return mapResource(ch.GetOrgID(), uniqByNameResID, KindCheck, checkToObject(ch, r.Name))
 	case r.Kind.is(KindDashboard):
 		dash, err := ex.findDashboardByIDFull(ctx, r.ID)
 		if err != nil {
 			return err
 		}
-		mapResource(dash.OrganizationID, dash.ID, KindDashboard, DashboardToObject(*dash, r.Name))
+		return mapResource(dash.OrganizationID, dash.ID, KindDashboard, DashboardToObject(*dash, r.Name))
 	case r.Kind.is(KindLabel):
 		l, err := ex.labelSVC.FindLabelByID(ctx, r.ID)
 		if err != nil {
 			return err
 		}
-		mapResource(l.OrgID, uniqByNameResID, KindLabel, labelToObject(*l, r.Name))
+		return mapResource(l.OrgID, uniqByNameResID, KindLabel, labelToObject(*l, r.Name))
 	case r.Kind.is(KindNotificationEndpoint),
 		r.Kind.is(KindNotificationEndpointHTTP),
 		r.Kind.is(KindNotificationEndpointPagerDuty),
@@ -232,7 +253,7 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		if err != nil {
 			return err
 		}
-		mapResource(e.GetOrgID(), uniqByNameResID, KindNotificationEndpoint, endpointKind(e, r.Name))
+		return mapResource(e.GetOrgID(), uniqByNameResID, KindNotificationEndpoint, endpointKind(e, r.Name))
 	case r.Kind.is(KindNotificationRule):
 		rule, ruleEndpoint, err := ex.getEndpointRule(ctx, r.ID)
 		if err != nil {
@@ -242,35 +263,36 @@ func (ex *resourceExporter) resourceCloneToKind(ctx context.Context, r ResourceT
 		endpointKey := newExportKey(ruleEndpoint.GetOrgID(), uniqByNameResID, KindNotificationEndpoint, ruleEndpoint.GetName())
 		object, ok := ex.mObjects[endpointKey]
 		if !ok {
-			mapResource(ruleEndpoint.GetOrgID(), uniqByNameResID, KindNotificationEndpoint, endpointKind(ruleEndpoint, ""))
+			err := mapResource(ruleEndpoint.GetOrgID(), uniqByNameResID, KindNotificationEndpoint, endpointKind(ruleEndpoint, ""))
+			if err != nil {
+				return err
+			}
 			object = ex.mObjects[endpointKey]
 		}
 		endpointObjectName := object.Name()
 
-		mapResource(rule.GetOrgID(), rule.GetID(), KindNotificationRule, ruleToObject(rule, endpointObjectName, r.Name))
+		return mapResource(rule.GetOrgID(), rule.GetID(), KindNotificationRule, ruleToObject(rule, endpointObjectName, r.Name))
 	case r.Kind.is(KindTask):
 		t, err := ex.taskSVC.FindTaskByID(ctx, r.ID)
 		if err != nil {
 			return err
 		}
-		mapResource(t.OrganizationID, t.ID, KindTask, taskToObject(*t, r.Name))
+		return mapResource(t.OrganizationID, t.ID, KindTask, taskToObject(*t, r.Name))
 	case r.Kind.is(KindTelegraf):
 		t, err := ex.teleSVC.FindTelegrafConfigByID(ctx, r.ID)
 		if err != nil {
 			return err
 		}
-		mapResource(t.OrgID, t.ID, KindTelegraf, telegrafToObject(*t, r.Name))
+		return mapResource(t.OrgID, t.ID, KindTelegraf, telegrafToObject(*t, r.Name))
 	case r.Kind.is(KindVariable):
 		v, err := ex.varSVC.FindVariableByID(ctx, r.ID)
 		if err != nil {
 			return err
 		}
-		mapResource(v.OrganizationID, uniqByNameResID, KindVariable, VariableToObject(*v, r.Name))
+		return mapResource(v.OrganizationID, uniqByNameResID, KindVariable, VariableToObject(*v, r.Name))
 	default:
 		return errors.New("unsupported kind provided: " + string(r.Kind))
 	}
-
-	return nil
 }
 
 func (ex *resourceExporter) resourceCloneAssociationsGen(ctx context.Context, labelNames ...string) (cloneAssociationsFn, error) {
@@ -339,7 +361,9 @@ func (ex *resourceExporter) resourceCloneAssociationsGen(ctx context.Context, la
 				fieldKind: KindLabel.String(),
 				fieldName: labelObject.Name(),
 			})
-			ex.mObjects[k] = labelObject
+			if err := ex.addObject(k, labelObject); err != nil {
+				return nil, false, err
+			}
 		}
 		return associations, false, nil
 	}
@@ -421,8 +445,8 @@ func bucketToObject(bkt influxdb.Bucket, name string) Object {
 
 	o := newObject(KindBucket, name)
 	assignNonZeroStrings(o.Spec, map[string]string{fieldDescription: bkt.Description})
-	if bkt.RetentionPeriod != 0 {
-		o.Spec[fieldBucketRetentionRules] = retentionRules{newRetentionRule(bkt.RetentionPeriod)}
+	if rules := bucketRetentionRules(bkt); len(rules) > 0 {
+		o.Spec[fieldBucketRetentionRules] = rules
 	}
 	return o
 }
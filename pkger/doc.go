@@ -33,6 +33,8 @@ If a validation error is encountered during the validation or parsing then
 the error returned will be of type *parseErr. The parseErr provides a rich
 set of validations failures. There can be numerous failures in a package
 and we did our best to inform the caller about them all in a single run.
+Each failure identifies the object and field path that failed and, when the
+package was parsed from YAML or JSON, the line and column of the field.
 
 If you want to see the effects of a package before applying it to the
 organization's influxdb platform, you have the flexibility to dry run the
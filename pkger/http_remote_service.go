@@ -78,6 +78,7 @@ func (s *HTTPRemoteService) CreatePkg(ctx context.Context, setters ...CreatePkgS
 				ByLabel:        org.LabelNames,
 				ByResourceKind: org.ResourceKinds,
 			},
+			IncludeBackingTasks: org.IncludeBackingTasks,
 		})
 	}
 
@@ -90,7 +91,7 @@ func (s *HTTPRemoteService) CreatePkg(ctx context.Context, setters ...CreatePkgS
 	err := s.Client.
 		PostJSON(reqBody, RoutePrefix).
 		Decode(func(resp *http.Response) error {
-			pkg, err := Parse(EncodingJSON, FromReader(resp.Body))
+			pkg, err := ParseReader(EncodingJSON, resp.Body)
 			newPkg = pkg
 			return err
 		}).
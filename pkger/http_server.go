@@ -148,6 +148,7 @@ type ReqCreateOrgIDOpt struct {
 		ByLabel        []string `json:"byLabel"`
 		ByResourceKind []Kind   `json:"byResourceKind"`
 	} `json:"resourceFilters"`
+	IncludeBackingTasks bool `json:"includeBackingTasks,omitempty"`
 }
 
 // ReqCreatePkg is a request body for the create pkg endpoint.
@@ -198,9 +199,10 @@ func (s *HTTPServer) createPkg(w http.ResponseWriter, r *http.Request) {
 			continue
 		}
 		opts = append(opts, CreateWithAllOrgResources(CreateByOrgIDOpt{
-			OrgID:         *orgID,
-			LabelNames:    orgIDStr.Filters.ByLabel,
-			ResourceKinds: orgIDStr.Filters.ByResourceKind,
+			OrgID:               *orgID,
+			LabelNames:          orgIDStr.Filters.ByLabel,
+			ResourceKinds:       orgIDStr.Filters.ByResourceKind,
+			IncludeBackingTasks: orgIDStr.IncludeBackingTasks,
 		}))
 	}
 
@@ -270,7 +272,15 @@ func (r ReqApplyPkg) Pkgs(encoding Encoding) (*Pkg, error) {
 		if rem.URL == "" {
 			continue
 		}
-		pkg, err := Parse(rem.Encoding(), FromHTTPRequest(rem.URL), ValidSkipParseError())
+		var (
+			pkg *Pkg
+			err error
+		)
+		if IsArchive(rem.URL) {
+			pkg, err = ParseArchive(FromHTTPRequest(rem.URL), ValidSkipParseError())
+		} else {
+			pkg, err = Parse(rem.Encoding(), FromHTTPRequest(rem.URL), ValidSkipParseError())
+		}
 		if err != nil {
 			return nil, &influxdb.Error{
 				Code: influxdb.EUnprocessableEntity,
@@ -284,7 +294,7 @@ func (r ReqApplyPkg) Pkgs(encoding Encoding) (*Pkg, error) {
 		if rawPkg == nil {
 			continue
 		}
-		pkg, err := Parse(encoding, FromReader(bytes.NewReader(rawPkg)), ValidSkipParseError())
+		pkg, err := ParseReader(encoding, bytes.NewReader(rawPkg), ValidSkipParseError())
 		if err != nil {
 			return nil, &influxdb.Error{
 				Code: influxdb.EUnprocessableEntity,
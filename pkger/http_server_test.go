@@ -135,6 +135,16 @@ func TestPkgerHTTPServer(t *testing.T) {
 						}},
 					},
 				},
+				{
+					name: "retrieves package archive from a URL",
+					reqBody: pkger.ReqApplyPkg{
+						DryRun: true,
+						OrgID:  influxdb.ID(9000).String(),
+						Remotes: []pkger.PkgRemote{{
+							URL: newPkgURL(t, filesvr.URL, "testdata/remote_bucket.tar.gz"),
+						}},
+					},
+				},
 				{
 					name:        "app jsonnet",
 					contentType: "application/x-jsonnet",
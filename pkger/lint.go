@@ -0,0 +1,121 @@
+package pkger
+
+import (
+	"fmt"
+)
+
+// LintRule identifies a convention a pkg resource is linted against.
+type LintRule string
+
+// Lint rules.
+const (
+	LintRuleRequireLabels       LintRule = "requireLabels"
+	LintRuleRequireDescriptions LintRule = "requireDescriptions"
+	LintRuleForbiddenName       LintRule = "forbiddenName"
+)
+
+// LintRules are the conventions a pkg is linted against.
+type LintRules struct {
+	// RequireLabels requires every resource, other than labels, to be
+	// associated with at least one label.
+	RequireLabels bool
+	// RequireDescriptions requires every resource to have a description.
+	RequireDescriptions bool
+	// ForbiddenNames are names no resource may have.
+	ForbiddenNames []string
+}
+
+// LintWarning is a violation of a lint rule by an individual resource.
+type LintWarning struct {
+	Kind Kind     `json:"kind"`
+	Name string   `json:"name"`
+	Rule LintRule `json:"rule"`
+	Msg  string   `json:"msg"`
+}
+
+// Lint walks the resources of the pkg and returns a warning for every violation
+// of the provided rules. Unlike Validate, a pkg with lint warnings is still valid
+// and may be applied. The warnings are ordered by kind, then by resource name.
+func (s *Service) Lint(pkg *Pkg, rules LintRules) []LintWarning {
+	forbidden := make(map[string]bool, len(rules.ForbiddenNames))
+	for _, name := range rules.ForbiddenNames {
+		forbidden[name] = true
+	}
+
+	var warnings []LintWarning
+	for _, r := range lintResources(pkg) {
+		if forbidden[r.name] {
+			warnings = append(warnings, LintWarning{
+				Kind: r.kind,
+				Name: r.name,
+				Rule: LintRuleForbiddenName,
+				Msg:  fmt.Sprintf("name %q is forbidden", r.name),
+			})
+		}
+		if rules.RequireDescriptions && r.description == "" {
+			warnings = append(warnings, LintWarning{
+				Kind: r.kind,
+				Name: r.name,
+				Rule: LintRuleRequireDescriptions,
+				Msg:  "a description is required",
+			})
+		}
+		if rules.RequireLabels && !r.kind.is(KindLabel) && r.numLabels == 0 {
+			warnings = append(warnings, LintWarning{
+				Kind: r.kind,
+				Name: r.name,
+				Rule: LintRuleRequireLabels,
+				Msg:  "at least 1 label association is required",
+			})
+		}
+	}
+	return warnings
+}
+
+type lintResource struct {
+	kind        Kind
+	name        string
+	description string
+	numLabels   int
+}
+
+func lintResources(pkg *Pkg) []lintResource {
+	var resources []lintResource
+	add := func(k Kind, name, description string, labels []*label) {
+		resources = append(resources, lintResource{
+			kind:        k,
+			name:        name,
+			description: description,
+			numLabels:   len(labels),
+		})
+	}
+
+	for _, b := range pkg.buckets() {
+		add(KindBucket, b.Name(), b.Description, b.Labels())
+	}
+	for _, c := range pkg.checks() {
+		add(KindCheck, c.Name(), c.description, c.Labels())
+	}
+	for _, d := range pkg.dashboards() {
+		add(KindDashboard, d.Name(), d.Description, d.Labels())
+	}
+	for _, l := range pkg.labels() {
+		add(KindLabel, l.Name(), l.Description, nil)
+	}
+	for _, e := range pkg.notificationEndpoints() {
+		add(KindNotificationEndpoint, e.Name(), e.description, e.Labels())
+	}
+	for _, r := range pkg.notificationRules() {
+		add(KindNotificationRule, r.Name(), r.description, r.Labels())
+	}
+	for _, t := range pkg.tasks() {
+		add(KindTask, t.Name(), t.description, t.Labels())
+	}
+	for _, t := range pkg.telegrafs() {
+		add(KindTelegraf, t.Name(), t.config.Description, t.Labels())
+	}
+	for _, v := range pkg.variables() {
+		add(KindVariable, v.Name(), v.Description, v.Labels())
+	}
+	return resources
+}
@@ -56,6 +56,18 @@ var kinds = map[Kind]bool{
 	KindVariable:                      true,
 }
 
+// SupportedKinds returns the kinds the service can export and apply, sorted by name.
+func SupportedKinds() []Kind {
+	out := make([]Kind, 0, len(kinds))
+	for k := range kinds {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i] < out[j]
+	})
+	return out
+}
+
 // Kind is a resource kind.
 type Kind string
 
@@ -110,6 +122,20 @@ func (k Kind) ResourceType() influxdb.ResourceType {
 	}
 }
 
+// baseKind returns the kind the resources of the kind are applied as. The check and
+// notification endpoint kinds are applied as KindCheck and KindNotificationEndpoint.
+func (k Kind) baseKind() Kind {
+	switch k {
+	case KindCheckDeadman, KindCheckThreshold:
+		return KindCheck
+	case KindNotificationEndpointHTTP,
+		KindNotificationEndpointPagerDuty,
+		KindNotificationEndpointSlack:
+		return KindNotificationEndpoint
+	}
+	return k
+}
+
 func (k Kind) is(comps ...Kind) bool {
 	for _, c := range comps {
 		if c == k {
@@ -174,6 +200,301 @@ func (d Diff) HasConflicts() bool {
 	return false
 }
 
+// DiffStat counts the resources of a diff by the change an apply makes to them.
+type DiffStat struct {
+	New       int `json:"new"`
+	Changed   int `json:"changed"`
+	Unchanged int `json:"unchanged"`
+	NotInPkg  int `json:"notInPkg"`
+}
+
+// DiffStats are the totals of a diff. Kinds holds the totals of each kind with
+// resources in the diff.
+type DiffStats struct {
+	Kinds         map[Kind]DiffStat `json:"kinds"`
+	LabelMappings DiffStat          `json:"labelMappings"`
+}
+
+// Stats counts the new, changed, and unchanged resources of each kind in the diff,
+// and the label mappings of stack resources that are not in the pkg. The existing state of dashboards, notification endpoints, and notification
+// rules is not compared, so an existing resource of these kinds is always counted
+// as changed.
+func (d Diff) Stats() DiffStats {
+	stats := DiffStats{Kinds: make(map[Kind]DiffStat)}
+	count := func(k Kind, isNew, changed bool) {
+		s := stats.Kinds[k]
+		switch {
+		case isNew:
+			s.New++
+		case changed:
+			s.Changed++
+		default:
+			s.Unchanged++
+		}
+		stats.Kinds[k] = s
+	}
+
+	for _, b := range d.Buckets {
+		count(KindBucket, b.IsNew(), b.hasConflict())
+	}
+	for _, c := range d.Checks {
+		count(KindCheck, c.IsNew(), len(c.ChangedDetails()) > 0)
+	}
+	for _, dash := range d.Dashboards {
+		count(KindDashboard, dash.IsNew(), true)
+	}
+	for _, l := range d.Labels {
+		count(KindLabel, l.IsNew(), l.hasConflict())
+	}
+	for _, e := range d.NotificationEndpoints {
+		count(KindNotificationEndpoint, e.IsNew(), true)
+	}
+	for _, r := range d.NotificationRules {
+		count(KindNotificationRule, r.IsNew(), true)
+	}
+	for _, t := range d.Tasks {
+		count(KindTask, t.IsNew(), t.Old != nil && *t.Old != t.New)
+	}
+	for _, t := range d.Telegrafs {
+		count(KindTelegraf, t.IsNew(), t.Old != nil && *t.Old != t.New)
+	}
+	for _, v := range d.Variables {
+		count(KindVariable, v.IsNew(), v.hasConflict())
+	}
+
+	for _, m := range d.LabelMappings {
+		switch {
+		case m.NotInPkg:
+			stats.LabelMappings.NotInPkg++
+		case m.IsNew:
+			stats.LabelMappings.New++
+		default:
+			stats.LabelMappings.Unchanged++
+		}
+	}
+
+	return stats
+}
+
+// scheduleErrs describes each notification rule in the diff whose schedule is invalid.
+func (d Diff) scheduleErrs() []string {
+	var errs []string
+	for _, r := range d.NotificationRules {
+		if r.ScheduleErr != "" {
+			errs = append(errs, fmt.Sprintf("notification rule %q: %s", r.Name, r.ScheduleErr))
+		}
+	}
+	return errs
+}
+
+// destructiveChanges describes each change in the diff that loses data or replaces
+// an existing resource. A bucket whose retention is shortened loses the data that
+// falls outside the new retention. A check, notification endpoint, or variable whose
+// type changes replaces the existing resource.
+func (d Diff) destructiveChanges() []string {
+	var changes []string
+	for _, b := range d.Buckets {
+		if b.Old == nil || !b.RetentionShrink {
+			continue
+		}
+		oldRP, newRP := b.Old.RetentionRules.RP(), b.New.RetentionRules.RP()
+		changes = append(changes, fmt.Sprintf("bucket %q retention shortened from %s to %s", b.Name, rpString(oldRP), rpString(newRP)))
+	}
+
+	for _, c := range d.Checks {
+		if c.Old == nil || c.Old.Check == nil || c.New.Check == nil {
+			continue
+		}
+		if oldType, newType := c.Old.Type(), c.New.Type(); oldType != newType {
+			changes = append(changes, fmt.Sprintf("check %q replaced from type %q to %q", c.Name, oldType, newType))
+		}
+	}
+
+	for _, e := range d.NotificationEndpoints {
+		if e.Old == nil || e.Old.NotificationEndpoint == nil || e.New.NotificationEndpoint == nil {
+			continue
+		}
+		if oldType, newType := e.Old.Type(), e.New.Type(); oldType != newType {
+			changes = append(changes, fmt.Sprintf("notification endpoint %q replaced from type %q to %q", e.Name, oldType, newType))
+		}
+	}
+
+	for _, v := range d.Variables {
+		if v.Old == nil || v.Old.Args == nil || v.New.Args == nil {
+			continue
+		}
+		if oldType, newType := v.Old.Args.Type, v.New.Args.Type; oldType != newType {
+			changes = append(changes, fmt.Sprintf("variable %q replaced from type %q to %q", v.Name, oldType, newType))
+		}
+	}
+
+	return changes
+}
+
+func rpString(rp time.Duration) string {
+	if rp == 0 {
+		return "infinite"
+	}
+	return rp.String()
+}
+
+// DiffPackages computes the diff of moving from the old pkg to the new pkg. Resources
+// are matched by their kind and pkg name. A resource in the new pkg that is present
+// in the old pkg is treated as existing, with the old pkg's values populating the Old
+// values of the diff. The charts of a dashboard and the endpoint of a notification
+// rule are provided from the old pkg as well.
+// Neither pkg is required to have been dry run, no platform access is required.
+func DiffPackages(oldPkg, newPkg *Pkg) Diff {
+	diff := Diff{
+		Buckets:               make([]DiffBucket, 0, len(newPkg.mBuckets)),
+		Checks:                make([]DiffCheck, 0, len(newPkg.mChecks)),
+		Dashboards:            make([]DiffDashboard, 0, len(newPkg.mDashboards)),
+		Labels:                make([]DiffLabel, 0, len(newPkg.mLabels)),
+		NotificationEndpoints: make([]DiffNotificationEndpoint, 0, len(newPkg.mNotificationEndpoints)),
+		NotificationRules:     make([]DiffNotificationRule, 0, len(newPkg.mNotificationRules)),
+		Tasks:                 make([]DiffTask, 0, len(newPkg.mTasks)),
+		Telegrafs:             make([]DiffTelegraf, 0, len(newPkg.mTelegrafs)),
+		Variables:             make([]DiffVariable, 0, len(newPkg.mVariables)),
+	}
+
+	for _, b := range newPkg.buckets() {
+		d := newDiffBucket(b, nil)
+		if ob, ok := oldPkg.mBuckets[b.PkgName()]; ok {
+			old := newDiffBucket(ob, nil).New
+			d.Old = &old
+			d.RetentionShrink = retentionShrinks(old.RetentionRules.RP(), d.New.RetentionRules.RP())
+		}
+		diff.Buckets = append(diff.Buckets, d)
+	}
+
+	for _, c := range newPkg.checks() {
+		d := newDiffCheck(c, nil)
+		if oc, ok := oldPkg.mChecks[c.PkgName()]; ok {
+			oldSum := oc.summarize()
+			d.Old = &DiffCheckValues{Check: oldSum.Check}
+			oldDetails := newDiffCheckDetails(oldSum.Check, oldSum.Status)
+			d.OldDetails = &oldDetails
+		}
+		diff.Checks = append(diff.Checks, d)
+	}
+
+	for _, dash := range newPkg.dashboards() {
+		d := newDiffDashboard(dash)
+		if od, ok := oldPkg.mDashboards[dash.PkgName()]; ok {
+			old := newDiffDashboard(od)
+			d.Old = &DiffDashboardValues{Desc: old.Desc, Charts: old.Charts}
+		}
+		diff.Dashboards = append(diff.Dashboards, d)
+	}
+
+	for _, l := range newPkg.labels() {
+		d := newDiffLabel(l, nil)
+		if ol, ok := oldPkg.mLabels[l.PkgName()]; ok {
+			old := newDiffLabel(ol, nil).New
+			d.Old = &old
+		}
+		diff.Labels = append(diff.Labels, d)
+	}
+
+	for _, e := range newPkg.notificationEndpoints() {
+		d := newDiffNotificationEndpoint(e, nil)
+		if oe, ok := oldPkg.mNotificationEndpoints[e.PkgName()]; ok {
+			d.Old = &DiffNotificationEndpointValues{
+				NotificationEndpoint: oe.summarize().NotificationEndpoint,
+			}
+		}
+		diff.NotificationEndpoints = append(diff.NotificationEndpoints, d)
+	}
+
+	for _, r := range newPkg.notificationRules() {
+		var iEndpoint influxdb.NotificationEndpoint
+		if e, ok := newPkg.mNotificationEndpoints[r.endpointName.String()]; ok {
+			iEndpoint = e.summarize().NotificationEndpoint
+		}
+		d := newDiffNotificationRule(r, iEndpoint)
+		if or, ok := oldPkg.mNotificationRules[r.PkgName()]; ok {
+			old := newDiffNotificationRule(or, nil)
+			d.Old = &DiffNotificationRuleValues{
+				Description:     old.Description,
+				Every:           old.Every,
+				Offset:          old.Offset,
+				MessageTemplate: old.MessageTemplate,
+				Status:          old.Status,
+			}
+			d.OldEndpointName = or.endpointName.String()
+		}
+		diff.NotificationRules = append(diff.NotificationRules, d)
+	}
+
+	for _, t := range newPkg.tasks() {
+		d := newDiffTask(t, nil)
+		if ot, ok := oldPkg.mTasks[t.PkgName()]; ok {
+			old := newDiffTask(ot, nil).New
+			d.Old = &old
+		}
+		diff.Tasks = append(diff.Tasks, d)
+	}
+
+	for _, t := range newPkg.telegrafs() {
+		d := newDiffTelegraf(t, nil)
+		if ot, ok := oldPkg.mTelegrafs[t.PkgName()]; ok {
+			old := newDiffTelegraf(ot, nil).New
+			d.Old = &old
+		}
+		diff.Telegrafs = append(diff.Telegrafs, d)
+	}
+
+	for _, v := range newPkg.variables() {
+		d := newDiffVariable(v, nil)
+		if ov, ok := oldPkg.mVariables[v.PkgName()]; ok {
+			old := newDiffVariable(ov, nil).New
+			d.Old = &old
+		}
+		diff.Variables = append(diff.Variables, d)
+	}
+
+	diff.LabelMappings = diffPkgLabelMappings(oldPkg, newPkg)
+
+	return diff
+}
+
+func diffPkgLabelMappings(oldPkg, newPkg *Pkg) []DiffLabelMapping {
+	type mappingKey struct {
+		resType   influxdb.ResourceType
+		resName   string
+		labelName string
+	}
+
+	mOldMappings := make(map[mappingKey]bool)
+	for _, mapper := range pkgLabelMappers(oldPkg) {
+		for i := 0; i < mapper.Len(); i++ {
+			la := mapper.Association(i)
+			for _, l := range la.Labels() {
+				mOldMappings[mappingKey{resType: la.ResourceType(), resName: la.Name(), labelName: l.Name()}] = true
+			}
+		}
+	}
+
+	diffs := make([]DiffLabelMapping, 0)
+	for _, mapper := range pkgLabelMappers(newPkg) {
+		for i := 0; i < mapper.Len(); i++ {
+			la := mapper.Association(i)
+			for _, l := range la.Labels() {
+				k := mappingKey{resType: la.ResourceType(), resName: la.Name(), labelName: l.Name()}
+				diffs = append(diffs, DiffLabelMapping{
+					IsNew:     !mOldMappings[k],
+					ResType:   la.ResourceType(),
+					ResName:   la.Name(),
+					LabelName: l.Name(),
+				})
+			}
+		}
+	}
+	sortDiffLabelMappings(diffs)
+
+	return diffs
+}
+
 // DiffBucketValues are the varying values for a bucket.
 type DiffBucketValues struct {
 	Description    string         `json:"description"`
@@ -186,6 +507,10 @@ type DiffBucket struct {
 	Name string            `json:"name"`
 	New  DiffBucketValues  `json:"new"`
 	Old  *DiffBucketValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// RetentionShrink indicates the retention of the existing bucket is shortened,
+	// the data outside of the new retention will be dropped.
+	RetentionShrink bool `json:"retentionShrink,omitempty"`
 }
 
 func newDiffBucket(b *bucket, i *influxdb.Bucket) DiffBucket {
@@ -199,18 +524,23 @@ func newDiffBucket(b *bucket, i *influxdb.Bucket) DiffBucket {
 	if i != nil {
 		diff.ID = SafeID(i.ID)
 		diff.Old = &DiffBucketValues{
-			Description: i.Description,
-		}
-		if i.RetentionPeriod > 0 {
-			diff.Old.RetentionRules = retentionRules{newRetentionRule(i.RetentionPeriod)}
+			Description:    i.Description,
+			RetentionRules: bucketRetentionRules(*i),
 		}
+		diff.RetentionShrink = retentionShrinks(diff.Old.RetentionRules.RP(), diff.New.RetentionRules.RP())
 	}
 	return diff
 }
 
+// retentionShrinks indicates whether moving from the old to the new retention drops
+// data. An infinite retention, provided as 0, never shrinks.
+func retentionShrinks(oldRP, newRP time.Duration) bool {
+	return newRP > 0 && (oldRP == 0 || newRP < oldRP)
+}
+
 // IsNew indicates whether a pkg bucket is going to be new to the platform.
 func (d DiffBucket) IsNew() bool {
-	return d.ID == SafeID(0)
+	return d.Old == nil
 }
 
 func (d DiffBucket) hasConflict() bool {
@@ -222,32 +552,118 @@ type DiffCheckValues struct {
 	influxdb.Check
 }
 
+// MarshalJSON encodes the check values as the check. The MarshalJSON promoted
+// from the embedded check panics when the check is nil, a nil check is encoded
+// as null instead.
+func (d DiffCheckValues) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Check)
+}
+
 // UnmarshalJSON decodes the check values.
 func (d *DiffCheckValues) UnmarshalJSON(b []byte) (err error) {
 	d.Check, err = icheck.UnmarshalJSON(b)
 	return
 }
 
+// DiffCheckDetails are the status, query, and thresholds of a check, provided
+// in a structured form to outline what an update to a check modifies.
+type DiffCheckDetails struct {
+	Status     influxdb.Status      `json:"status"`
+	Query      string               `json:"query"`
+	Thresholds []DiffCheckThreshold `json:"thresholds"`
+}
+
+// DiffCheckThreshold is an individual threshold of a check. A deadman check is
+// provided as a single threshold of type deadman.
+type DiffCheckThreshold struct {
+	Type      string  `json:"type"`
+	Level     string  `json:"level"`
+	AllValues bool    `json:"allValues,omitempty"`
+	Value     float64 `json:"value,omitempty"`
+	Min       float64 `json:"min,omitempty"`
+	Max       float64 `json:"max,omitempty"`
+	Within    bool    `json:"within,omitempty"`
+	StaleTime string  `json:"staleTime,omitempty"`
+	TimeSince string  `json:"timeSince,omitempty"`
+}
+
+func newDiffCheckDetails(c influxdb.Check, status influxdb.Status) DiffCheckDetails {
+	details := DiffCheckDetails{
+		Status:     status,
+		Thresholds: []DiffCheckThreshold{},
+	}
+
+	switch c := c.(type) {
+	case *icheck.Deadman:
+		details.Query = c.Query.Text
+		th := DiffCheckThreshold{
+			Type:  icheck.Deadman{}.Type(),
+			Level: c.Level.String(),
+		}
+		if c.StaleTime != nil {
+			th.StaleTime = c.StaleTime.TimeDuration().String()
+		}
+		if c.TimeSince != nil {
+			th.TimeSince = c.TimeSince.TimeDuration().String()
+		}
+		details.Thresholds = append(details.Thresholds, th)
+	case *icheck.Threshold:
+		details.Query = c.Query.Text
+		for _, tc := range c.Thresholds {
+			th := DiffCheckThreshold{
+				Type:  tc.Type(),
+				Level: tc.GetLevel().String(),
+			}
+			switch tc := tc.(type) {
+			case icheck.Greater:
+				th.AllValues, th.Value = tc.AllValues, tc.Value
+			case *icheck.Greater:
+				th.AllValues, th.Value = tc.AllValues, tc.Value
+			case icheck.Lesser:
+				th.AllValues, th.Value = tc.AllValues, tc.Value
+			case *icheck.Lesser:
+				th.AllValues, th.Value = tc.AllValues, tc.Value
+			case icheck.Range:
+				th.AllValues, th.Min, th.Max, th.Within = tc.AllValues, tc.Min, tc.Max, tc.Within
+			case *icheck.Range:
+				th.AllValues, th.Min, th.Max, th.Within = tc.AllValues, tc.Min, tc.Max, tc.Within
+			}
+			details.Thresholds = append(details.Thresholds, th)
+		}
+	}
+
+	return details
+}
+
 // DiffCheck is a diff of an individual check.
 type DiffCheck struct {
 	ID   SafeID           `json:"id"`
 	Name string           `json:"name"`
 	New  DiffCheckValues  `json:"new"`
 	Old  *DiffCheckValues `json:"old"`
+
+	// NewDetails and OldDetails outline the status, query, and thresholds
+	// of the check in the pkg and of the existing check respectively.
+	NewDetails DiffCheckDetails  `json:"newDetails"`
+	OldDetails *DiffCheckDetails `json:"oldDetails,omitempty"`
 }
 
 func newDiffCheck(c *check, iCheck influxdb.Check) DiffCheck {
+	sum := c.summarize()
 	diff := DiffCheck{
 		Name: c.Name(),
 		New: DiffCheckValues{
-			Check: c.summarize().Check,
+			Check: sum.Check,
 		},
+		NewDetails: newDiffCheckDetails(sum.Check, sum.Status),
 	}
 	if iCheck != nil {
 		diff.ID = SafeID(iCheck.GetID())
 		diff.Old = &DiffCheckValues{
 			Check: iCheck,
 		}
+		oldDetails := newDiffCheckDetails(iCheck, "")
+		diff.OldDetails = &oldDetails
 	}
 	return diff
 }
@@ -257,11 +673,43 @@ func (d DiffCheck) IsNew() bool {
 	return d.Old == nil
 }
 
-// DiffDashboard is a diff of an individual dashboard. This resource is always new.
+// ChangedDetails provides the details, one of status, query, or thresholds,
+// that differ between the existing check and the check in the pkg. A new
+// check has no changed details.
+func (d DiffCheck) ChangedDetails() []string {
+	if d.OldDetails == nil {
+		return nil
+	}
+
+	var changed []string
+	if d.OldDetails.Status != d.NewDetails.Status {
+		changed = append(changed, "status")
+	}
+	if d.OldDetails.Query != d.NewDetails.Query {
+		changed = append(changed, "query")
+	}
+	if !reflect.DeepEqual(d.OldDetails.Thresholds, d.NewDetails.Thresholds) {
+		changed = append(changed, "thresholds")
+	}
+	return changed
+}
+
+// DiffDashboard is a diff of an individual dashboard. A dashboard is only
+// matched to an existing dashboard when applied as part of a stack.
 type DiffDashboard struct {
+	ID     SafeID      `json:"id"`
 	Name   string      `json:"name"`
 	Desc   string      `json:"description"`
 	Charts []DiffChart `json:"charts"`
+
+	// Old is the existing state of the dashboard, nil when the dashboard is new.
+	Old *DiffDashboardValues `json:"old,omitempty"`
+}
+
+// DiffDashboardValues are the varying values for a dashboard.
+type DiffDashboardValues struct {
+	Desc   string      `json:"description"`
+	Charts []DiffChart `json:"charts"`
 }
 
 func newDiffDashboard(d *dashboard) DiffDashboard {
@@ -269,6 +717,21 @@ func newDiffDashboard(d *dashboard) DiffDashboard {
 		Name: d.Name(),
 		Desc: d.Description,
 	}
+	if d.existing != nil {
+		diff.ID = SafeID(d.existing.ID)
+		diff.Old = &DiffDashboardValues{Desc: d.existing.Description}
+		for _, cell := range d.existing.Cells {
+			if cell == nil {
+				continue
+			}
+			ch := convertCellView(*cell)
+			diff.Old.Charts = append(diff.Old.Charts, DiffChart{
+				Properties: ch.properties(),
+				Height:     ch.Height,
+				Width:      ch.Width,
+			})
+		}
+	}
 
 	for _, c := range d.Charts {
 		diff.Charts = append(diff.Charts, DiffChart{
@@ -281,6 +744,11 @@ func newDiffDashboard(d *dashboard) DiffDashboard {
 	return diff
 }
 
+// IsNew indicates whether a pkg dashboard is going to be new to the platform.
+func (d DiffDashboard) IsNew() bool {
+	return d.Old == nil
+}
+
 // DiffChart is a diff of oa chart. Since all charts are new right now.
 // the SummaryChart is reused here.
 type DiffChart SummaryChart
@@ -301,7 +769,7 @@ type DiffLabel struct {
 
 // IsNew indicates whether a pkg label is going to be new to the platform.
 func (d DiffLabel) IsNew() bool {
-	return d.ID == SafeID(0)
+	return d.Old == nil
 }
 
 func (d DiffLabel) hasConflict() bool {
@@ -331,6 +799,10 @@ func newDiffLabel(l *label, i *influxdb.Label) DiffLabel {
 // A label can have many mappings to other resources.
 type DiffLabelMapping struct {
 	IsNew bool `json:"isNew"`
+	// NotInPkg is set for a mapping of a stack resource that exists on the
+	// platform, but is no longer in the pkg. The apply leaves the mapping in
+	// place.
+	NotInPkg bool `json:"notInPkg"`
 
 	ResType influxdb.ResourceType `json:"resourceType"`
 	ResID   SafeID                `json:"resourceID"`
@@ -345,6 +817,13 @@ type DiffNotificationEndpointValues struct {
 	influxdb.NotificationEndpoint
 }
 
+// MarshalJSON encodes the notification endpoint. The MarshalJSON promoted from
+// the embedded endpoint panics when the endpoint is nil, a nil endpoint is
+// encoded as null instead.
+func (d DiffNotificationEndpointValues) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.NotificationEndpoint)
+}
+
 // UnmarshalJSON decodes the notification endpoint. This is necessary unfortunately.
 func (d *DiffNotificationEndpointValues) UnmarshalJSON(b []byte) (err error) {
 	d.NotificationEndpoint, err = endpoint.UnmarshalJSON(b)
@@ -381,8 +860,10 @@ func (d DiffNotificationEndpoint) IsNew() bool {
 	return d.Old == nil
 }
 
-// DiffNotificationRule is a diff of an individual notification rule. This resource is always new.
+// DiffNotificationRule is a diff of an individual notification rule. A rule is matched
+// to an existing rule by its name within the org.
 type DiffNotificationRule struct {
+	ID          SafeID `json:"id"`
 	Name        string `json:"name"`
 	Description string `json:"description"`
 
@@ -391,16 +872,64 @@ type DiffNotificationRule struct {
 	EndpointName string `json:"endpointName"`
 	EndpointType string `json:"endpointType"`
 
+	// These 2 fields represent the relationship of the existing rule to its
+	// endpoint. They are empty when the rule is new.
+	OldEndpointID   SafeID `json:"oldEndpointID,omitempty"`
+	OldEndpointName string `json:"oldEndpointName,omitempty"`
+
 	Every           string              `json:"every"`
 	Offset          string              `json:"offset"`
 	MessageTemplate string              `json:"messageTemplate"`
 	Status          influxdb.Status     `json:"status"`
 	StatusRules     []SummaryStatusRule `json:"statusRules"`
 	TagRules        []SummaryTagRule    `json:"tagRules"`
+
+	// ScheduleErr describes why the every and offset of the rule can not be
+	// scheduled. It is empty when the schedule is valid.
+	ScheduleErr string `json:"scheduleErr,omitempty"`
+
+	// Old is the existing state of the rule, nil when the rule is new.
+	Old *DiffNotificationRuleValues `json:"old,omitempty"`
+}
+
+// DiffNotificationRuleValues are the varying values for a notification rule.
+type DiffNotificationRuleValues struct {
+	Description     string          `json:"description"`
+	Every           string          `json:"every"`
+	Offset          string          `json:"offset"`
+	MessageTemplate string          `json:"messageTemplate"`
+	Status          influxdb.Status `json:"status"`
+}
+
+func newDiffNotificationRuleValues(iRule influxdb.NotificationRule, status influxdb.Status) DiffNotificationRuleValues {
+	values := DiffNotificationRuleValues{
+		Description: iRule.GetDescription(),
+		Status:      status,
+	}
+	setBase := func(base rule.Base) {
+		if base.Every != nil {
+			values.Every = base.Every.TimeDuration().String()
+		}
+		if base.Offset != nil {
+			values.Offset = base.Offset.TimeDuration().String()
+		}
+	}
+	switch t := iRule.(type) {
+	case *rule.HTTP:
+		setBase(t.Base)
+	case *rule.PagerDuty:
+		setBase(t.Base)
+		values.MessageTemplate = t.MessageTemplate
+	case *rule.Slack:
+		setBase(t.Base)
+		values.MessageTemplate = t.MessageTemplate
+	}
+	return values
 }
 
 func newDiffNotificationRule(r *notificationRule, iEndpoint influxdb.NotificationEndpoint) DiffNotificationRule {
 	sum := DiffNotificationRule{
+		ID:              SafeID(r.ID()),
 		Name:            r.Name(),
 		Description:     r.description,
 		EndpointName:    r.endpointName.String(),
@@ -415,13 +944,36 @@ func newDiffNotificationRule(r *notificationRule, iEndpoint influxdb.Notificatio
 		sum.EndpointID = SafeID(iEndpoint.GetID())
 		sum.EndpointType = iEndpoint.Type()
 	}
+	if r.existing != nil {
+		old := newDiffNotificationRuleValues(r.existing, r.existingStatus)
+		sum.Old = &old
+	}
+	if err := r.scheduleErr(); err != nil {
+		sum.ScheduleErr = err.Error()
+	}
 
 	return sum
 }
 
-// DiffTask is a diff of an individual task. This resource is always new.
-type DiffTask struct {
-	Name        string          `json:"name"`
+// IsNew indicates whether a pkg notification rule is going to be new to the platform.
+func (d DiffNotificationRule) IsNew() bool {
+	return d.Old == nil
+}
+
+// EndpointChanged indicates whether an existing rule is going to be associated
+// with a different endpoint than the one it is associated with now.
+func (d DiffNotificationRule) EndpointChanged() bool {
+	if d.OldEndpointID == 0 && d.OldEndpointName == "" {
+		return false
+	}
+	if d.OldEndpointName != d.EndpointName {
+		return true
+	}
+	return d.OldEndpointID != 0 && d.EndpointID != 0 && d.OldEndpointID != d.EndpointID
+}
+
+// DiffTaskValues are the varying values for a task.
+type DiffTaskValues struct {
 	Cron        string          `json:"cron"`
 	Description string          `json:"description"`
 	Every       string          `json:"every"`
@@ -430,27 +982,81 @@ type DiffTask struct {
 	Status      influxdb.Status `json:"status"`
 }
 
-func newDiffTask(t *task) DiffTask {
-	return DiffTask{
-		Name:        t.Name(),
-		Cron:        t.cron,
-		Description: t.description,
-		Every:       durToStr(t.every),
-		Offset:      durToStr(t.offset),
-		Query:       t.query,
-		Status:      t.Status(),
+// DiffTask is a diff of an individual task.
+type DiffTask struct {
+	ID   SafeID          `json:"id"`
+	Name string          `json:"name"`
+	New  DiffTaskValues  `json:"new"`
+	Old  *DiffTaskValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+}
+
+func newDiffTask(t *task, iTask *influxdb.Task) DiffTask {
+	diff := DiffTask{
+		Name: t.Name(),
+		New: DiffTaskValues{
+			Cron:        t.cron,
+			Description: t.description,
+			Every:       durToStr(t.every),
+			Offset:      durToStr(t.offset),
+			Query:       t.query,
+			Status:      t.Status(),
+		},
+	}
+	if iTask != nil {
+		diff.ID = SafeID(iTask.ID)
+		diff.Old = &DiffTaskValues{
+			Cron:        iTask.Cron,
+			Description: iTask.Description,
+			Every:       iTask.Every,
+			Offset:      durToStr(iTask.Offset),
+			Query:       iTask.Flux,
+			Status:      influxdb.Status(iTask.Status),
+		}
 	}
+	return diff
 }
 
-// DiffTelegraf is a diff of an individual telegraf. This resource is always new.
+// IsNew indicates whether a pkg task is going to be new to the platform.
+func (d DiffTask) IsNew() bool {
+	return d.Old == nil
+}
+
+// DiffTelegrafValues are the varying values for a telegraf config. The Config
+// is the TOML body of the telegraf config.
+type DiffTelegrafValues struct {
+	Description string `json:"description"`
+	Config      string `json:"config"`
+}
+
+// DiffTelegraf is a diff of an individual telegraf config.
 type DiffTelegraf struct {
-	influxdb.TelegrafConfig
+	ID   SafeID              `json:"id"`
+	Name string              `json:"name"`
+	New  DiffTelegrafValues  `json:"new"`
+	Old  *DiffTelegrafValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
 }
 
-func newDiffTelegraf(t *telegraf) DiffTelegraf {
-	return DiffTelegraf{
-		TelegrafConfig: t.config,
+func newDiffTelegraf(t *telegraf, iTele *influxdb.TelegrafConfig) DiffTelegraf {
+	diff := DiffTelegraf{
+		Name: t.Name(),
+		New: DiffTelegrafValues{
+			Description: t.config.Description,
+			Config:      t.config.Config,
+		},
 	}
+	if iTele != nil {
+		diff.ID = SafeID(iTele.ID)
+		diff.Old = &DiffTelegrafValues{
+			Description: iTele.Description,
+			Config:      iTele.Config,
+		}
+	}
+	return diff
+}
+
+// IsNew indicates whether a pkg telegraf config is going to be new to the platform.
+func (d DiffTelegraf) IsNew() bool {
+	return d.Old == nil
 }
 
 // DiffVariableValues are the varying values for a variable.
@@ -465,6 +1071,12 @@ type DiffVariable struct {
 	Name string              `json:"name"`
 	New  DiffVariableValues  `json:"new"`
 	Old  *DiffVariableValues `json:"old,omitempty"` // using omitempty here to signal there was no prev state with a nil
+
+	// NameCollision is set when a new variable's name matches an existing
+	// variable's name when compared case insensitively. It is not reported
+	// when the variable service finds variables by name, as the org's
+	// variables are then only scanned for case insensitive matching.
+	NameCollision bool `json:"nameCollision,omitempty"`
 }
 
 func newDiffVariable(v *variable, iv *influxdb.Variable) DiffVariable {
@@ -488,7 +1100,7 @@ func newDiffVariable(v *variable, iv *influxdb.Variable) DiffVariable {
 
 // IsNew indicates whether a pkg variable is going to be new to the platform.
 func (d DiffVariable) IsNew() bool {
-	return d.ID == SafeID(0)
+	return d.Old == nil
 }
 
 func (d DiffVariable) hasConflict() bool {
@@ -497,6 +1109,13 @@ func (d DiffVariable) hasConflict() bool {
 
 // Summary is a definition of all the resources that have or
 // will be created from a pkg.
+//
+// MissingEnvs are the env refs that remain unresolved once the env refs
+// provided to a dry run or apply have been substituted. DefaultedEnvs are
+// the env refs without a provided value that resolve to the default declared
+// for them in the pkg. SkippedCounts are the number of resources of each kind
+// an apply did not write, as they already matched the platform or were
+// skipped by the conflict strategy of the apply.
 type Summary struct {
 	Buckets               []SummaryBucket               `json:"buckets"`
 	Checks                []SummaryCheck                `json:"checks"`
@@ -506,12 +1125,198 @@ type Summary struct {
 	Labels                []SummaryLabel                `json:"labels"`
 	LabelMappings         []SummaryLabelMapping         `json:"labelMappings"`
 	MissingEnvs           []string                      `json:"missingEnvRefs"`
+	DefaultedEnvs         []string                      `json:"defaultedEnvRefs"`
 	MissingSecrets        []string                      `json:"missingSecrets"`
+	SkippedCounts         map[Kind]int                  `json:"skippedCounts,omitempty"`
 	Tasks                 []SummaryTask                 `json:"summaryTask"`
 	TelegrafConfigs       []SummaryTelegraf             `json:"telegrafConfigs"`
 	Variables             []SummaryVariable             `json:"variables"`
 }
 
+// skippedCounts counts the resources of each kind with an action indicating the
+// apply did not write them.
+func (s Summary) skippedCounts() map[Kind]int {
+	var counts map[Kind]int
+	count := func(k Kind, action ApplyAction) {
+		if action != ApplyActionSkipped && action != ApplyActionUnchanged {
+			return
+		}
+		if counts == nil {
+			counts = make(map[Kind]int)
+		}
+		counts[k]++
+	}
+
+	for _, b := range s.Buckets {
+		count(KindBucket, b.Action)
+	}
+	for _, c := range s.Checks {
+		count(KindCheck, c.Action)
+	}
+	for _, d := range s.Dashboards {
+		count(KindDashboard, d.Action)
+	}
+	for _, l := range s.Labels {
+		count(KindLabel, l.Action)
+	}
+	for _, e := range s.NotificationEndpoints {
+		count(KindNotificationEndpoint, e.Action)
+	}
+	for _, r := range s.NotificationRules {
+		count(KindNotificationRule, r.Action)
+	}
+	for _, t := range s.Tasks {
+		count(KindTask, t.Action)
+	}
+	for _, t := range s.TelegrafConfigs {
+		count(KindTelegraf, t.Action)
+	}
+	for _, v := range s.Variables {
+		count(KindVariable, v.Action)
+	}
+	return counts
+}
+
+// SummaryDelta describes the resources that were added, removed, or changed
+// between two summaries. Resources are identified by their kind and name.
+type SummaryDelta struct {
+	Added   []SummaryDeltaResource `json:"added"`
+	Removed []SummaryDeltaResource `json:"removed"`
+	Changed []SummaryDeltaResource `json:"changed"`
+}
+
+// SummaryDeltaResource identifies a resource reported in a SummaryDelta.
+type SummaryDeltaResource struct {
+	Kind Kind   `json:"kind"`
+	Name string `json:"name"`
+}
+
+// DiffSummaries reports the resources added, removed, or changed between the
+// before and after summaries, such as those returned from two consecutive
+// applies. A resource is changed when any of its summarized fields, including
+// its label associations, differ. The action an apply took on a resource is not
+// compared. Each list is ordered by kind, then by name.
+func DiffSummaries(before, after Summary) SummaryDelta {
+	beforeResources, afterResources := summaryResources(before), summaryResources(after)
+
+	delta := SummaryDelta{
+		Added:   []SummaryDeltaResource{},
+		Removed: []SummaryDeltaResource{},
+		Changed: []SummaryDeltaResource{},
+	}
+	for id, afterRes := range afterResources {
+		beforeRes, ok := beforeResources[id]
+		if !ok {
+			delta.Added = append(delta.Added, id)
+			continue
+		}
+		if !reflect.DeepEqual(beforeRes, afterRes) {
+			delta.Changed = append(delta.Changed, id)
+		}
+	}
+	for id := range beforeResources {
+		if _, ok := afterResources[id]; !ok {
+			delta.Removed = append(delta.Removed, id)
+		}
+	}
+
+	for _, resources := range [][]SummaryDeltaResource{delta.Added, delta.Removed, delta.Changed} {
+		sort.Slice(resources, func(i, j int) bool {
+			if resources[i].Kind != resources[j].Kind {
+				return resources[i].Kind < resources[j].Kind
+			}
+			return resources[i].Name < resources[j].Name
+		})
+	}
+
+	return delta
+}
+
+// summaryResources indexes the resources of the summary by kind and name. The
+// action an apply took on a resource is cleared, as consecutive applies of the
+// same pkg report the same resource as created and then as unchanged.
+func summaryResources(sum Summary) map[SummaryDeltaResource]interface{} {
+	m := make(map[SummaryDeltaResource]interface{})
+	for _, b := range sum.Buckets {
+		b.Action, b.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(b.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindBucket, Name: b.Name}] = b
+	}
+	for _, c := range sum.Checks {
+		c.Action, c.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(c.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindCheck, Name: c.Check.GetName()}] = c
+	}
+	for _, d := range sum.Dashboards {
+		d.Action, d.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(d.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindDashboard, Name: d.Name}] = d
+	}
+	for _, l := range sum.Labels {
+		l.Action = ApplyActionUnknown
+		m[SummaryDeltaResource{Kind: KindLabel, Name: l.Name}] = l
+	}
+	for _, e := range sum.NotificationEndpoints {
+		e.Action, e.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(e.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindNotificationEndpoint, Name: e.NotificationEndpoint.GetName()}] = e
+	}
+	for _, r := range sum.NotificationRules {
+		r.Action, r.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(r.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindNotificationRule, Name: r.Name}] = r
+	}
+	for _, t := range sum.Tasks {
+		t.Action, t.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(t.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindTask, Name: t.Name}] = t
+	}
+	for _, t := range sum.TelegrafConfigs {
+		t.Action, t.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(t.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindTelegraf, Name: t.TelegrafConfig.Name}] = t
+	}
+	for _, v := range sum.Variables {
+		v.Action, v.LabelAssociations = ApplyActionUnknown, summaryLabelsWithoutAction(v.LabelAssociations)
+		m[SummaryDeltaResource{Kind: KindVariable, Name: v.Name}] = v
+	}
+	return m
+}
+
+// summaryLabelsWithoutAction copies the labels with their action cleared,
+// leaving the labels of the summary untouched.
+func summaryLabelsWithoutAction(labels []SummaryLabel) []SummaryLabel {
+	if labels == nil {
+		return nil
+	}
+	out := make([]SummaryLabel, len(labels))
+	for i, l := range labels {
+		l.Action = ApplyActionUnknown
+		out[i] = l
+	}
+	return out
+}
+
+// ApplyAction describes the action an apply took on a resource.
+type ApplyAction string
+
+// available apply actions. A summary that is not the result of an apply, such
+// as the summary of a dry run, has no action set.
+const (
+	ApplyActionUnknown ApplyAction = ""
+	// ApplyActionCreated indicates the resource did not exist and was created.
+	ApplyActionCreated ApplyAction = "created"
+	// ApplyActionUpdated indicates an existing resource was updated.
+	ApplyActionUpdated ApplyAction = "updated"
+	// ApplyActionSkipped indicates the apply left an existing resource as is,
+	// regardless of whether it differs from the pkg.
+	ApplyActionSkipped ApplyAction = "skipped"
+	// ApplyActionUnchanged indicates an existing resource already matched the
+	// pkg, so it was not written.
+	ApplyActionUnchanged ApplyAction = "unchanged"
+)
+
+// newApplyAction provides the action of a resource that was written by an apply.
+func newApplyAction(exists bool) ApplyAction {
+	if exists {
+		return ApplyActionUpdated
+	}
+	return ApplyActionCreated
+}
+
 // SummaryBucket provides a summary of a pkg bucket.
 type SummaryBucket struct {
 	ID          SafeID `json:"id,omitempty"`
@@ -521,6 +1326,7 @@ type SummaryBucket struct {
 	// TODO: return retention rules?
 	RetentionPeriod   time.Duration  `json:"retentionPeriod"`
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
+	Action            ApplyAction    `json:"action,omitempty"`
 }
 
 // SummaryCheck provides a summary of a pkg check.
@@ -528,12 +1334,14 @@ type SummaryCheck struct {
 	Check             influxdb.Check  `json:"check"`
 	Status            influxdb.Status `json:"status"`
 	LabelAssociations []SummaryLabel  `json:"labelAssociations"`
+	Action            ApplyAction     `json:"action,omitempty"`
 }
 
 func (s *SummaryCheck) UnmarshalJSON(b []byte) error {
 	var out struct {
 		Status            string          `json:"status"`
 		LabelAssociations []SummaryLabel  `json:"labelAssociations"`
+		Action            ApplyAction     `json:"action"`
 		Check             json.RawMessage `json:"check"`
 	}
 	if err := json.Unmarshal(b, &out); err != nil {
@@ -541,6 +1349,7 @@ func (s *SummaryCheck) UnmarshalJSON(b []byte) error {
 	}
 	s.Status = influxdb.Status(out.Status)
 	s.LabelAssociations = out.LabelAssociations
+	s.Action = out.Action
 
 	var err error
 	s.Check, err = icheck.UnmarshalJSON(out.Check)
@@ -556,6 +1365,7 @@ type SummaryDashboard struct {
 	Charts      []SummaryChart `json:"charts"`
 
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
+	Action            ApplyAction    `json:"action,omitempty"`
 }
 
 // chartKind identifies what kind of chart is eluded too. Each
@@ -646,6 +1456,7 @@ func (s *SummaryChart) UnmarshalJSON(b []byte) error {
 type SummaryNotificationEndpoint struct {
 	NotificationEndpoint influxdb.NotificationEndpoint `json:"notificationEndpoint"`
 	LabelAssociations    []SummaryLabel                `json:"labelAssociations"`
+	Action               ApplyAction                   `json:"action,omitempty"`
 }
 
 // UnmarshalJSON unmarshals the notificatio endpoint. This is necessary b/c of
@@ -654,11 +1465,13 @@ func (s *SummaryNotificationEndpoint) UnmarshalJSON(b []byte) error {
 	var a struct {
 		NotificationEndpoint json.RawMessage `json:"notificationEndpoint"`
 		LabelAssociations    []SummaryLabel  `json:"labelAssociations"`
+		Action               ApplyAction     `json:"action"`
 	}
 	if err := json.Unmarshal(b, &a); err != nil {
 		return err
 	}
 	s.LabelAssociations = a.LabelAssociations
+	s.Action = a.Action
 
 	e, err := endpoint.UnmarshalJSON(a.NotificationEndpoint)
 	s.NotificationEndpoint = e
@@ -684,6 +1497,7 @@ type (
 		Status            influxdb.Status     `json:"status"`
 		StatusRules       []SummaryStatusRule `json:"statusRules"`
 		TagRules          []SummaryTagRule    `json:"tagRules"`
+		Action            ApplyAction         `json:"action,omitempty"`
 	}
 
 	SummaryStatusRule struct {
@@ -707,6 +1521,7 @@ type SummaryLabel struct {
 		Color       string `json:"color"`
 		Description string `json:"description"`
 	} `json:"properties"`
+	Action ApplyAction `json:"action,omitempty"`
 }
 
 // SummaryLabelMapping provides a summary of a label mapped with a single resource.
@@ -719,6 +1534,13 @@ type SummaryLabelMapping struct {
 	LabelID      SafeID                `json:"labelID"`
 }
 
+// shouldApply does 2 things, it does not write a mapping when one exists. it
+// also avoids having to worry about deleting an existing mapping since it will
+// not be added to the list of mappings that is referenced in the rollback.
+func (m SummaryLabelMapping) shouldApply() bool {
+	return !m.exists && m.LabelID != 0 && m.ResourceID != 0
+}
+
 // SummaryTask provides a summary of a task.
 type SummaryTask struct {
 	ID          SafeID          `json:"id"`
@@ -731,12 +1553,14 @@ type SummaryTask struct {
 	Status      influxdb.Status `json:"status"`
 
 	LabelAssociations []SummaryLabel `json:"labelAssociations"`
+	Action            ApplyAction    `json:"action,omitempty"`
 }
 
 // SummaryTelegraf provides a summary of a pkg telegraf config.
 type SummaryTelegraf struct {
 	TelegrafConfig    influxdb.TelegrafConfig `json:"telegrafConfig"`
 	LabelAssociations []SummaryLabel          `json:"labelAssociations"`
+	Action            ApplyAction             `json:"action,omitempty"`
 }
 
 // SummaryVariable provides a summary of a pkg variable.
@@ -747,6 +1571,7 @@ type SummaryVariable struct {
 	Description       string                      `json:"description"`
 	Arguments         *influxdb.VariableArguments `json:"arguments"`
 	LabelAssociations []SummaryLabel              `json:"labelAssociations"`
+	Action            ApplyAction                 `json:"action,omitempty"`
 }
 
 type identity struct {
@@ -805,6 +1630,9 @@ type bucket struct {
 	RetentionRules retentionRules
 	labels         sortedLabels
 
+	// action is the action taken on the bucket by an apply.
+	action ApplyAction
+
 	// existing provides context for a resource that already
 	// exists in the platform. If a resource already exists
 	// then it will be referenced here.
@@ -838,6 +1666,7 @@ func (b *bucket) summarize() SummaryBucket {
 		Description:       b.Description,
 		RetentionPeriod:   b.RetentionRules.RP(),
 		LabelAssociations: toSummaryLabels(b.labels...),
+		Action:            b.action,
 	}
 }
 
@@ -859,7 +1688,16 @@ func (b *bucket) shouldApply() bool {
 	return b.existing == nil ||
 		b.Description != b.existing.Description ||
 		b.Name() != b.existing.Name ||
-		b.RetentionRules.RP() != b.existing.RetentionPeriod
+		b.RetentionRules.RP() != b.existing.RetentionPeriod ||
+		b.shardGroupDurationChanged()
+}
+
+// shardGroupDurationChanged indicates the pkg bucket provides a shard group duration
+// that differs from the existing bucket's. A bucket that does not provide one leaves
+// the shard group duration of the existing bucket as is.
+func (b *bucket) shardGroupDurationChanged() bool {
+	sgd := b.RetentionRules.ShardGroupDuration()
+	return sgd > 0 && sgd != b.existing.ShardGroupDuration
 }
 
 type mapperBuckets []*bucket
@@ -879,6 +1717,8 @@ const (
 type retentionRule struct {
 	Type    string `json:"type" yaml:"type"`
 	Seconds int    `json:"everySeconds" yaml:"everySeconds"`
+	// ShardGroupDurationSeconds of zero implies the default shard group duration.
+	ShardGroupDurationSeconds int `json:"shardGroupDurationSeconds,omitempty" yaml:"shardGroupDurationSeconds,omitempty"`
 }
 
 func newRetentionRule(d time.Duration) retentionRule {
@@ -888,6 +1728,17 @@ func newRetentionRule(d time.Duration) retentionRule {
 	}
 }
 
+// bucketRetentionRules provides the retention rules of a platform bucket. A bucket
+// with an infinite retention period has no rules.
+func bucketRetentionRules(b influxdb.Bucket) retentionRules {
+	if b.RetentionPeriod <= 0 {
+		return nil
+	}
+	rule := newRetentionRule(b.RetentionPeriod)
+	rule.ShardGroupDurationSeconds = int(b.ShardGroupDuration.Round(time.Second) / time.Second)
+	return retentionRules{rule}
+}
+
 func (r retentionRule) valid() []validationErr {
 	const hour = 3600
 	var ff []validationErr
@@ -903,11 +1754,18 @@ func (r retentionRule) valid() []validationErr {
 			Msg:   `type must be "expire"`,
 		})
 	}
+	if r.ShardGroupDurationSeconds < 0 {
+		ff = append(ff, validationErr{
+			Field: fieldRetentionRulesShardGroupDurationSeconds,
+			Msg:   "shard group duration seconds must not be negative",
+		})
+	}
 	return ff
 }
 
 const (
-	fieldRetentionRulesEverySeconds = "everySeconds"
+	fieldRetentionRulesEverySeconds              = "everySeconds"
+	fieldRetentionRulesShardGroupDurationSeconds = "shardGroupDurationSeconds"
 )
 
 type retentionRules []retentionRule
@@ -921,6 +1779,15 @@ func (r retentionRules) RP() time.Duration {
 	return 0
 }
 
+// ShardGroupDuration provides the shard group duration of the retention rules, zero
+// implies the default shard group duration.
+func (r retentionRules) ShardGroupDuration() time.Duration {
+	for _, rule := range r {
+		return time.Duration(rule.ShardGroupDurationSeconds) * time.Second
+	}
+	return 0
+}
+
 func (r retentionRules) valid() []validationErr {
 	var failures []validationErr
 	for i, rule := range r {
@@ -974,6 +1841,7 @@ type check struct {
 	thresholds    []threshold
 
 	labels sortedLabels
+	action ApplyAction
 
 	existing influxdb.Check
 }
@@ -1023,6 +1891,7 @@ func (c *check) summarize() SummaryCheck {
 	sum := SummaryCheck{
 		Status:            c.Status(),
 		LabelAssociations: toSummaryLabels(c.labels...),
+		Action:            c.action,
 	}
 	switch c.kind {
 	case checkKindThreshold:
@@ -1253,6 +2122,9 @@ type label struct {
 	Description string
 	associationMapping
 
+	// action is the action taken on the label by an apply.
+	action ApplyAction
+
 	// exists provides context for a resource that already
 	// exists in the platform. If a resource already exists(exists=true)
 	// then the ID should be populated.
@@ -1285,6 +2157,7 @@ func (l *label) summarize() SummaryLabel {
 			Color:       l.Color,
 			Description: l.Description,
 		},
+		Action: l.action,
 	}
 }
 
@@ -1338,7 +2211,10 @@ func (l *label) valid() []validationErr {
 func toSummaryLabels(labels ...*label) []SummaryLabel {
 	iLabels := make([]SummaryLabel, 0, len(labels))
 	for _, l := range labels {
-		iLabels = append(iLabels, l.summarize())
+		// the action belongs to the label itself, not to its association
+		sum := l.summarize()
+		sum.Action = ApplyActionUnknown
+		iLabels = append(iLabels, sum)
 	}
 	return iLabels
 }
@@ -1397,6 +2273,7 @@ type notificationEndpoint struct {
 	username    *references
 
 	labels sortedLabels
+	action ApplyAction
 
 	existing influxdb.NotificationEndpoint
 }
@@ -1442,6 +2319,7 @@ func (n *notificationEndpoint) summarize() SummaryNotificationEndpoint {
 	}
 	sum := SummaryNotificationEndpoint{
 		LabelAssociations: toSummaryLabels(n.labels...),
+		Action:            n.action,
 	}
 
 	switch n.kind {
@@ -1607,13 +2485,20 @@ type notificationRule struct {
 	endpointType string
 
 	labels sortedLabels
+	action ApplyAction
+
+	existing       influxdb.NotificationRule
+	existingStatus influxdb.Status
 }
 
 func (r *notificationRule) Exists() bool {
-	return false
+	return r.existing != nil
 }
 
 func (r *notificationRule) ID() influxdb.ID {
+	if r.existing != nil {
+		return r.existing.GetID()
+	}
 	return r.id
 }
 
@@ -1632,6 +2517,20 @@ func (r *notificationRule) Status() influxdb.Status {
 	return influxdb.Status(r.status)
 }
 
+// scheduleErr validates the every and offset of the rule the same way the rule is
+// validated when it is created, so that a bad schedule is caught prior to applying it.
+func (r *notificationRule) scheduleErr() error {
+	switch {
+	case r.every <= 0:
+		return fmt.Errorf("every must be greater than 0; got=%s", r.every)
+	case r.offset < 0:
+		return fmt.Errorf("offset must not be negative; got=%s", r.offset)
+	case r.offset >= r.every:
+		return fmt.Errorf("offset %s must be less than every %s", r.offset, r.every)
+	}
+	return nil
+}
+
 func (r *notificationRule) summarize() SummaryNotificationRule {
 	return SummaryNotificationRule{
 		ID:                SafeID(r.ID()),
@@ -1647,6 +2546,7 @@ func (r *notificationRule) summarize() SummaryNotificationRule {
 		Status:            r.Status(),
 		StatusRules:       toSummaryStatusRules(r.statusRules),
 		TagRules:          toSummaryTagRules(r.tagRules),
+		Action:            r.action,
 	}
 }
 
@@ -1844,13 +2744,19 @@ type task struct {
 	status      string
 
 	labels sortedLabels
+	action ApplyAction
+
+	existing *influxdb.Task
 }
 
 func (t *task) Exists() bool {
-	return false
+	return t.existing != nil
 }
 
 func (t *task) ID() influxdb.ID {
+	if t.existing != nil {
+		return t.existing.ID
+	}
 	return t.id
 }
 
@@ -1914,6 +2820,7 @@ func (t *task) summarize() SummaryTask {
 		Status:      t.Status(),
 
 		LabelAssociations: toSummaryLabels(t.labels...),
+		Action:            t.action,
 	}
 }
 
@@ -1975,9 +2882,15 @@ type telegraf struct {
 	config influxdb.TelegrafConfig
 
 	labels sortedLabels
+	action ApplyAction
+
+	existing *influxdb.TelegrafConfig
 }
 
 func (t *telegraf) ID() influxdb.ID {
+	if t.existing != nil {
+		return t.existing.ID
+	}
 	return t.config.ID
 }
 
@@ -1990,15 +2903,17 @@ func (t *telegraf) ResourceType() influxdb.ResourceType {
 }
 
 func (t *telegraf) Exists() bool {
-	return false
+	return t.existing != nil
 }
 
 func (t *telegraf) summarize() SummaryTelegraf {
 	cfg := t.config
+	cfg.ID = t.ID()
 	cfg.Name = t.Name()
 	return SummaryTelegraf{
 		TelegrafConfig:    cfg,
 		LabelAssociations: toSummaryLabels(t.labels...),
+		Action:            t.action,
 	}
 }
 
@@ -2049,6 +2964,7 @@ type variable struct {
 	MapValues   map[string]string
 
 	labels sortedLabels
+	action ApplyAction
 
 	existing *influxdb.Variable
 }
@@ -2087,6 +3003,7 @@ func (v *variable) summarize() SummaryVariable {
 		Description:       v.Description,
 		Arguments:         v.influxVarArgs(),
 		LabelAssociations: toSummaryLabels(v.labels...),
+		Action:            v.action,
 	}
 }
 
@@ -2173,9 +3090,15 @@ type dashboard struct {
 	Charts      []chart
 
 	labels sortedLabels
+	action ApplyAction
+
+	existing *influxdb.Dashboard
 }
 
 func (d *dashboard) ID() influxdb.ID {
+	if d.existing != nil {
+		return d.existing.ID
+	}
 	return d.id
 }
 
@@ -2188,7 +3111,7 @@ func (d *dashboard) ResourceType() influxdb.ResourceType {
 }
 
 func (d *dashboard) Exists() bool {
-	return false
+	return d.existing != nil
 }
 
 func (d *dashboard) summarize() SummaryDashboard {
@@ -2198,6 +3121,7 @@ func (d *dashboard) summarize() SummaryDashboard {
 		Name:              d.Name(),
 		Description:       d.Description,
 		LabelAssociations: toSummaryLabels(d.labels...),
+		Action:            d.action,
 	}
 	for _, c := range d.Charts {
 		iDash.Charts = append(iDash.Charts, SummaryChart{
@@ -2826,14 +3750,17 @@ func (l legend) influxLegend() influxdb.Legend {
 }
 
 const (
-	fieldReferencesEnv    = "envRef"
-	fieldReferencesSecret = "secretRef"
+	fieldReferencesDefault = "default"
+	fieldReferencesEnv     = "envRef"
+	fieldReferencesSecret  = "secretRef"
 )
 
 type references struct {
 	val    interface{}
 	EnvRef string
-	Secret string
+	// EnvRefDefault is the value of the env ref when no value is provided for it.
+	EnvRefDefault string
+	Secret        string
 }
 
 func (r *references) hasValue() bool {
@@ -2848,6 +3775,9 @@ func (r *references) String() string {
 		return v
 	}
 	if r.EnvRef != "" {
+		if r.EnvRefDefault != "" {
+			return r.EnvRefDefault
+		}
 		return "$" + r.EnvRef
 	}
 	return ""
@@ -1,15 +1,24 @@
 package pkger
 
 import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/influxdata/influxdb"
+	"github.com/influxdata/influxdb/notification"
+	icheck "github.com/influxdata/influxdb/notification/check"
+	"github.com/influxdata/influxdb/notification/endpoint"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
+var updateGolden = flag.Bool("update-golden", false, "update the golden files of the pkger tests")
+
 func TestPkg(t *testing.T) {
 	t.Run("Summary", func(t *testing.T) {
 		t.Run("buckets returned in asc order by name", func(t *testing.T) {
@@ -124,6 +133,139 @@ func TestPkg(t *testing.T) {
 		})
 	})
 
+	t.Run("SummaryForKinds", func(t *testing.T) {
+		testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+			sum, err := pkg.SummaryForKinds(KindBucket)
+			require.NoError(t, err)
+
+			assert.Len(t, sum.Buckets, 3)
+			assert.Empty(t, sum.Labels)
+			assert.Empty(t, sum.LabelMappings)
+
+			sum, err = pkg.SummaryForKinds(KindLabel)
+			require.NoError(t, err)
+
+			assert.Empty(t, sum.Buckets)
+			assert.Len(t, sum.Labels, 2)
+			assert.Len(t, sum.LabelMappings, 4)
+
+			sum, err = pkg.SummaryForKinds()
+			require.NoError(t, err)
+			assert.Equal(t, pkg.Summary(), sum)
+
+			_, err = pkg.SummaryForKinds(Kind("unsupported"))
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("typed getters", func(t *testing.T) {
+		t.Run("match the summary", func(t *testing.T) {
+			pkg := Pkg{
+				mBuckets: map[string]*bucket{
+					"buck_2": {
+						id:       influxdb.ID(2),
+						identity: identity{name: &references{val: "name2"}},
+					},
+					"buck_1": {
+						id:       influxdb.ID(1),
+						identity: identity{name: &references{val: "name1"}},
+					},
+				},
+				mLabels: map[string]*label{
+					"label_1": {
+						id:       influxdb.ID(3),
+						identity: identity{name: &references{val: "label1"}},
+					},
+				},
+			}
+
+			summary := pkg.Summary()
+			assert.Equal(t, summary.Buckets, pkg.Buckets())
+			assert.Equal(t, summary.Labels, pkg.Labels())
+		})
+
+		t.Run("empty pkg returns initialized slices", func(t *testing.T) {
+			pkg := Pkg{}
+
+			assert.NotNil(t, pkg.Buckets())
+			assert.Empty(t, pkg.Buckets())
+			assert.NotNil(t, pkg.Checks())
+			assert.NotNil(t, pkg.Dashboards())
+			assert.NotNil(t, pkg.Labels())
+			assert.NotNil(t, pkg.NotificationEndpoints())
+			assert.NotNil(t, pkg.NotificationRules())
+			assert.NotNil(t, pkg.Tasks())
+			assert.NotNil(t, pkg.TelegrafConfigs())
+			assert.NotNil(t, pkg.Variables())
+		})
+	})
+
+	t.Run("Clone", func(t *testing.T) {
+		testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+			clone, err := pkg.Clone()
+			require.NoError(t, err)
+			assert.True(t, clone.isParsed)
+			assert.Equal(t, pkg.Summary(), clone.Summary())
+			assert.Equal(t, pkg.Objects, clone.Objects)
+
+			for _, b := range clone.buckets() {
+				b.id = influxdb.ID(3)
+				b.OrgID = influxdb.ID(9000)
+			}
+			clone.Objects[0].Metadata["name"] = "changed_name"
+
+			for _, b := range pkg.buckets() {
+				assert.Zero(t, b.ID())
+				assert.Zero(t, b.OrgID)
+			}
+			assert.NotEqual(t, pkg.Objects, clone.Objects)
+		})
+
+		t.Run("a pkg that fails to graph is not marked parsed", func(t *testing.T) {
+			pkg := &Pkg{
+				Objects: []Object{
+					{APIVersion: APIVersion, Kind: KindBucket, Metadata: Resource{"name": "a"}},
+				},
+				isParsed: true,
+			}
+
+			clone, err := pkg.Clone()
+			require.Error(t, err)
+			assert.True(t, IsParseErr(err))
+			require.NotNil(t, clone)
+			assert.False(t, clone.isParsed)
+		})
+	})
+
+	t.Run("CheckAPIVersion", func(t *testing.T) {
+		t.Run("supported version", func(t *testing.T) {
+			pkg := Pkg{
+				Objects: []Object{
+					{APIVersion: APIVersion, Kind: KindBucket},
+					{APIVersion: APIVersion, Kind: KindLabel},
+				},
+			}
+
+			require.NoError(t, pkg.CheckAPIVersion())
+		})
+
+		t.Run("names unsupported versions", func(t *testing.T) {
+			pkg := Pkg{
+				Objects: []Object{
+					{APIVersion: APIVersion, Kind: KindBucket},
+					{APIVersion: "influxdata.com/v3", Kind: KindLabel},
+					{APIVersion: "influxdata.com/v1alpha1", Kind: KindBucket},
+					{APIVersion: "influxdata.com/v3", Kind: KindTask},
+				},
+			}
+
+			err := pkg.CheckAPIVersion()
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EUnprocessableEntity, influxdb.ErrorCode(err))
+			assert.Contains(t, err.Error(), `["influxdata.com/v1alpha1", "influxdata.com/v3"]`)
+		})
+	})
+
 	t.Run("Diff", func(t *testing.T) {
 		t.Run("hasConflict", func(t *testing.T) {
 			tests := []struct {
@@ -438,5 +580,436 @@ func TestPkg(t *testing.T) {
 				t.Run(tt.name, fn)
 			}
 		})
+
+		t.Run("DiffPackages", func(t *testing.T) {
+			oldPkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  description: old desc
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Dashboard
+metadata:
+  name: dash_1
+spec:
+  description: old desc
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSlack
+metadata:
+  name: endpoint_1
+spec:
+  url: https://hooks.slack.com/services/bip/piddy/boppidy
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: rule_1
+spec:
+  endpointName: endpoint_1
+  every: 1h
+  messageTemplate: "Notification Rule: ${ r._notification_rule_name } triggered by check: ${ r._check_name }: ${ r._message }"
+  statusRules:
+    - currentLevel: CRIT
+`), EncodingYAML)
+
+			newPkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name: label_2
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  description: new desc
+  associations:
+    - kind: Label
+      name: label_1
+    - kind: Label
+      name: label_2
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_2
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Dashboard
+metadata:
+  name: dash_1
+spec:
+  description: new desc
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Dashboard
+metadata:
+  name: dash_2
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointSlack
+metadata:
+  name: endpoint_1
+spec:
+  url: https://hooks.slack.com/services/bip/piddy/boppidy
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: rule_1
+spec:
+  endpointName: endpoint_1
+  every: 30m
+  messageTemplate: "Notification Rule: ${ r._notification_rule_name } triggered by check: ${ r._check_name }: ${ r._message }"
+  statusRules:
+    - currentLevel: CRIT
+`), EncodingYAML)
+
+			diff := DiffPackages(oldPkg, newPkg)
+
+			require.Len(t, diff.Buckets, 2)
+			bkt1 := diff.Buckets[0]
+			assert.Equal(t, "rucket_1", bkt1.Name)
+			assert.False(t, bkt1.IsNew())
+			require.NotNil(t, bkt1.Old)
+			assert.Equal(t, "old desc", bkt1.Old.Description)
+			assert.Equal(t, "new desc", bkt1.New.Description)
+			assert.True(t, bkt1.hasConflict())
+
+			bkt2 := diff.Buckets[1]
+			assert.Equal(t, "rucket_2", bkt2.Name)
+			assert.True(t, bkt2.IsNew())
+
+			require.Len(t, diff.Dashboards, 2)
+			dash1 := diff.Dashboards[0]
+			assert.Equal(t, "dash_1", dash1.Name)
+			assert.False(t, dash1.IsNew())
+			require.NotNil(t, dash1.Old)
+			assert.Equal(t, "old desc", dash1.Old.Desc)
+			assert.Equal(t, "new desc", dash1.Desc)
+			assert.True(t, diff.Dashboards[1].IsNew())
+
+			require.Len(t, diff.NotificationRules, 1)
+			rule1 := diff.NotificationRules[0]
+			assert.False(t, rule1.IsNew())
+			require.NotNil(t, rule1.Old)
+			assert.Equal(t, "1h0m0s", rule1.Old.Every)
+			assert.Equal(t, "30m0s", rule1.Every)
+			assert.Equal(t, "endpoint_1", rule1.OldEndpointName)
+
+			require.Len(t, diff.Labels, 2)
+			assert.False(t, diff.Labels[0].IsNew())
+			assert.False(t, diff.Labels[0].hasConflict())
+			assert.True(t, diff.Labels[1].IsNew())
+
+			expectedMappings := []DiffLabelMapping{
+				{
+					IsNew:     false,
+					ResType:   influxdb.BucketsResourceType,
+					ResName:   "rucket_1",
+					LabelName: "label_1",
+				},
+				{
+					IsNew:     true,
+					ResType:   influxdb.BucketsResourceType,
+					ResName:   "rucket_1",
+					LabelName: "label_2",
+				},
+			}
+			assert.Equal(t, expectedMappings, diff.LabelMappings)
+		})
+	})
+}
+
+func TestKind(t *testing.T) {
+	t.Run("ResourceType", func(t *testing.T) {
+		tests := []struct {
+			kind     Kind
+			expected influxdb.ResourceType
+		}{
+			{kind: KindBucket, expected: influxdb.BucketsResourceType},
+			{kind: KindCheck, expected: influxdb.ChecksResourceType},
+			{kind: KindCheckDeadman, expected: influxdb.ChecksResourceType},
+			{kind: KindCheckThreshold, expected: influxdb.ChecksResourceType},
+			{kind: KindDashboard, expected: influxdb.DashboardsResourceType},
+			{kind: KindLabel, expected: influxdb.LabelsResourceType},
+			{kind: KindNotificationEndpoint, expected: influxdb.NotificationEndpointResourceType},
+			{kind: KindNotificationEndpointHTTP, expected: influxdb.NotificationEndpointResourceType},
+			{kind: KindNotificationEndpointPagerDuty, expected: influxdb.NotificationEndpointResourceType},
+			{kind: KindNotificationEndpointSlack, expected: influxdb.NotificationEndpointResourceType},
+			{kind: KindNotificationRule, expected: influxdb.NotificationRuleResourceType},
+			{kind: KindTask, expected: influxdb.TasksResourceType},
+			{kind: KindTelegraf, expected: influxdb.TelegrafsResourceType},
+			{kind: KindVariable, expected: influxdb.VariablesResourceType},
+			{kind: KindPackage, expected: ""},
+			{kind: KindUnknown, expected: ""},
+		}
+
+		for _, tt := range tests {
+			fn := func(t *testing.T) {
+				actual := tt.kind.ResourceType()
+				assert.Equal(t, tt.expected, actual)
+				if tt.expected != "" {
+					require.NoError(t, actual.Valid())
+				}
+			}
+			t.Run(tt.kind.String(), fn)
+		}
+
+		t.Run("every supported kind has a valid resource type", func(t *testing.T) {
+			for k := range kinds {
+				resType := k.ResourceType()
+				require.NotEmpty(t, resType, "kind="+k.String())
+				require.NoError(t, resType.Valid(), "kind="+k.String())
+			}
+		})
+
+		t.Run("clone kinds map to distinct resource types", func(t *testing.T) {
+			resGens := new(Service).filterOrgResourceKinds(CreateByOrgIDOpt{})
+			require.Len(t, resGens, 9)
+
+			seen := make(map[influxdb.ResourceType]bool)
+			for _, resGen := range resGens {
+				require.NoError(t, resGen.resType.Valid())
+				assert.False(t, seen[resGen.resType], "duplicate resource type "+string(resGen.resType))
+				seen[resGen.resType] = true
+			}
+		})
+	})
+}
+
+func TestSupportedKinds(t *testing.T) {
+	supported := SupportedKinds()
+
+	require.Len(t, supported, len(kinds))
+	for i, k := range supported {
+		require.NoError(t, k.OK())
+		if i > 0 {
+			assert.True(t, supported[i-1] < k, "kinds are not sorted: %v", supported)
+		}
+	}
+	assert.Contains(t, supported, KindBucket)
+	assert.NotContains(t, supported, KindUnknown)
+}
+
+func TestDiffSummaries(t *testing.T) {
+	t.Run("reports added, removed, and changed resources", func(t *testing.T) {
+		before := Summary{
+			Buckets: []SummaryBucket{
+				{ID: 1, Name: "rucket_1", RetentionPeriod: time.Hour},
+				{ID: 2, Name: "rucket_2"},
+			},
+			Labels: []SummaryLabel{
+				{ID: 3, Name: "label_1"},
+			},
+		}
+		after := Summary{
+			Buckets: []SummaryBucket{
+				{ID: 1, Name: "rucket_1", RetentionPeriod: 2 * time.Hour},
+			},
+			Labels: []SummaryLabel{
+				{ID: 3, Name: "label_1"},
+			},
+			Variables: []SummaryVariable{
+				{ID: 4, Name: "var_1"},
+			},
+		}
+
+		delta := DiffSummaries(before, after)
+
+		assert.Equal(t, []SummaryDeltaResource{{Kind: KindVariable, Name: "var_1"}}, delta.Added)
+		assert.Equal(t, []SummaryDeltaResource{{Kind: KindBucket, Name: "rucket_2"}}, delta.Removed)
+		assert.Equal(t, []SummaryDeltaResource{{Kind: KindBucket, Name: "rucket_1"}}, delta.Changed)
+	})
+
+	t.Run("identical summaries have no changes", func(t *testing.T) {
+		sum := Summary{
+			Buckets: []SummaryBucket{{ID: 1, Name: "rucket_1"}},
+			Tasks:   []SummaryTask{{ID: 2, Name: "task_1", Every: "1h"}},
+		}
+
+		delta := DiffSummaries(sum, sum)
+
+		assert.Empty(t, delta.Added)
+		assert.Empty(t, delta.Removed)
+		assert.Empty(t, delta.Changed)
+	})
+}
+
+func TestDiffStats(t *testing.T) {
+	diff := Diff{
+		Buckets: []DiffBucket{
+			{Name: "rucket_1"},
+			{
+				ID:   1,
+				Name: "rucket_2",
+				New:  DiffBucketValues{Description: "new desc"},
+				Old:  &DiffBucketValues{Description: "old desc"},
+			},
+			{
+				ID:   2,
+				Name: "rucket_3",
+				New:  DiffBucketValues{Description: "desc"},
+				Old:  &DiffBucketValues{Description: "desc"},
+			},
+		},
+		Dashboards: []DiffDashboard{
+			{Name: "dash_1"},
+			{ID: 3, Name: "dash_2", Old: &DiffDashboardValues{}},
+		},
+		Tasks: []DiffTask{
+			{
+				ID:   4,
+				Name: "task_1",
+				New:  DiffTaskValues{Every: "1h"},
+				Old:  &DiffTaskValues{Every: "1h"},
+			},
+		},
+		LabelMappings: []DiffLabelMapping{
+			{IsNew: true},
+			{IsNew: false},
+			{IsNew: false, NotInPkg: true},
+		},
+	}
+
+	stats := diff.Stats()
+
+	expected := map[Kind]DiffStat{
+		KindBucket:    {New: 1, Changed: 1, Unchanged: 1},
+		KindDashboard: {New: 1, Changed: 1},
+		KindTask:      {Unchanged: 1},
+	}
+	assert.Equal(t, expected, stats.Kinds)
+	assert.Equal(t, DiffStat{New: 1, Unchanged: 1, NotInPkg: 1}, stats.LabelMappings)
+}
+
+func TestDiffBucketRetentionShrink(t *testing.T) {
+	tests := []struct {
+		name     string
+		oldRP    time.Duration
+		newRP    time.Duration
+		expected bool
+	}{
+		{name: "shortened", oldRP: 30 * time.Hour, newRP: time.Hour, expected: true},
+		{name: "shortened from infinite", oldRP: 0, newRP: time.Hour, expected: true},
+		{name: "lengthened", oldRP: time.Hour, newRP: 30 * time.Hour},
+		{name: "lengthened to infinite", oldRP: time.Hour, newRP: 0},
+		{name: "unchanged", oldRP: time.Hour, newRP: time.Hour},
+	}
+
+	for _, tt := range tests {
+		fn := func(t *testing.T) {
+			b := &bucket{}
+			if tt.newRP > 0 {
+				b.RetentionRules = retentionRules{newRetentionRule(tt.newRP)}
+			}
+
+			diff := newDiffBucket(b, &influxdb.Bucket{ID: 1, RetentionPeriod: tt.oldRP})
+			assert.Equal(t, tt.expected, diff.RetentionShrink)
+
+			// a new bucket has no data to drop
+			assert.False(t, newDiffBucket(b, nil).RetentionShrink)
+		}
+		t.Run(tt.name, fn)
+	}
+}
+
+func TestSummaryJSON(t *testing.T) {
+	const goldenFile = "testdata/summary.golden.json"
+
+	testfileRunner(t, "testdata/summary.yml", func(t *testing.T, pkg *Pkg) {
+		b, err := json.MarshalIndent(pkg.Summary(), "", "\t")
+		require.NoError(t, err)
+
+		if *updateGolden {
+			require.NoError(t, ioutil.WriteFile(goldenFile, append(b, '\n'), 0644))
+		}
+
+		golden, err := ioutil.ReadFile(goldenFile)
+		require.NoError(t, err)
+		assert.Equal(t, strings.TrimSpace(string(golden)), string(b))
+	})
+}
+
+func TestDiffJSON(t *testing.T) {
+	t.Run("check values are encoded as the check", func(t *testing.T) {
+		values := DiffCheckValues{
+			Check: &icheck.Deadman{
+				Base: icheck.Base{
+					Name:  "check_1",
+					Every: mustDuration(t, time.Minute),
+				},
+				Level: notification.Critical,
+			},
+		}
+
+		b, err := json.Marshal(values)
+		require.NoError(t, err)
+
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &raw))
+		assert.Equal(t, "deadman", raw["type"])
+		assert.Equal(t, "check_1", raw["name"])
+		assert.NotContains(t, raw, "Check")
+
+		var decoded DiffCheckValues
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		reencoded, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(b), string(reencoded))
+	})
+
+	t.Run("notification endpoint values are encoded as the endpoint", func(t *testing.T) {
+		values := DiffNotificationEndpointValues{
+			NotificationEndpoint: &endpoint.Slack{
+				Base: endpoint.Base{
+					Name:   "endpoint_1",
+					Status: influxdb.Active,
+				},
+				URL: "https://hooks.slack.com/services/bip/piddy/boppidy",
+			},
+		}
+
+		b, err := json.Marshal(values)
+		require.NoError(t, err)
+
+		var raw map[string]interface{}
+		require.NoError(t, json.Unmarshal(b, &raw))
+		assert.Equal(t, "slack", raw["type"])
+		assert.NotContains(t, raw, "NotificationEndpoint")
+
+		var decoded DiffNotificationEndpointValues
+		require.NoError(t, json.Unmarshal(b, &decoded))
+		reencoded, err := json.Marshal(decoded)
+		require.NoError(t, err)
+		assert.JSONEq(t, string(b), string(reencoded))
+	})
+
+	t.Run("nil values are encoded as null", func(t *testing.T) {
+		b, err := json.Marshal(DiffCheckValues{})
+		require.NoError(t, err)
+		assert.Equal(t, "null", string(b))
+
+		b, err = json.Marshal(DiffNotificationEndpointValues{})
+		require.NoError(t, err)
+		assert.Equal(t, "null", string(b))
 	})
 }
@@ -83,6 +83,13 @@ func Parse(encoding Encoding, readerFn ReaderFn, opts ...ValidateOptFn) (*Pkg, e
 	}
 }
 
+// ParseReader parses a pkg read from the reader, as Parse does when provided FromReader.
+// It is the entry point for parsing a pkg from a stream, such as a request body or
+// stdin. With EncodingSource the encoding is inferred from the contents of the reader.
+func ParseReader(encoding Encoding, r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
+	return Parse(encoding, FromReader(r), opts...)
+}
+
 // FromFile reads a file from disk and provides a reader from it.
 func FromFile(filePath string) ReaderFn {
 	return func() (io.Reader, error) {
@@ -137,11 +144,31 @@ func FromHTTPRequest(addr string) ReaderFn {
 }
 
 func parseJSON(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
-	return parse(json.NewDecoder(r), opts...)
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// json is valid yaml, decoding it as yaml provides the location of each
+	// object within the raw pkg for its parse errors.
+	var sources []*yaml.Node
+	var doc yaml.Node
+	if err := yaml.Unmarshal(b, &doc); err == nil && len(doc.Content) == 1 {
+		sources = doc.Content[0].Content
+	}
+
+	return parse(json.NewDecoder(bytes.NewReader(b)), func(objects []Object) {
+		if len(sources) != len(objects) {
+			return
+		}
+		for i := range objects {
+			objects[i].source = sources[i]
+		}
+	}, opts...)
 }
 
 func parseJsonnet(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
-	return parse(jsonnet.NewDecoder(r), opts...)
+	return parse(jsonnet.NewDecoder(r), nil, opts...)
 }
 
 func parseSource(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
@@ -156,7 +183,8 @@ func parseSource(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
 		b = bb
 	}
 
-	contentType := http.DetectContentType(b[:512])
+	// the content type is detected from at most the first 512 bytes
+	contentType := http.DetectContentType(b)
 	switch {
 	case strings.Contains(contentType, "jsonnet"):
 		// highly unlikely to fall in here with supported content type detection as is
@@ -178,14 +206,22 @@ func parseYAML(r io.Reader, opts ...ValidateOptFn) (*Pkg, error) {
 	for {
 		// forced to use this for loop b/c the yaml dependency does not
 		// decode multi documents.
-		var k Object
-		err := dec.Decode(&k)
+		var doc yaml.Node
+		err := dec.Decode(&doc)
 		if err == io.EOF {
 			break
 		}
 		if err != nil {
 			return nil, err
 		}
+
+		var k Object
+		if err := doc.Decode(&k); err != nil {
+			return nil, err
+		}
+		if len(doc.Content) == 1 {
+			k.source = doc.Content[0]
+		}
 		pkg.Objects = append(pkg.Objects, k)
 	}
 
@@ -200,11 +236,14 @@ type decoder interface {
 	Decode(interface{}) error
 }
 
-func parse(dec decoder, opts ...ValidateOptFn) (*Pkg, error) {
+func parse(dec decoder, setSources func([]Object), opts ...ValidateOptFn) (*Pkg, error) {
 	var pkg Pkg
 	if err := dec.Decode(&pkg.Objects); err != nil {
 		return nil, err
 	}
+	if setSources != nil {
+		setSources(pkg.Objects)
+	}
 
 	if err := pkg.Validate(opts...); err != nil {
 		return nil, err
@@ -219,6 +258,10 @@ type Object struct {
 	Kind       Kind     `json:"kind" yaml:"kind"`
 	Metadata   Resource `json:"metadata" yaml:"metadata"`
 	Spec       Resource `json:"spec" yaml:"spec"`
+
+	// source is the yaml node the object was decoded from, used to locate
+	// parse errors within the raw pkg. It is nil when the location is unknown.
+	source *yaml.Node
 }
 
 // Name returns the name of the kind.
@@ -235,6 +278,11 @@ func (k Object) Name() string {
 type Pkg struct {
 	Objects []Object `json:"-" yaml:"-"`
 
+	// the resource maps are populated when the pkg is graphed and are never
+	// written to during an Apply, making it safe for the appliers to read them
+	// concurrently. An applier only mutates the resources it applies, guarded by
+	// its own mutex, and resources from an earlier apply phase are only read once
+	// that phase has completed.
 	mLabels                map[string]*label
 	mBuckets               map[string]*bucket
 	mChecks                map[string]*check
@@ -245,9 +293,10 @@ type Pkg struct {
 	mTelegrafs             map[string]*telegraf
 	mVariables             map[string]*variable
 
-	mEnv     map[string]bool
-	mEnvVals map[string]string
-	mSecrets map[string]bool
+	mEnv         map[string]bool
+	mEnvDefaults map[string]string
+	mEnvVals     map[string]string
+	mSecrets     map[string]bool
 
 	isVerified bool // dry run has verified pkg resources with existing resources
 	isParsed   bool // indicates the pkg has been parsed and all resources graphed accordingly
@@ -280,6 +329,65 @@ func (p *Pkg) Encode(encoding Encoding) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// objectStreamEncoder encodes objects to the underlying writer one at a time,
+// producing the same document layout as Pkg.Encode without requiring all the
+// objects to be held in memory.
+type objectStreamEncoder struct {
+	w        io.Writer
+	encoding Encoding
+	yamlEnc  *yaml.Encoder
+	written  int
+}
+
+func newObjectStreamEncoder(w io.Writer, encoding Encoding) (*objectStreamEncoder, error) {
+	enc := &objectStreamEncoder{w: w, encoding: encoding}
+	switch encoding {
+	case EncodingJSON, EncodingJsonnet:
+	case EncodingYAML:
+		enc.yamlEnc = yaml.NewEncoder(w)
+	default:
+		return nil, ErrInvalidEncoding
+	}
+	return enc, nil
+}
+
+func (e *objectStreamEncoder) Encode(o Object) error {
+	defer func() { e.written++ }()
+
+	if e.yamlEnc != nil {
+		return e.yamlEnc.Encode(o)
+	}
+
+	b, err := json.MarshalIndent(o, "\t", "\t")
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n\t"
+	if e.written == 0 {
+		prefix = "[\n\t"
+	}
+	if _, err := io.WriteString(e.w, prefix); err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+// Close terminates the document. This must be called once all objects are encoded.
+func (e *objectStreamEncoder) Close() error {
+	if e.yamlEnc != nil {
+		return e.yamlEnc.Close()
+	}
+
+	end := "\n]\n"
+	if e.written == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(e.w, end)
+	return err
+}
+
 // Summary returns a package Summary that describes all the resources and
 // associations the pkg contains. It is very useful for informing users of
 // the changes that will take place when this pkg would be applied.
@@ -294,6 +402,7 @@ func (p *Pkg) Summary() Summary {
 		NotificationRules:     []SummaryNotificationRule{},
 		Labels:                []SummaryLabel{},
 		MissingEnvs:           p.missingEnvRefs(),
+		DefaultedEnvs:         p.defaultedEnvRefs(),
 		MissingSecrets:        []string{},
 		Tasks:                 []SummaryTask{},
 		TelegrafConfigs:       []SummaryTelegraf{},
@@ -305,45 +414,160 @@ func (p *Pkg) Summary() Summary {
 		sum.MissingSecrets = p.missingSecrets()
 	}
 
-	for _, b := range p.buckets() {
-		sum.Buckets = append(sum.Buckets, b.summarize())
+	sum.Buckets = append(sum.Buckets, p.Buckets()...)
+	sum.Checks = append(sum.Checks, p.Checks()...)
+	sum.Dashboards = append(sum.Dashboards, p.Dashboards()...)
+	sum.Labels = append(sum.Labels, p.Labels()...)
+	sum.LabelMappings = p.labelMappings()
+	sum.NotificationEndpoints = append(sum.NotificationEndpoints, p.NotificationEndpoints()...)
+	sum.NotificationRules = append(sum.NotificationRules, p.NotificationRules()...)
+	sum.Tasks = append(sum.Tasks, p.Tasks()...)
+	sum.TelegrafConfigs = append(sum.TelegrafConfigs, p.TelegrafConfigs()...)
+	sum.Variables = append(sum.Variables, p.Variables()...)
+	sum.SkippedCounts = sum.skippedCounts()
+
+	return sum
+}
+
+// Buckets returns a summary of every bucket the pkg contains.
+func (p *Pkg) Buckets() []SummaryBucket {
+	buckets := p.buckets()
+	out := make([]SummaryBucket, 0, len(buckets))
+	for _, b := range buckets {
+		out = append(out, b.summarize())
 	}
+	return out
+}
 
-	for _, c := range p.checks() {
-		sum.Checks = append(sum.Checks, c.summarize())
+// Checks returns a summary of every check the pkg contains.
+func (p *Pkg) Checks() []SummaryCheck {
+	checks := p.checks()
+	out := make([]SummaryCheck, 0, len(checks))
+	for _, c := range checks {
+		out = append(out, c.summarize())
 	}
+	return out
+}
 
-	for _, d := range p.dashboards() {
-		sum.Dashboards = append(sum.Dashboards, d.summarize())
+// Dashboards returns a summary of every dashboard the pkg contains.
+func (p *Pkg) Dashboards() []SummaryDashboard {
+	dashboards := p.dashboards()
+	out := make([]SummaryDashboard, 0, len(dashboards))
+	for _, d := range dashboards {
+		out = append(out, d.summarize())
 	}
+	return out
+}
 
-	for _, l := range p.labels() {
-		sum.Labels = append(sum.Labels, l.summarize())
+// Labels returns a summary of every label the pkg contains.
+func (p *Pkg) Labels() []SummaryLabel {
+	labels := p.labels()
+	out := make([]SummaryLabel, 0, len(labels))
+	for _, l := range labels {
+		out = append(out, l.summarize())
 	}
+	return out
+}
 
-	sum.LabelMappings = p.labelMappings()
+// NotificationEndpoints returns a summary of every notification endpoint
+// the pkg contains.
+func (p *Pkg) NotificationEndpoints() []SummaryNotificationEndpoint {
+	endpoints := p.notificationEndpoints()
+	out := make([]SummaryNotificationEndpoint, 0, len(endpoints))
+	for _, e := range endpoints {
+		out = append(out, e.summarize())
+	}
+	return out
+}
+
+// NotificationRules returns a summary of every notification rule the pkg contains.
+func (p *Pkg) NotificationRules() []SummaryNotificationRule {
+	rules := p.notificationRules()
+	out := make([]SummaryNotificationRule, 0, len(rules))
+	for _, r := range rules {
+		out = append(out, r.summarize())
+	}
+	return out
+}
 
-	for _, n := range p.notificationEndpoints() {
-		sum.NotificationEndpoints = append(sum.NotificationEndpoints, n.summarize())
+// Tasks returns a summary of every task the pkg contains.
+func (p *Pkg) Tasks() []SummaryTask {
+	tasks := p.tasks()
+	out := make([]SummaryTask, 0, len(tasks))
+	for _, t := range tasks {
+		out = append(out, t.summarize())
 	}
+	return out
+}
+
+// TelegrafConfigs returns a summary of every telegraf config the pkg contains.
+func (p *Pkg) TelegrafConfigs() []SummaryTelegraf {
+	telegrafs := p.telegrafs()
+	out := make([]SummaryTelegraf, 0, len(telegrafs))
+	for _, t := range telegrafs {
+		out = append(out, t.summarize())
+	}
+	return out
+}
 
-	for _, r := range p.notificationRules() {
-		sum.NotificationRules = append(sum.NotificationRules, r.summarize())
+// Variables returns a summary of every variable the pkg contains.
+func (p *Pkg) Variables() []SummaryVariable {
+	variables := p.variables()
+	out := make([]SummaryVariable, 0, len(variables))
+	for _, v := range variables {
+		out = append(out, v.summarize())
 	}
+	return out
+}
 
-	for _, t := range p.tasks() {
-		sum.Tasks = append(sum.Tasks, t.summarize())
+// SummaryForKinds returns a package Summary that only contains the resources of the
+// provided kinds. Label mappings are included when labels are requested. When no
+// kinds are provided, the full Summary is returned.
+func (p *Pkg) SummaryForKinds(kinds ...Kind) (Summary, error) {
+	mResTypes := make(map[influxdb.ResourceType]bool)
+	for _, k := range kinds {
+		if err := k.OK(); err != nil {
+			return Summary{}, fmt.Errorf("kind %q: %s", k, err)
+		}
+		mResTypes[k.ResourceType()] = true
 	}
 
-	for _, t := range p.telegrafs() {
-		sum.TelegrafConfigs = append(sum.TelegrafConfigs, t.summarize())
+	sum := p.Summary()
+	if len(kinds) == 0 {
+		return sum, nil
 	}
 
-	for _, v := range p.variables() {
-		sum.Variables = append(sum.Variables, v.summarize())
+	if !mResTypes[influxdb.BucketsResourceType] {
+		sum.Buckets = []SummaryBucket{}
+	}
+	if !mResTypes[influxdb.ChecksResourceType] {
+		sum.Checks = []SummaryCheck{}
+	}
+	if !mResTypes[influxdb.DashboardsResourceType] {
+		sum.Dashboards = []SummaryDashboard{}
+	}
+	if !mResTypes[influxdb.LabelsResourceType] {
+		sum.Labels = []SummaryLabel{}
+		sum.LabelMappings = []SummaryLabelMapping{}
+	}
+	if !mResTypes[influxdb.NotificationEndpointResourceType] {
+		sum.NotificationEndpoints = []SummaryNotificationEndpoint{}
+	}
+	if !mResTypes[influxdb.NotificationRuleResourceType] {
+		sum.NotificationRules = []SummaryNotificationRule{}
+	}
+	if !mResTypes[influxdb.TasksResourceType] {
+		sum.Tasks = []SummaryTask{}
+	}
+	if !mResTypes[influxdb.TelegrafsResourceType] {
+		sum.TelegrafConfigs = []SummaryTelegraf{}
+	}
+	if !mResTypes[influxdb.VariablesResourceType] {
+		sum.Variables = []SummaryVariable{}
 	}
+	sum.SkippedCounts = sum.skippedCounts()
 
-	return sum
+	return sum, nil
 }
 
 func (p *Pkg) applyEnvRefs(envRefs map[string]string) error {
@@ -362,6 +586,180 @@ func (p *Pkg) applyEnvRefs(envRefs map[string]string) error {
 	return p.Validate()
 }
 
+// Clone provides an independent copy of the pkg. The objects of the pkg are deep
+// copied, and its resources are graphed anew from them, with the env ref values
+// the pkg has been provided. The IDs and org IDs assigned to the resources by a
+// dry run or apply are not carried over, making the clone safe to apply anew.
+// A clone whose resources fail to graph is returned alongside the parse error,
+// it is not marked parsed, as Validate would leave it.
+func (p *Pkg) Clone() (*Pkg, error) {
+	newPkg := &Pkg{
+		Objects: make([]Object, 0, len(p.Objects)),
+	}
+	for _, o := range p.Objects {
+		newPkg.Objects = append(newPkg.Objects, Object{
+			APIVersion: o.APIVersion,
+			Kind:       o.Kind,
+			Metadata:   copyResource(o.Metadata),
+			Spec:       copyResource(o.Spec),
+			source:     o.source,
+		})
+	}
+
+	if len(p.mEnvVals) > 0 {
+		newPkg.mEnvVals = make(map[string]string, len(p.mEnvVals))
+		for k, v := range p.mEnvVals {
+			newPkg.mEnvVals[k] = v
+		}
+	}
+
+	if err := newPkg.Validate(ValidWithoutResources()); err != nil {
+		if IsParseErr(err) {
+			return newPkg, err
+		}
+		return nil, err
+	}
+	return newPkg, nil
+}
+
+// transform provides a copy of the pkg with each of its objects run through the
+// transforms in turn. The copy is graphed anew from the transformed objects, the
+// pkg itself is left untouched.
+func (p *Pkg) transform(transforms ...func(obj Object) (Object, error)) (*Pkg, error) {
+	// the transformed objects are validated below, a parse error of the clone
+	// is superseded by it.
+	newPkg, err := p.Clone()
+	if err != nil && !IsParseErr(err) {
+		return nil, err
+	}
+	for i, o := range newPkg.Objects {
+		kind, name := o.Kind, o.Name()
+		for _, fn := range transforms {
+			o, err = fn(o)
+			if err != nil {
+				return nil, fmt.Errorf("failed to transform %s %q: %s", kind, name, err)
+			}
+		}
+		newPkg.Objects[i] = o
+	}
+
+	if err := newPkg.Validate(); err != nil {
+		return newPkg, err
+	}
+	return newPkg, nil
+}
+
+func copyResource(r Resource) Resource {
+	if r == nil {
+		return nil
+	}
+	out := make(Resource, len(r))
+	for k, v := range r {
+		out[k] = copyResourceValue(v)
+	}
+	return out
+}
+
+func copyResourceValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case Resource:
+		return copyResource(v)
+	case map[string]interface{}:
+		return map[string]interface{}(copyResource(v))
+	case map[interface{}]interface{}:
+		out := make(map[interface{}]interface{}, len(v))
+		for k, vv := range v {
+			out[k] = copyResourceValue(vv)
+		}
+		return out
+	case []Resource:
+		out := make([]Resource, 0, len(v))
+		for _, r := range v {
+			out = append(out, copyResource(r))
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, 0, len(v))
+		for _, vv := range v {
+			out = append(out, copyResourceValue(vv))
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// withBucketRetention provides a copy of the pkg with the retention of every bucket
+// replaced by the retention provided. The pkg itself is left untouched. Any parse
+// error of the copy is returned alongside it, as Clone does.
+func (p *Pkg) withBucketRetention(rp time.Duration) (*Pkg, error) {
+	newPkg, err := p.Clone()
+	if err != nil && !IsParseErr(err) {
+		return nil, err
+	}
+	for _, b := range newPkg.mBuckets {
+		var rules retentionRules
+		if rp > 0 {
+			// the shard group duration of the bucket is retained
+			rule := newRetentionRule(rp)
+			rule.ShardGroupDurationSeconds = int(b.RetentionRules.ShardGroupDuration() / time.Second)
+			rules = retentionRules{rule}
+		}
+		b.RetentionRules = rules
+	}
+	return newPkg, err
+}
+
+// withNamePrefix provides a copy of the pkg with the prefix prepended to the name of
+// each of its resources. The pkg names are left as is, so the associations of the pkg
+// continue to resolve. A rule's endpoint dependency is resolved to the prefixed name
+// of the endpoint, whether the endpoint is part of the pkg or exists on the platform.
+func (p *Pkg) withNamePrefix(prefix string) (*Pkg, error) {
+	newPkg, err := p.Clone()
+	if err != nil && !IsParseErr(err) {
+		return nil, err
+	}
+
+	prefixName := func(i *identity) {
+		i.displayName = &references{val: prefix + i.Name()}
+	}
+	for _, b := range newPkg.mBuckets {
+		prefixName(&b.identity)
+	}
+	for _, c := range newPkg.mChecks {
+		prefixName(&c.identity)
+	}
+	for _, d := range newPkg.mDashboards {
+		prefixName(&d.identity)
+	}
+	for _, l := range newPkg.mLabels {
+		prefixName(&l.identity)
+	}
+	for _, e := range newPkg.mNotificationEndpoints {
+		prefixName(&e.identity)
+	}
+	for _, r := range newPkg.mNotificationRules {
+		prefixName(&r.identity)
+
+		endpointName := prefix + r.endpointName.String()
+		if e, ok := newPkg.mNotificationEndpoints[r.endpointName.String()]; ok {
+			endpointName = e.Name()
+		}
+		r.endpointName = &references{val: endpointName}
+	}
+	for _, t := range newPkg.mTasks {
+		prefixName(&t.identity)
+	}
+	for _, t := range newPkg.mTelegrafs {
+		prefixName(&t.identity)
+	}
+	for _, v := range newPkg.mVariables {
+		prefixName(&v.identity)
+	}
+
+	return newPkg, err
+}
+
 func (p *Pkg) applySecrets(secrets map[string]string) {
 	for k := range secrets {
 		p.mSecrets[k] = true
@@ -432,6 +830,7 @@ func (p *Pkg) Validate(opts ...ValidateOptFn) error {
 	}
 
 	if len(pErr.Resources) > 0 && !opt.skipValidate {
+		pErr.locateFn = p.locate
 		return &pErr
 	}
 
@@ -439,6 +838,36 @@ func (p *Pkg) Validate(opts ...ValidateOptFn) error {
 	return nil
 }
 
+// CheckAPIVersion verifies every object in the pkg declares an apiVersion
+// supported by this build. The returned error names each unsupported version
+// found, allowing users to catch a pkg written for another version of the
+// format before any of its resources are parsed.
+func (p *Pkg) CheckAPIVersion() error {
+	mUnsupported := make(map[string]bool)
+	for _, o := range p.Objects {
+		if o.APIVersion != APIVersion {
+			mUnsupported[o.APIVersion] = true
+		}
+	}
+	if len(mUnsupported) == 0 {
+		return nil
+	}
+
+	unsupported := make([]string, 0, len(mUnsupported))
+	for v := range mUnsupported {
+		unsupported = append(unsupported, strconv.Quote(v))
+	}
+	sort.Strings(unsupported)
+
+	return &influxdb.Error{
+		Code: influxdb.EUnprocessableEntity,
+		Msg: fmt.Sprintf(
+			"unsupported API version(s) [%s]; supported version is %q",
+			strings.Join(unsupported, ", "), APIVersion,
+		),
+	}
+}
+
 func (p *Pkg) buckets() []*bucket {
 	buckets := make([]*bucket, 0, len(p.mBuckets))
 	for _, b := range p.mBuckets {
@@ -508,7 +937,18 @@ func (p *Pkg) notificationRules() []*notificationRule {
 func (p *Pkg) missingEnvRefs() []string {
 	envRefs := make([]string, 0)
 	for envRef, matching := range p.mEnv {
-		if !matching {
+		if !matching && p.mEnvDefaults[envRef] == "" {
+			envRefs = append(envRefs, envRef)
+		}
+	}
+	sort.Strings(envRefs)
+	return envRefs
+}
+
+func (p *Pkg) defaultedEnvRefs() []string {
+	envRefs := make([]string, 0)
+	for envRef, matching := range p.mEnv {
+		if !matching && p.mEnvDefaults[envRef] != "" {
 			envRefs = append(envRefs, envRef)
 		}
 	}
@@ -612,6 +1052,7 @@ func (p *Pkg) validResources() error {
 
 func (p *Pkg) graphResources() error {
 	p.mEnv = make(map[string]bool)
+	p.mEnvDefaults = make(map[string]string)
 	p.mSecrets = make(map[string]bool)
 
 	graphFns := []func() *parseErr{
@@ -663,8 +1104,9 @@ func (p *Pkg) graphBuckets() *parseErr {
 		} else {
 			for _, r := range o.Spec.slcResource(fieldBucketRetentionRules) {
 				bkt.RetentionRules = append(bkt.RetentionRules, retentionRule{
-					Type:    r.stringShort(fieldType),
-					Seconds: r.intShort(fieldRetentionRulesEverySeconds),
+					Type:                      r.stringShort(fieldType),
+					Seconds:                   r.intShort(fieldRetentionRulesEverySeconds),
+					ShardGroupDurationSeconds: r.intShort(fieldRetentionRulesShardGroupDurationSeconds),
 				})
 			}
 		}
@@ -1219,6 +1661,10 @@ func (p *Pkg) setRefs(refs ...*references) {
 		}
 		if ref.EnvRef != "" {
 			p.mEnv[ref.EnvRef] = p.mEnvVals[ref.EnvRef] != ""
+			// an env ref only has a default when every use of it declares one
+			if _, ok := p.mEnvDefaults[ref.EnvRef]; !ok || ref.EnvRefDefault == "" {
+				p.mEnvDefaults[ref.EnvRef] = ref.EnvRefDefault
+			}
 		}
 	}
 }
@@ -1452,6 +1898,7 @@ func (r Resource) references(key string) *references {
 			switch f {
 			case fieldReferencesEnv:
 				ref.EnvRef = keyRes.stringShort(fieldKey)
+				ref.EnvRefDefault = keyRes.stringShort(fieldReferencesDefault)
 			case fieldReferencesSecret:
 				ref.Secret = keyRes.stringShort(fieldKey)
 			}
@@ -1623,6 +2070,10 @@ type (
 	parseErr struct {
 		Resources []resourceErr
 		rawErrs   []ValidationErr
+
+		// locateFn provides the line and column of a validation error
+		// within the raw pkg, when known.
+		locateFn func(ValidationErr) (line, column int)
 	}
 
 	// resourceErr describes the error for a particular resource. In
@@ -1700,21 +2151,46 @@ func (e *parseErr) ValidationErrs() []ValidationErr {
 			continue
 		}
 		m[k] = true
+		if e.locateFn != nil {
+			verr.Line, verr.Column = e.locateFn(verr)
+		}
 		out = append(out, verr)
 	}
 
 	return out
 }
 
-// ValidationErr represents an error during the parsing of a package.
+// ValidationErr represents an error during the parsing of a package. The Line
+// and Column locate the error within the raw YAML or JSON pkg, and are 0 when
+// the location is unknown.
 type ValidationErr struct {
 	Kind    string   `json:"kind" yaml:"kind"`
 	Fields  []string `json:"fields" yaml:"fields"`
 	Indexes []*int   `json:"idxs" yaml:"idxs"`
 	Reason  string   `json:"reason" yaml:"reason"`
+	Line    int      `json:"line,omitempty" yaml:"line,omitempty"`
+	Column  int      `json:"column,omitempty" yaml:"column,omitempty"`
 }
 
 func (v ValidationErr) Error() string {
+	if v.Line > 0 {
+		return fmt.Sprintf("kind=%s field=%s line=%d column=%d reason=%q", v.Kind, v.FieldPath(), v.Line, v.Column, v.Reason)
+	}
+	return fmt.Sprintf("kind=%s field=%s reason=%q", v.Kind, v.FieldPath(), v.Reason)
+}
+
+// ObjectIndex returns the index of the object within the pkg that failed
+// validation. When the error is not for an individual object, -1 is returned.
+func (v ValidationErr) ObjectIndex() int {
+	if len(v.Fields) == 0 || v.Fields[0] != "root" || len(v.Indexes) == 0 || v.Indexes[0] == nil {
+		return -1
+	}
+	return *v.Indexes[0]
+}
+
+// FieldPath returns the path to the field that failed validation, with the
+// index of each field within a list, i.e. root[0].charts[1].kind.
+func (v ValidationErr) FieldPath() string {
 	fieldPairs := make([]string, 0, len(v.Fields))
 	for i, idx := range v.Indexes {
 		field := v.Fields[i]
@@ -1724,8 +2200,7 @@ func (v ValidationErr) Error() string {
 		}
 		fieldPairs = append(fieldPairs, fmt.Sprintf("%s[%d]", field, *idx))
 	}
-
-	return fmt.Sprintf("kind=%s field=%s reason=%q", v.Kind, strings.Join(fieldPairs, "."), v.Reason)
+	return strings.Join(fieldPairs, ".")
 }
 
 func traverseErrs(root ValidationErr, vErr validationErr) []ValidationErr {
@@ -1753,7 +2228,7 @@ func (e *parseErr) append(errs ...resourceErr) {
 // it will return nil values for the parseErr, making it unsafe
 // to use.
 func IsParseErr(err error) bool {
-	if _, ok := err.(*parseErr); ok {
+	if _, ok := err.(ParseError); ok {
 		return true
 	}
 
@@ -1764,6 +2239,50 @@ func IsParseErr(err error) bool {
 	return IsParseErr(iErr.Err)
 }
 
+// locate provides the line and column of the field of the object that failed
+// validation. When the field cannot be found, the deepest parent of the field
+// that is found is located instead.
+func (p *Pkg) locate(vErr ValidationErr) (line, column int) {
+	idx := vErr.ObjectIndex()
+	if idx < 0 || idx >= len(p.Objects) || p.Objects[idx].source == nil {
+		return 0, 0
+	}
+
+	node := p.Objects[idx].source
+	for i := 1; i < len(vErr.Fields); i++ {
+		next := yamlMapValue(node, vErr.Fields[i])
+		if next == nil && i == 1 {
+			// the fields of the spec are reported without the spec prefix
+			next = yamlMapValue(yamlMapValue(node, fieldSpec), vErr.Fields[i])
+		}
+		if next == nil {
+			break
+		}
+		node = next
+
+		if i < len(vErr.Indexes) && vErr.Indexes[i] != nil {
+			elemIdx := *vErr.Indexes[i]
+			if node.Kind != yaml.SequenceNode || elemIdx < 0 || elemIdx >= len(node.Content) {
+				break
+			}
+			node = node.Content[elemIdx]
+		}
+	}
+	return node.Line, node.Column
+}
+
+func yamlMapValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
 func objectValidationErr(field string, vErrs ...validationErr) validationErr {
 	return validationErr{
 		Field:  field,
@@ -1,6 +1,10 @@
 package pkger
 
 import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"path/filepath"
@@ -3918,6 +3922,52 @@ spec:
 		})
 	})
 
+	t.Run("referencing env with a default", func(t *testing.T) {
+		pkgStr := `apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name:
+    envRef:
+      key: label-1-name-ref
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name:
+    envRef:
+      key: bkt-1-name-ref
+      default: bucket-default
+spec:
+  associations:
+    - kind: Label
+      name:
+        envRef:
+          key: label-1-name-ref
+`
+		pkg := newParsedPkg(t, FromString(pkgStr), EncodingYAML)
+
+		sum := pkg.Summary()
+		require.Len(t, sum.Buckets, 1)
+		assert.Equal(t, "bucket-default", sum.Buckets[0].Name)
+		assert.Equal(t, []string{"label-1-name-ref"}, sum.MissingEnvs)
+		assert.Equal(t, []string{"bkt-1-name-ref"}, sum.DefaultedEnvs)
+
+		t.Log("provided env refs should override the default")
+		{
+			err := pkg.applyEnvRefs(map[string]string{
+				"bkt-1-name-ref":   "bucket-1",
+				"label-1-name-ref": "label-1",
+			})
+			require.NoError(t, err)
+
+			sum := pkg.Summary()
+			require.Len(t, sum.Buckets, 1)
+			assert.Equal(t, "bucket-1", sum.Buckets[0].Name)
+			assert.Empty(t, sum.MissingEnvs)
+			assert.Empty(t, sum.DefaultedEnvs)
+		}
+	})
+
 	t.Run("jsonnet support", func(t *testing.T) {
 		pkg := validParsedPkgFromFile(t, "testdata/bucket_associates_labels.jsonnet", EncodingJsonnet)
 
@@ -3958,6 +4008,45 @@ spec:
 	})
 }
 
+func TestParseReader(t *testing.T) {
+	yamlPkg := fmt.Sprintf(`
+apiVersion: %[1]s
+kind: Label
+metadata:
+  name: label_1
+`, APIVersion)
+	jsonPkg := fmt.Sprintf(`[{"apiVersion": %q, "kind": "Label", "metadata": {"name": "label_1"}}]`, APIVersion)
+
+	tests := []struct {
+		name     string
+		encoding Encoding
+		content  string
+	}{
+		{name: "yaml", encoding: EncodingYAML, content: yamlPkg},
+		{name: "json", encoding: EncodingJSON, content: jsonPkg},
+		{name: "source yaml", encoding: EncodingSource, content: yamlPkg},
+		{name: "source json", encoding: EncodingSource, content: jsonPkg},
+	}
+
+	for _, tt := range tests {
+		fn := func(t *testing.T) {
+			// the buffer is shorter than the 512 bytes used to detect the content type
+			pkg, err := ParseReader(tt.encoding, bytes.NewBufferString(tt.content))
+			require.NoError(t, err)
+
+			labels := pkg.Summary().Labels
+			require.Len(t, labels, 1)
+			assert.Equal(t, "label_1", labels[0].Name)
+		}
+		t.Run(tt.name, fn)
+	}
+
+	t.Run("invalid encoding", func(t *testing.T) {
+		_, err := ParseReader(EncodingUnknown, strings.NewReader(yamlPkg))
+		require.Equal(t, ErrInvalidEncoding, err)
+	})
+}
+
 func TestCombine(t *testing.T) {
 	newPkgFromYmlStr := func(t *testing.T, pkgStr string) *Pkg {
 		t.Helper()
@@ -4063,6 +4152,118 @@ spec:
 	})
 }
 
+func TestParseArchive(t *testing.T) {
+	files := []struct {
+		name    string
+		content string
+	}{
+		{
+			name: "pack/labels.yml",
+			content: fmt.Sprintf(`
+apiVersion: %[1]s
+kind: Label
+metadata:
+  name: label_1
+`, APIVersion),
+		},
+		{
+			name: "pack/buckets/bucket.json",
+			content: fmt.Sprintf(`[{
+  "apiVersion": %q,
+  "kind": "Bucket",
+  "metadata": {"name": "rucket_1"},
+  "spec": {"associations": [{"kind": "Label", "name": "label_1"}]}
+}]`, APIVersion),
+		},
+		{name: "pack/README.md", content: "# not a pkg"},
+		{name: "pack/.hidden.yml", content: "not: [a pkg"},
+	}
+
+	tarGz := func(t *testing.T) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		gw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gw)
+		for _, f := range files {
+			hdr := &tar.Header{
+				Name:     f.name,
+				Mode:     0600,
+				Size:     int64(len(f.content)),
+				Typeflag: tar.TypeReg,
+			}
+			require.NoError(t, tw.WriteHeader(hdr))
+			_, err := tw.Write([]byte(f.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, tw.Close())
+		require.NoError(t, gw.Close())
+		return buf.Bytes()
+	}
+
+	zipped := func(t *testing.T) []byte {
+		t.Helper()
+
+		var buf bytes.Buffer
+		zw := zip.NewWriter(&buf)
+		for _, f := range files {
+			w, err := zw.Create(f.name)
+			require.NoError(t, err)
+			_, err = w.Write([]byte(f.content))
+			require.NoError(t, err)
+		}
+		require.NoError(t, zw.Close())
+		return buf.Bytes()
+	}
+
+	tests := []struct {
+		name    string
+		archive func(t *testing.T) []byte
+	}{
+		{name: "tar.gz", archive: tarGz},
+		{name: "zip", archive: zipped},
+	}
+
+	for _, tt := range tests {
+		fn := func(t *testing.T) {
+			pkg, err := ParseArchive(FromReader(bytes.NewReader(tt.archive(t))))
+			require.NoError(t, err)
+
+			sum := pkg.Summary()
+			require.Len(t, sum.Labels, 1)
+			assert.Equal(t, "label_1", sum.Labels[0].Name)
+
+			require.Len(t, sum.Buckets, 1)
+			assert.Equal(t, "rucket_1", sum.Buckets[0].Name)
+			require.Len(t, sum.Buckets[0].LabelAssociations, 1)
+			assert.Equal(t, "label_1", sum.Buckets[0].LabelAssociations[0].Name)
+		}
+		t.Run(tt.name, fn)
+	}
+
+	t.Run("errors for a reader that is not an archive", func(t *testing.T) {
+		_, err := ParseArchive(FromString("not an archive"))
+		require.Error(t, err)
+		assert.Equal(t, ErrInvalidArchive, err)
+	})
+
+	t.Run("errors for an archive larger than the max size", func(t *testing.T) {
+		b := append(tarGz(t), make([]byte, MaxArchiveSize)...)
+		_, err := ParseArchive(FromReader(bytes.NewReader(b)))
+		require.Error(t, err)
+		assert.Equal(t, ErrArchiveTooLarge, err)
+	})
+
+	t.Run("recognizes archive names", func(t *testing.T) {
+		for _, name := range []string{"pack.tar.gz", "pack.tgz", "https://example.com/PACK.ZIP"} {
+			assert.True(t, IsArchive(name), name)
+		}
+		for _, name := range []string{"pack.yml", "pack.json", "pack.gz"} {
+			assert.False(t, IsArchive(name), name)
+		}
+	})
+}
+
 func Test_IsParseError(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -4117,6 +4318,92 @@ func Test_IsParseError(t *testing.T) {
 	}
 }
 
+func Test_ParseErrLocation(t *testing.T) {
+	findErr := func(t *testing.T, err error, fieldPath string) ValidationErr {
+		t.Helper()
+
+		require.True(t, IsParseErr(err), err)
+		for _, vErr := range err.(ParseError).ValidationErrs() {
+			if vErr.FieldPath() == fieldPath {
+				return vErr
+			}
+		}
+		require.FailNow(t, "validation error not found for field: "+fieldPath, err.Error())
+		return ValidationErr{}
+	}
+
+	t.Run("yaml", func(t *testing.T) {
+		pkgStr := fmt.Sprintf(`apiVersion: %[1]s
+kind: Label
+metadata:
+  name: label_1
+---
+apiVersion: %[1]s
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  retentionRules:
+    - type: expire
+      everySeconds: 1
+`, APIVersion)
+
+		_, err := Parse(EncodingYAML, FromString(pkgStr))
+		require.Error(t, err)
+
+		vErr := findErr(t, err, "root[1].spec.retentionRules[0].everySeconds")
+		assert.Equal(t, 1, vErr.ObjectIndex())
+		assert.Equal(t, 13, vErr.Line)
+		assert.Equal(t, 21, vErr.Column)
+		assert.Contains(t, vErr.Error(), "line=13 column=21")
+	})
+
+	t.Run("json", func(t *testing.T) {
+		pkgStr := fmt.Sprintf(`[
+  {
+    "apiVersion": %q,
+    "kind": "Bucket",
+    "metadata": {"name": "rucket_1"},
+    "spec": {
+      "retentionRules": [{"type": "expire", "everySeconds": 1}]
+    }
+  }
+]`, APIVersion)
+
+		_, err := Parse(EncodingJSON, FromString(pkgStr))
+		require.Error(t, err)
+
+		vErr := findErr(t, err, "root[0].spec.retentionRules[0].everySeconds")
+		assert.Equal(t, 0, vErr.ObjectIndex())
+		assert.Equal(t, 7, vErr.Line)
+		assert.NotZero(t, vErr.Column)
+	})
+
+	t.Run("falls back to the parent of a missing field", func(t *testing.T) {
+		pkgStr := fmt.Sprintf(`apiVersion: %[1]s
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  retentionRules:
+    - everySeconds: 3600
+`, APIVersion)
+
+		_, err := Parse(EncodingYAML, FromString(pkgStr))
+		require.Error(t, err)
+
+		vErr := findErr(t, err, "root[0].spec.retentionRules[0].type")
+		assert.Equal(t, 7, vErr.Line)
+		assert.Equal(t, 7, vErr.Column)
+	})
+
+	t.Run("unknown location for errors from a jsonnet pkg", func(t *testing.T) {
+		vErr := ValidationErr{Kind: KindBucket.String(), Fields: []string{"root"}, Indexes: []*int{intPtr(0)}, Reason: "bad"}
+		assert.Equal(t, `kind=Bucket field=root[0] reason="bad"`, vErr.Error())
+		assert.Equal(t, -1, ValidationErr{Kind: KindPackage.String(), Fields: []string{"resources"}}.ObjectIndex())
+	})
+}
+
 func Test_PkgValidationErr(t *testing.T) {
 	iPtr := func(i int) *int {
 		return &i
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"sort"
 	"strings"
@@ -12,6 +13,7 @@ import (
 
 	"github.com/influxdata/influxdb"
 	ierrors "github.com/influxdata/influxdb/kit/errors"
+	"github.com/influxdata/influxdb/notification/endpoint"
 	"github.com/influxdata/influxdb/snowflake"
 	"go.uber.org/zap"
 )
@@ -61,10 +63,13 @@ type SVCMiddleware func(SVC) SVC
 type serviceOpt struct {
 	logger *zap.Logger
 
-	applyReqLimit int
-	idGen         influxdb.IDGenerator
-	timeGen       influxdb.TimeGenerator
-	store         Store
+	applyGraph      ApplyGraph
+	applyQuotaFn    ApplyQuotaFn
+	applyReqLimit   int
+	applyRollbackFn ApplyRollbackFn
+	idGen           influxdb.IDGenerator
+	timeGen         influxdb.TimeGenerator
+	store           Store
 
 	bucketSVC   influxdb.BucketService
 	checkSVC    influxdb.CheckService
@@ -89,6 +94,80 @@ func WithLogger(log *zap.Logger) ServiceSetterFn {
 	}
 }
 
+// ApplyQuotaFn is called during a dry run with the number of new resources of the
+// given kind a pkg would create within the org. A non nil error blocks the pkg from
+// being applied.
+type ApplyQuotaFn func(ctx context.Context, orgID influxdb.ID, kind Kind, count int) error
+
+// ApplyRollback describes an apply that failed and was rolled back.
+type ApplyRollback struct {
+	// Resource is the resource type of the applier that failed first, such as
+	// "check". It is empty when the apply failed outside of the appliers.
+	Resource string
+	// Err is the first error returned by the failed applier, or the error that
+	// failed the apply when no applier failed.
+	Err error
+	// RolledBack is the number of applied resources that were rolled back.
+	RolledBack int
+	// FailedRollbacks is the number of appliers that failed to roll back.
+	FailedRollbacks int
+	// Skipped is the number of applied resources left in place, as their kind
+	// was provided to ApplyWithNoRollbackKinds.
+	Skipped int
+}
+
+// ApplyRollbackFn is called once a failed apply has been rolled back within the org.
+type ApplyRollbackFn func(ctx context.Context, orgID influxdb.ID, rb ApplyRollback)
+
+// KindFuncs are the callbacks a kind is cloned, dry run, applied, and rolled back
+// with, as registered by Service.RegisterKind. A nil callback is skipped.
+type KindFuncs struct {
+	// Clone returns the resources of the kind that exist within the org.
+	Clone func(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error)
+	// DryRun validates the pkg's resources of the kind against the org. An
+	// error fails the dry run.
+	DryRun func(ctx context.Context, orgID influxdb.ID, pkg *Pkg) error
+	// Apply applies the pkg's resources of the kind to the org.
+	Apply func(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg) error
+	// Rollback undoes a successful Apply when the pkg apply fails.
+	Rollback func(ctx context.Context, orgID influxdb.ID, pkg *Pkg) error
+}
+
+// WithApplyGraph sets the graph that orders the application of a pkg's resources.
+// The graph must contain every kind in the DefaultApplyGraph.
+func WithApplyGraph(g ApplyGraph) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.applyGraph = g
+	}
+}
+
+// WithApplyQuota sets the quota hook for the service. This allows for limiting the
+// number of resources a pkg may create within an org.
+func WithApplyQuota(fn ApplyQuotaFn) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.applyQuotaFn = fn
+	}
+}
+
+// WithApplyRollbackObserver sets the hook called whenever an apply is rolled back.
+// This allows for tracking how often applies roll back and the resources that
+// cause them to.
+func WithApplyRollbackObserver(fn ApplyRollbackFn) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		opt.applyRollbackFn = fn
+	}
+}
+
+// WithApplyReqLimit sets the maximum number of requests made concurrently when
+// applying or exporting a pkg.
+func WithApplyReqLimit(limit int) ServiceSetterFn {
+	return func(opt *serviceOpt) {
+		if limit > 0 {
+			opt.applyReqLimit = limit
+		}
+	}
+}
+
 // WithIDGenerator sets the id generator for the service.
 func WithIDGenerator(idGen influxdb.IDGenerator) ServiceSetterFn {
 	return func(opt *serviceOpt) {
@@ -201,10 +280,16 @@ type Service struct {
 	log *zap.Logger
 
 	// internal dependencies
-	applyReqLimit int
-	idGen         influxdb.IDGenerator
-	store         Store
-	timeGen       influxdb.TimeGenerator
+	applyGraph      ApplyGraph
+	applyQuotaFn    ApplyQuotaFn
+	applyReqLimit   int
+	applyRollbackFn ApplyRollbackFn
+	idGen           influxdb.IDGenerator
+	store           Store
+	timeGen         influxdb.TimeGenerator
+
+	kindsMu sync.RWMutex
+	kinds   map[Kind]KindFuncs
 
 	// external service dependencies
 	bucketSVC   influxdb.BucketService
@@ -226,6 +311,7 @@ var _ SVC = (*Service)(nil)
 func NewService(opts ...ServiceSetterFn) *Service {
 	opt := &serviceOpt{
 		logger:        zap.NewNop(),
+		applyGraph:    DefaultApplyGraph(),
 		applyReqLimit: 5,
 		idGen:         snowflake.NewDefaultIDGenerator(),
 		timeGen:       influxdb.RealTimeGenerator{},
@@ -237,10 +323,13 @@ func NewService(opts ...ServiceSetterFn) *Service {
 	return &Service{
 		log: opt.logger,
 
-		applyReqLimit: opt.applyReqLimit,
-		idGen:         opt.idGen,
-		store:         opt.store,
-		timeGen:       opt.timeGen,
+		applyGraph:      opt.applyGraph,
+		applyQuotaFn:    opt.applyQuotaFn,
+		applyReqLimit:   opt.applyReqLimit,
+		applyRollbackFn: opt.applyRollbackFn,
+		idGen:           opt.idGen,
+		store:           opt.store,
+		timeGen:         opt.timeGen,
 
 		bucketSVC:   opt.bucketSVC,
 		checkSVC:    opt.checkSVC,
@@ -256,6 +345,81 @@ func NewService(opts ...ServiceSetterFn) *Service {
 	}
 }
 
+// RegisterKind registers the funcs the resources of a kind are handled with.
+// The funcs of a kind the service already supports replace its clone and apply,
+// the dry run is run in addition to that of the kind. The kind must be within
+// the apply graph of the service, which determines when it is applied.
+func (s *Service) RegisterKind(k Kind, funcs KindFuncs) error {
+	if k == KindUnknown {
+		return errors.New("invalid kind")
+	}
+	if _, ok := s.applyGraph[k]; !ok {
+		return fmt.Errorf("kind %q is not in the apply graph", k)
+	}
+
+	s.kindsMu.Lock()
+	defer s.kindsMu.Unlock()
+	if s.kinds == nil {
+		s.kinds = make(map[Kind]KindFuncs)
+	}
+	s.kinds[k] = funcs
+	return nil
+}
+
+// registeredKinds returns a copy of the kinds registered with RegisterKind.
+func (s *Service) registeredKinds() map[Kind]KindFuncs {
+	s.kindsMu.RLock()
+	defer s.kindsMu.RUnlock()
+
+	kinds := make(map[Kind]KindFuncs, len(s.kinds))
+	for k, funcs := range s.kinds {
+		kinds[k] = funcs
+	}
+	return kinds
+}
+
+// checkSentinelStackID is the stack read by Check to round trip the store. The
+// stack is not expected to exist, a not found error is a successful read.
+const checkSentinelStackID = influxdb.ID(1)
+
+// Check verifies the critical dependencies of the service are available, making it
+// suitable for a readiness probe. The store is round tripped with the read of a
+// sentinel stack and the organization service is asked to list a single org. The
+// returned error describes the first dependency found to be unavailable.
+func (s *Service) Check(ctx context.Context) error {
+	if s.store == nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "pkger store is not configured",
+		}
+	}
+	_, err := s.store.ReadStackByID(ctx, checkSentinelStackID)
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "pkger store is unavailable",
+			Err:  err,
+		}
+	}
+
+	if s.orgSVC == nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "organization service is not configured",
+		}
+	}
+	_, _, err = s.orgSVC.FindOrganizations(ctx, influxdb.OrganizationFilter{}, influxdb.FindOptions{Limit: 1})
+	if err != nil {
+		return &influxdb.Error{
+			Code: influxdb.EUnavailable,
+			Msg:  "organization service is unavailable",
+			Err:  err,
+		}
+	}
+
+	return nil
+}
+
 // InitStack will create a new stack for the given user and its given org. The stack can be created
 // with urls that point to the location of packages that are included as part of the stack when
 // it is applied.
@@ -286,6 +450,73 @@ func (s *Service) InitStack(ctx context.Context, userID influxdb.ID, stack Stack
 	return stack, nil
 }
 
+// DeleteStackOpts are the options for deleting a stack.
+type DeleteStackOpts struct {
+	// RetainResources removes the stack from the store, leaving the platform
+	// resources the stack created in place. When false, the default, each
+	// resource associated with the stack is deleted from the platform before
+	// the stack itself is removed.
+	RetainResources bool
+}
+
+// DeleteStack removes a stack and, unless opts.RetainResources is set, all the
+// resources associated with the stack.
+func (s *Service) DeleteStack(ctx context.Context, stackID influxdb.ID, opts DeleteStackOpts) error {
+	stack, err := s.store.ReadStackByID(ctx, stackID)
+	if err != nil {
+		if influxdb.ErrorCode(err) == influxdb.ENotFound {
+			return err
+		}
+		return internalErr(err)
+	}
+
+	if !opts.RetainResources {
+		for _, r := range stack.Resources {
+			if err := s.deleteStackResource(ctx, r); err != nil {
+				msg := fmt.Sprintf("failed to delete %s resource %q for stack: %s", r.Kind, r.ID, err)
+				return toInfluxError(influxdb.EInternal, msg)
+			}
+		}
+	}
+
+	if err := s.store.DeleteStack(ctx, stackID); err != nil {
+		return internalErr(err)
+	}
+	return nil
+}
+
+func (s *Service) deleteStackResource(ctx context.Context, r StackResource) error {
+	var err error
+	switch r.Kind.ResourceType() {
+	case influxdb.BucketsResourceType:
+		err = s.bucketSVC.DeleteBucket(ctx, r.ID)
+	case influxdb.ChecksResourceType:
+		err = s.checkSVC.DeleteCheck(ctx, r.ID)
+	case influxdb.DashboardsResourceType:
+		err = s.dashSVC.DeleteDashboard(ctx, r.ID)
+	case influxdb.LabelsResourceType:
+		err = s.labelSVC.DeleteLabel(ctx, r.ID)
+	case influxdb.NotificationEndpointResourceType:
+		_, _, err = s.endpointSVC.DeleteNotificationEndpoint(ctx, r.ID)
+	case influxdb.NotificationRuleResourceType:
+		err = s.ruleSVC.DeleteNotificationRule(ctx, r.ID)
+	case influxdb.TasksResourceType:
+		err = s.taskSVC.DeleteTask(ctx, r.ID)
+	case influxdb.TelegrafsResourceType:
+		err = s.teleSVC.DeleteTelegrafConfig(ctx, r.ID)
+	case influxdb.VariablesResourceType:
+		err = s.varSVC.DeleteVariable(ctx, r.ID)
+	default:
+		return errors.New("unsupported kind")
+	}
+
+	// a resource removed out from under the stack is already in the desired state
+	if err != nil && influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return err
+	}
+	return nil
+}
+
 type (
 	// CreatePkgSetFn is a functional input for setting the pkg fields.
 	CreatePkgSetFn func(opt *CreateOpt) error
@@ -302,6 +533,12 @@ type (
 		OrgID         influxdb.ID
 		LabelNames    []string
 		ResourceKinds []Kind
+
+		// IncludeBackingTasks includes the tasks that back checks and notification
+		// rules as task resources. These tasks are created by their check or rule
+		// when applied, so applying a pkg that includes them creates duplicate
+		// tasks. This is intended for inspecting or backing up an org's tasks.
+		IncludeBackingTasks bool
 	}
 )
 
@@ -345,40 +582,209 @@ func (s *Service) CreatePkg(ctx context.Context, setters ...CreatePkgSetFn) (*Pk
 	}
 
 	exporter := newResourceExporter(s)
+	if err := s.export(ctx, exporter, *opt); err != nil {
+		return nil, err
+	}
 
-	for _, orgIDOpt := range opt.OrgIDs {
-		resourcesToClone, err := s.cloneOrgResources(ctx, orgIDOpt.OrgID, orgIDOpt.ResourceKinds)
-		if err != nil {
-			return nil, internalErr(err)
+	pkg := &Pkg{Objects: exporter.Objects()}
+	if err := pkg.Validate(ValidWithoutResources()); err != nil {
+		return nil, failedValidationErr(err)
+	}
+
+	return pkg, nil
+}
+
+// ExportResource exports a single resource into a minimal pkg. The pkg contains the
+// resource along with the dependencies it requires to be applied on its own: the labels
+// associated with it and, for a notification rule, the endpoint the rule notifies.
+func (s *Service) ExportResource(ctx context.Context, kind Kind, id influxdb.ID) (*Pkg, error) {
+	r := ResourceToClone{Kind: kind, ID: id}
+	if err := r.OK(); err != nil {
+		return nil, failedValidationErr(err)
+	}
+	return s.CreatePkg(ctx, CreateWithExistingResources(r))
+}
+
+// ExportStream exports the resources identified by the setters, in the same manner as
+// CreatePkg, writing each object to the writer as it is cloned. The objects are written
+// in the order they are cloned, and the full set of objects is never held in memory.
+// Unlike CreatePkg, the resulting pkg is not validated.
+func (s *Service) ExportStream(ctx context.Context, w io.Writer, encoding Encoding, setters ...CreatePkgSetFn) error {
+	opt := new(CreateOpt)
+	for _, setter := range setters {
+		if err := setter(opt); err != nil {
+			return err
 		}
+	}
 
-		if err := exporter.Export(ctx, resourcesToClone, orgIDOpt.LabelNames...); err != nil {
-			return nil, internalErr(err)
+	enc, err := newObjectStreamEncoder(w, encoding)
+	if err != nil {
+		return failedValidationErr(err)
+	}
+
+	exporter := newResourceExporter(s)
+	exporter.streamFn = enc.Encode
+	if err := s.export(ctx, exporter, *opt); err != nil {
+		return err
+	}
+
+	if err := enc.Close(); err != nil {
+		return internalErr(err)
+	}
+	return nil
+}
+
+func (s *Service) export(ctx context.Context, exporter *resourceExporter, opt CreateOpt) error {
+	orgsResources, err := s.cloneOrgsResources(ctx, opt.OrgIDs)
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return internalErr(err)
+	}
+
+	// the orgs are exported in the order they were provided, regardless of the order
+	// their resources were found in. This keeps the names the exporter gives to the
+	// objects, and the order they are streamed in, deterministic.
+	for i, orgIDOpt := range opt.OrgIDs {
+		if err := exporter.Export(ctx, orgsResources[i], orgIDOpt.LabelNames...); err != nil {
+			return internalErr(err)
 		}
 	}
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if err := exporter.Export(ctx, opt.Resources); err != nil {
-		return nil, internalErr(err)
+		return internalErr(err)
 	}
+	return nil
+}
 
-	pkg := &Pkg{Objects: exporter.Objects()}
-	if err := pkg.Validate(ValidWithoutResources()); err != nil {
-		return nil, failedValidationErr(err)
+// cloneOrgsResources finds the resources to clone for each of the orgs concurrently,
+// limiting the number of orgs in flight by the apply request limit. The clone calls
+// of every org share a single limit as well, keeping the total number of in flight
+// calls within the apply request limit. The resources are returned in the order of
+// the orgs provided. The first error encountered cancels all outstanding calls.
+func (s *Service) cloneOrgsResources(ctx context.Context, orgIDOpts []CreateByOrgIDOpt) ([][]ResourceToClone, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
 	}
 
-	return pkg, nil
+	limit := s.applyReqLimit
+	if limit < 1 {
+		limit = 1
+	}
+	orgSem, callSem := make(chan struct{}, limit), make(chan struct{}, limit)
+
+	results := make([][]ResourceToClone, len(orgIDOpts))
+	wg := new(sync.WaitGroup)
+	for i := range orgIDOpts {
+		select {
+		case <-ctx.Done():
+		case orgSem <- struct{}{}:
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					wg.Done()
+					<-orgSem
+				}()
+
+				resources, err := s.cloneOrgResources(ctx, callSem, orgIDOpts[i])
+				if err != nil {
+					setErr(err)
+					return
+				}
+				results[i] = resources
+			}(i)
+		}
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
 }
 
-func (s *Service) cloneOrgResources(ctx context.Context, orgID influxdb.ID, resourceKinds []Kind) ([]ResourceToClone, error) {
-	var resources []ResourceToClone
-	for _, resGen := range s.filterOrgResourceKinds(resourceKinds) {
-		existingResources, err := resGen.cloneFn(ctx, orgID)
-		if err != nil {
-			return nil, ierrors.Wrap(err, "finding "+string(resGen.resType))
+// cloneOrgResources fans out the clone calls for each resource kind, limiting the
+// number of in flight calls by the provided semaphore. The first error encountered
+// cancels all outstanding calls.
+func (s *Service) cloneOrgResources(ctx context.Context, sem chan struct{}, orgIDOpt CreateByOrgIDOpt) ([]ResourceToClone, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	orgID := orgIDOpt.OrgID
+	resGens := s.filterOrgResourceKinds(orgIDOpt)
+
+	var (
+		mu       sync.Mutex
+		firstErr error
+	)
+	setErr := func(err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr == nil {
+			firstErr = err
+			cancel()
+		}
+	}
+
+	results := make([][]ResourceToClone, len(resGens))
+	wg := new(sync.WaitGroup)
+	for i := range resGens {
+		select {
+		case <-ctx.Done():
+		case sem <- struct{}{}:
+			wg.Add(1)
+			go func(i int) {
+				defer func() {
+					wg.Done()
+					<-sem
+				}()
+
+				ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+				defer cancel()
+
+				resGen := resGens[i]
+				existingResources, err := resGen.cloneFn(ctx, orgID)
+				if err != nil {
+					setErr(ierrors.Wrap(err, "finding "+string(resGen.resType)))
+					return
+				}
+				results[i] = existingResources
+			}(i)
 		}
-		resources = append(resources, existingResources...)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
 
+	var resources []ResourceToClone
+	for _, res := range results {
+		resources = append(resources, res...)
+	}
 	return resources, nil
 }
 
@@ -439,20 +845,39 @@ func (s *Service) cloneOrgDashboards(ctx context.Context, orgID influxdb.ID) ([]
 	return resources, nil
 }
 
+// cloneListPageSize is the page size used to list the labels and variables of an
+// org being cloned. The pages are listed until exhausted.
+const cloneListPageSize = 100
+
 func (s *Service) cloneOrgLabels(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
-	labels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
-		OrgID: &orgID,
-	}, influxdb.FindOptions{Limit: 10000})
-	if err != nil {
-		return nil, ierrors.Wrap(err, "finding labels")
-	}
+	resources := make([]ResourceToClone, 0)
+	seen := make(map[influxdb.ID]bool)
+	for offset := 0; ; offset += cloneListPageSize {
+		labels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
+			OrgID: &orgID,
+		}, influxdb.FindOptions{Limit: cloneListPageSize, Offset: offset})
+		if err != nil {
+			return nil, ierrors.Wrap(err, "finding labels")
+		}
 
-	resources := make([]ResourceToClone, 0, len(labels))
-	for _, l := range labels {
-		resources = append(resources, ResourceToClone{
-			Kind: KindLabel,
-			ID:   l.ID,
-		})
+		var added int
+		for _, l := range labels {
+			if seen[l.ID] {
+				continue
+			}
+			seen[l.ID] = true
+			added++
+			resources = append(resources, ResourceToClone{
+				Kind: KindLabel,
+				ID:   l.ID,
+			})
+		}
+
+		// a service that disregards the paging options returns the same labels
+		// for every page, the lack of new labels guards against listing forever.
+		if len(labels) < cloneListPageSize || added == 0 {
+			break
+		}
 	}
 	return resources, nil
 }
@@ -493,7 +918,7 @@ func (s *Service) cloneOrgNotificationRules(ctx context.Context, orgID influxdb.
 	return resources, nil
 }
 
-func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID, includeBackingTasks bool) ([]ResourceToClone, error) {
 	tasks, _, err := s.taskSVC.FindTasks(ctx, influxdb.TaskFilter{OrganizationID: &orgID})
 	if err != nil {
 		return nil, err
@@ -503,6 +928,14 @@ func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]Resou
 		return nil, nil
 	}
 
+	mTasks := make(map[influxdb.ID]*influxdb.Task)
+	if includeBackingTasks {
+		for _, t := range tasks {
+			mTasks[t.ID] = t
+		}
+		return tasksToClone(mTasks), nil
+	}
+
 	checks, _, err := s.checkSVC.FindChecks(ctx, influxdb.CheckFilter{
 		OrgID: &orgID,
 	})
@@ -517,7 +950,6 @@ func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]Resou
 		return nil, err
 	}
 
-	mTasks := make(map[influxdb.ID]*influxdb.Task)
 	for i := range tasks {
 		t := tasks[i]
 		if t.Type != influxdb.TaskSystemType {
@@ -525,6 +957,7 @@ func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]Resou
 		}
 		mTasks[t.ID] = t
 	}
+
 	for _, c := range checks {
 		delete(mTasks, c.GetTaskID())
 	}
@@ -532,6 +965,10 @@ func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]Resou
 		delete(mTasks, r.GetTaskID())
 	}
 
+	return tasksToClone(mTasks), nil
+}
+
+func tasksToClone(mTasks map[influxdb.ID]*influxdb.Task) []ResourceToClone {
 	resources := make([]ResourceToClone, 0, len(mTasks))
 	for _, t := range mTasks {
 		resources = append(resources, ResourceToClone{
@@ -539,7 +976,7 @@ func (s *Service) cloneOrgTasks(ctx context.Context, orgID influxdb.ID) ([]Resou
 			ID:   t.ID,
 		})
 	}
-	return resources, nil
+	return resources
 }
 
 func (s *Service) cloneOrgTelegrafs(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
@@ -559,19 +996,32 @@ func (s *Service) cloneOrgTelegrafs(ctx context.Context, orgID influxdb.ID) ([]R
 }
 
 func (s *Service) cloneOrgVariables(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
-	vars, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
-		OrganizationID: &orgID,
-	}, influxdb.FindOptions{Limit: 10000})
-	if err != nil {
-		return nil, err
-	}
+	resources := make([]ResourceToClone, 0)
+	seen := make(map[influxdb.ID]bool)
+	for offset := 0; ; offset += cloneListPageSize {
+		vars, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
+			OrganizationID: &orgID,
+		}, influxdb.FindOptions{Limit: cloneListPageSize, Offset: offset})
+		if err != nil {
+			return nil, err
+		}
 
-	resources := make([]ResourceToClone, 0, len(vars))
-	for _, v := range vars {
-		resources = append(resources, ResourceToClone{
-			Kind: KindVariable,
-			ID:   v.ID,
-		})
+		var added int
+		for _, v := range vars {
+			if seen[v.ID] {
+				continue
+			}
+			seen[v.ID] = true
+			added++
+			resources = append(resources, ResourceToClone{
+				Kind: KindVariable,
+				ID:   v.ID,
+			})
+		}
+
+		if len(vars) < cloneListPageSize || added == 0 {
+			break
+		}
 	}
 
 	return resources, nil
@@ -579,10 +1029,14 @@ func (s *Service) cloneOrgVariables(ctx context.Context, orgID influxdb.ID) ([]R
 
 type cloneResFn func(context.Context, influxdb.ID) ([]ResourceToClone, error)
 
-func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []struct {
+func (s *Service) filterOrgResourceKinds(orgIDOpt CreateByOrgIDOpt) []struct {
 	resType influxdb.ResourceType
 	cloneFn cloneResFn
 } {
+	cloneOrgTasks := func(ctx context.Context, orgID influxdb.ID) ([]ResourceToClone, error) {
+		return s.cloneOrgTasks(ctx, orgID, orgIDOpt.IncludeBackingTasks)
+	}
+
 	mKinds := map[Kind]cloneResFn{
 		KindBucket:               s.cloneOrgBuckets,
 		KindCheck:                s.cloneOrgChecks,
@@ -590,10 +1044,15 @@ func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []struct {
 		KindLabel:                s.cloneOrgLabels,
 		KindNotificationEndpoint: s.cloneOrgNotificationEndpoints,
 		KindNotificationRule:     s.cloneOrgNotificationRules,
-		KindTask:                 s.cloneOrgTasks,
+		KindTask:                 cloneOrgTasks,
 		KindTelegraf:             s.cloneOrgTelegrafs,
 		KindVariable:             s.cloneOrgVariables,
 	}
+	for k, funcs := range s.registeredKinds() {
+		if funcs.Clone != nil {
+			mKinds[k] = funcs.Clone
+		}
+	}
 
 	newResGen := func(resType influxdb.ResourceType, cloneFn cloneResFn) struct {
 		resType influxdb.ResourceType
@@ -612,73 +1071,107 @@ func (s *Service) filterOrgResourceKinds(resourceKindFilters []Kind) []struct {
 		resType influxdb.ResourceType
 		cloneFn cloneResFn
 	}
-	if len(resourceKindFilters) == 0 {
+	if len(orgIDOpt.ResourceKinds) == 0 {
 		for k, cloneFn := range mKinds {
-			resourceTypeGens = append(resourceTypeGens, newResGen(k.ResourceType(), cloneFn))
+			resourceTypeGens = append(resourceTypeGens, newResGen(kindResourceType(k), cloneFn))
 		}
 		return resourceTypeGens
 	}
 
 	seenKinds := make(map[Kind]bool)
-	for _, k := range resourceKindFilters {
+	for _, k := range orgIDOpt.ResourceKinds {
 		cloneFn, ok := mKinds[k]
 		if !ok || seenKinds[k] {
 			continue
 		}
 		seenKinds[k] = true
-		resourceTypeGens = append(resourceTypeGens, newResGen(k.ResourceType(), cloneFn))
+		resourceTypeGens = append(resourceTypeGens, newResGen(kindResourceType(k), cloneFn))
 	}
 
 	return resourceTypeGens
 }
 
+// kindResourceType returns the resource type of the kind, falling back to the kind
+// itself for the registered kinds that have no resource type.
+func kindResourceType(k Kind) influxdb.ResourceType {
+	if resType := k.ResourceType(); resType != "" {
+		return resType
+	}
+	return influxdb.ResourceType(k)
+}
+
 // DryRun provides a dry run of the pkg application. The pkg will be marked verified
 // for later calls to Apply. This func will be run on an Apply if it has not been run
 // already.
 func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (Summary, Diff, error) {
-	// so here's the deal, when we have issues with the parsing validation, we
-	// continue to do the diff anyhow. any resource that does not have a name
-	// will be skipped, and won't bleed into the dry run here. We can now return
-	// a error (parseErr) and valid diff/summary.
-	var parseErr error
-	if !pkg.isParsed {
-		err := pkg.Validate()
-		if err != nil && !IsParseErr(err) {
-			return Summary{}, Diff{}, internalErr(err)
-		}
-		parseErr = err
+	pkg, opt, parseErr, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return Summary{}, Diff{}, err
 	}
 
-	var opt ApplyOpt
-	for _, o := range opts {
-		if err := o(&opt); err != nil {
-			return Summary{}, Diff{}, internalErr(err)
-		}
+	if err := s.dryRunSecrets(ctx, orgID, pkg); err != nil {
+		return Summary{}, Diff{}, err
 	}
 
-	if len(opt.EnvRefs) > 0 {
-		err := pkg.applyEnvRefs(opt.EnvRefs)
-		if err != nil && !IsParseErr(err) {
-			return Summary{}, Diff{}, internalErr(err)
-		}
-		parseErr = err
+	// the org labels are fetched once and shared by the label and label
+	// mapping dry runs.
+	labels := s.findOrgLabels(ctx, orgID)
+
+	existingIDs := opt.ExistingResourceIDs
+
+	diffBuckets, err := s.dryRunBuckets(ctx, orgID, pkg, existingIDs)
+	if err != nil {
+		return Summary{}, Diff{}, err
 	}
 
-	if err := s.dryRunSecrets(ctx, orgID, pkg); err != nil {
+	diffChecks, err := s.dryRunChecks(ctx, orgID, pkg, existingIDs)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	diffLabels, err := s.dryRunLabels(ctx, orgID, pkg, labels, existingIDs)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	// the org variables are fetched once and matched to the pkg variables in
+	// memory, unless the pkg variables are found by name.
+	var orgVars []*influxdb.Variable
+	if _, ok := s.varSVC.(VariableByNameFinder); !ok && len(pkg.variables()) > 0 {
+		orgVars = s.findOrgVariables(ctx, orgID)
+	}
+
+	diffVars, err := s.dryRunVariables(ctx, orgID, pkg, orgVars, opt.CaseInsensitiveVariables, existingIDs)
+	if err != nil {
 		return Summary{}, Diff{}, err
 	}
 
 	diff := Diff{
-		Buckets:    s.dryRunBuckets(ctx, orgID, pkg),
-		Checks:     s.dryRunChecks(ctx, orgID, pkg),
-		Dashboards: s.dryRunDashboards(pkg),
-		Labels:     s.dryRunLabels(ctx, orgID, pkg),
-		Tasks:      s.dryRunTasks(pkg),
-		Telegrafs:  s.dryRunTelegraf(pkg),
-		Variables:  s.dryRunVariables(ctx, orgID, pkg),
+		Buckets:   diffBuckets,
+		Checks:    diffChecks,
+		Labels:    diffLabels,
+		Variables: diffVars,
+	}
+
+	diffDashboards, err := s.dryRunDashboards(ctx, orgID, pkg, opt.StackID, existingIDs)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+	diff.Dashboards = diffDashboards
+
+	diffTasks, err := s.dryRunTasks(ctx, orgID, pkg)
+	if err != nil {
+		return Summary{}, Diff{}, err
+	}
+	diff.Tasks = diffTasks
+
+	diffTeles, err := s.dryRunTelegraf(ctx, orgID, pkg)
+	if err != nil {
+		return Summary{}, Diff{}, err
 	}
+	diff.Telegrafs = diffTeles
 
-	diffEndpoints, err := s.dryRunNotificationEndpoints(ctx, orgID, pkg)
+	diffEndpoints, err := s.dryRunNotificationEndpoints(ctx, orgID, pkg, existingIDs)
 	if err != nil {
 		return Summary{}, Diff{}, err
 	}
@@ -690,24 +1183,333 @@ func (s *Service) DryRun(ctx context.Context, orgID, userID influxdb.ID, pkg *Pk
 	}
 	diff.NotificationRules = diffRules
 
-	diffLabelMappings, err := s.dryRunLabelMappings(ctx, pkg)
+	diffLabelMappings, err := s.dryRunLabelMappings(ctx, pkg, labels, opt.StackID)
 	if err != nil {
 		return Summary{}, Diff{}, err
 	}
 	diff.LabelMappings = diffLabelMappings
 
-	// verify the pkg is verified by a dry run. when calling Service.Apply this
-	// is required to have been run. if it is not true, then apply runs
-	// the Dry run.
-	pkg.isVerified = true
-	return pkg.Summary(), diff, parseErr
+	if err := s.dryRunRegisteredKinds(ctx, orgID, pkg); err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	if err := s.checkApplyQuota(ctx, orgID, diff); err != nil {
+		return Summary{}, Diff{}, err
+	}
+
+	// verify the pkg is verified by a dry run. when calling Service.Apply this
+	// is required to have been run. if it is not true, then apply runs
+	// the Dry run.
+	pkg.isVerified = true
+	return pkg.Summary(), diff, parseErr
+}
+
+// prepareDryRun readies the pkg to be diffed with the platform per the options. The
+// pkg returned is a copy of the pkg provided when it is transformed or its names are
+// prefixed.
+func prepareDryRun(pkg *Pkg, opts []ApplyOptFn) (_ *Pkg, opt ApplyOpt, parseErr error, err error) {
+	// so here's the deal, when we have issues with the parsing validation, we
+	// continue to do the diff anyhow. any resource that does not have a name
+	// will be skipped, and won't bleed into the dry run here. We can now return
+	// a error (parseErr) and valid diff/summary.
+	if !pkg.isParsed {
+		err := pkg.Validate()
+		if err != nil && !IsParseErr(err) {
+			return nil, ApplyOpt{}, nil, internalErr(err)
+		}
+		parseErr = err
+	}
+
+	for _, o := range opts {
+		if err := o(&opt); err != nil {
+			return nil, ApplyOpt{}, nil, internalErr(err)
+		}
+	}
+
+	if len(opt.Transforms) > 0 {
+		transformed, err := pkg.transform(opt.Transforms...)
+		if err != nil && !IsParseErr(err) {
+			return nil, ApplyOpt{}, nil, failedValidationErr(err)
+		}
+		pkg, parseErr = transformed, err
+	}
+
+	if opt.StrictNames {
+		if err := unnamedObjectsErr(pkg); err != nil {
+			return nil, ApplyOpt{}, nil, err
+		}
+	}
+
+	if len(opt.EnvRefs) > 0 {
+		err := pkg.applyEnvRefs(opt.EnvRefs)
+		if err != nil && !IsParseErr(err) {
+			return nil, ApplyOpt{}, nil, internalErr(err)
+		}
+		parseErr = err
+	}
+
+	if opt.NamePrefix != "" {
+		prefixed, err := pkg.withNamePrefix(opt.NamePrefix)
+		if err != nil && !IsParseErr(err) {
+			return nil, ApplyOpt{}, nil, internalErr(err)
+		}
+		pkg, parseErr = prefixed, err
+	}
+
+	if opt.BucketRetentionOverride != nil {
+		overridden, err := pkg.withBucketRetention(*opt.BucketRetentionOverride)
+		if err != nil && !IsParseErr(err) {
+			return nil, ApplyOpt{}, nil, internalErr(err)
+		}
+		pkg, parseErr = overridden, err
+	}
+
+	return pkg, opt, parseErr, nil
+}
+
+// DryRunBuckets diffs the buckets of the pkg with the buckets of the org, without
+// diffing the rest of the pkg as DryRun does. The pkg is not marked verified.
+func (s *Service) DryRunBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffBucket, error) {
+	pkg, opt, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunBuckets(ctx, orgID, pkg, opt.ExistingResourceIDs)
+}
+
+// DryRunChecks diffs the checks of the pkg with the checks of the org, without
+// diffing the rest of the pkg as DryRun does. The pkg is not marked verified.
+func (s *Service) DryRunChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffCheck, error) {
+	pkg, opt, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunChecks(ctx, orgID, pkg, opt.ExistingResourceIDs)
+}
+
+// DryRunDashboards diffs the dashboards of the pkg with the dashboards of the org,
+// without diffing the rest of the pkg as DryRun does. The pkg is not marked verified.
+func (s *Service) DryRunDashboards(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffDashboard, error) {
+	pkg, opt, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunDashboards(ctx, orgID, pkg, opt.StackID, opt.ExistingResourceIDs)
+}
+
+// DryRunLabels diffs the labels of the pkg with the labels of the org, without
+// diffing the rest of the pkg as DryRun does. The pkg is not marked verified.
+func (s *Service) DryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffLabel, error) {
+	pkg, opt, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunLabels(ctx, orgID, pkg, s.findOrgLabels(ctx, orgID), opt.ExistingResourceIDs)
+}
+
+// DryRunNotificationEndpoints diffs the notification endpoints of the pkg with the
+// notification endpoints of the org, without diffing the rest of the pkg as DryRun
+// does. The pkg is not marked verified.
+func (s *Service) DryRunNotificationEndpoints(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffNotificationEndpoint, error) {
+	pkg, opt, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunNotificationEndpoints(ctx, orgID, pkg, opt.ExistingResourceIDs)
+}
+
+// DryRunNotificationRules diffs the notification rules of the pkg with the
+// notification rules of the org, without diffing the rest of the pkg as DryRun
+// does. The pkg is not marked verified.
+func (s *Service) DryRunNotificationRules(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffNotificationRule, error) {
+	pkg, _, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunNotificationRules(ctx, orgID, pkg)
+}
+
+// DryRunTasks diffs the tasks of the pkg with the tasks of the org, without diffing
+// the rest of the pkg as DryRun does. The pkg is not marked verified.
+func (s *Service) DryRunTasks(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffTask, error) {
+	pkg, _, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunTasks(ctx, orgID, pkg)
+}
+
+// DryRunTelegrafs diffs the telegraf configs of the pkg with the telegraf configs of
+// the org, without diffing the rest of the pkg as DryRun does. The pkg is not marked
+// verified.
+func (s *Service) DryRunTelegrafs(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffTelegraf, error) {
+	pkg, _, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+	return s.dryRunTelegraf(ctx, orgID, pkg)
+}
+
+// DryRunVariables diffs the variables of the pkg with the variables of the org,
+// without diffing the rest of the pkg as DryRun does. The pkg is not marked verified.
+func (s *Service) DryRunVariables(ctx context.Context, orgID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) ([]DiffVariable, error) {
+	pkg, opt, _, err := prepareDryRun(pkg, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var orgVars []*influxdb.Variable
+	if _, ok := s.varSVC.(VariableByNameFinder); !ok && len(pkg.variables()) > 0 {
+		orgVars = s.findOrgVariables(ctx, orgID)
+	}
+	return s.dryRunVariables(ctx, orgID, pkg, orgVars, opt.CaseInsensitiveVariables, opt.ExistingResourceIDs)
+}
+
+// dryRunRegisteredKinds runs the dry run of every kind registered with RegisterKind,
+// in the order of the kinds.
+func (s *Service) dryRunRegisteredKinds(ctx context.Context, orgID influxdb.ID, pkg *Pkg) error {
+	kinds := s.registeredKinds()
+	sortedKinds := make([]Kind, 0, len(kinds))
+	for k := range kinds {
+		sortedKinds = append(sortedKinds, k)
+	}
+	sort.Slice(sortedKinds, func(i, j int) bool {
+		return sortedKinds[i] < sortedKinds[j]
+	})
+
+	for _, k := range sortedKinds {
+		dryRunFn := kinds[k].DryRun
+		if dryRunFn == nil {
+			continue
+		}
+		if err := dryRunFn(ctx, orgID, pkg); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Msg:  fmt.Sprintf("dry run of kind %q failed", k),
+				Err:  err,
+			}
+		}
+	}
+	return nil
 }
 
-func (s *Service) dryRunBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg) []DiffBucket {
+// unnamedObjectsErr returns an error identifying every object of the pkg that is
+// missing a name.
+func unnamedObjectsErr(pkg *Pkg) error {
+	var unnamed []string
+	for i, o := range pkg.Objects {
+		if strings.TrimSpace(o.Name()) == "" {
+			unnamed = append(unnamed, fmt.Sprintf("objects[%d] kind=%q", i, o.Kind))
+		}
+	}
+	if len(unnamed) == 0 {
+		return nil
+	}
+	return &influxdb.Error{
+		Code: influxdb.EUnprocessableEntity,
+		Msg:  fmt.Sprintf("pkg objects missing a name: [%s]", strings.Join(unnamed, "; ")),
+	}
+}
+
+func (s *Service) checkApplyQuota(ctx context.Context, orgID influxdb.ID, diff Diff) error {
+	if s.applyQuotaFn == nil {
+		return nil
+	}
+
+	var newBuckets, newChecks, newDashboards, newEndpoints, newLabels, newRules, newTasks, newTeles, newVars int
+	for _, b := range diff.Buckets {
+		if b.IsNew() {
+			newBuckets++
+		}
+	}
+	for _, c := range diff.Checks {
+		if c.IsNew() {
+			newChecks++
+		}
+	}
+	for _, d := range diff.Dashboards {
+		if d.IsNew() {
+			newDashboards++
+		}
+	}
+	for _, e := range diff.NotificationEndpoints {
+		if e.IsNew() {
+			newEndpoints++
+		}
+	}
+	for _, l := range diff.Labels {
+		if l.IsNew() {
+			newLabels++
+		}
+	}
+	for _, r := range diff.NotificationRules {
+		if r.IsNew() {
+			newRules++
+		}
+	}
+	for _, t := range diff.Tasks {
+		if t.IsNew() {
+			newTasks++
+		}
+	}
+	for _, t := range diff.Telegrafs {
+		if t.IsNew() {
+			newTeles++
+		}
+	}
+	for _, v := range diff.Variables {
+		if v.IsNew() {
+			newVars++
+		}
+	}
+
+	counts := []struct {
+		kind  Kind
+		count int
+	}{
+		{kind: KindBucket, count: newBuckets},
+		{kind: KindCheck, count: newChecks},
+		{kind: KindDashboard, count: newDashboards},
+		{kind: KindLabel, count: newLabels},
+		{kind: KindNotificationEndpoint, count: newEndpoints},
+		{kind: KindNotificationRule, count: newRules},
+		{kind: KindTask, count: newTasks},
+		{kind: KindTelegraf, count: newTeles},
+		{kind: KindVariable, count: newVars},
+	}
+	for _, c := range counts {
+		if c.count == 0 {
+			continue
+		}
+		if err := s.applyQuotaFn(ctx, orgID, c.kind, c.count); err != nil {
+			return &influxdb.Error{
+				Code: influxdb.EForbidden,
+				Msg:  fmt.Sprintf("pkg exceeds quota for %d new %s resources", c.count, c.kind),
+				Err:  err,
+			}
+		}
+	}
+	return nil
+}
+
+func (s *Service) dryRunBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg, existingIDs map[string]influxdb.ID) ([]DiffBucket, error) {
 	mExistingBkts := make(map[string]DiffBucket)
 	bkts := pkg.buckets()
 	for i := range bkts {
 		b := bkts[i]
+		if id, ok := existingIDs[b.PkgName()]; ok {
+			existingBkt, err := s.bucketSVC.FindBucketByID(ctx, id)
+			if err == nil && existingBkt.OrgID != orgID {
+				err = &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			if err != nil {
+				return nil, existingResourceErr(KindBucket, b.PkgName(), id, err)
+			}
+			b.existing = existingBkt
+			mExistingBkts[b.Name()] = newDiffBucket(b, existingBkt)
+			continue
+		}
+
 		existingBkt, err := s.bucketSVC.FindBucketByName(ctx, orgID, b.Name())
 		switch err {
 		// TODO: case for err not found here and another case handle where
@@ -728,14 +1530,27 @@ func (s *Service) dryRunBuckets(ctx context.Context, orgID influxdb.ID, pkg *Pkg
 		return diffs[i].Name < diffs[j].Name
 	})
 
-	return diffs
+	return diffs, nil
 }
 
-func (s *Service) dryRunChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg) []DiffCheck {
+func (s *Service) dryRunChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg, existingIDs map[string]influxdb.ID) ([]DiffCheck, error) {
 	mExistingChecks := make(map[string]DiffCheck)
 	checks := pkg.checks()
 	for i := range checks {
 		c := checks[i]
+		if id, ok := existingIDs[c.PkgName()]; ok {
+			existingCheck, err := s.checkSVC.FindCheckByID(ctx, id)
+			if err == nil && existingCheck.GetOrgID() != orgID {
+				err = &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			if err != nil {
+				return nil, existingResourceErr(KindCheck, c.PkgName(), id, err)
+			}
+			c.existing = existingCheck
+			mExistingChecks[c.Name()] = s.newExistingDiffCheck(ctx, c, existingCheck)
+			continue
+		}
+
 		name := c.Name()
 		existingCheck, err := s.checkSVC.FindCheck(ctx, influxdb.CheckFilter{
 			Name:  &name,
@@ -744,7 +1559,7 @@ func (s *Service) dryRunChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg)
 		switch err {
 		case nil:
 			c.existing = existingCheck
-			mExistingChecks[c.Name()] = newDiffCheck(c, existingCheck)
+			mExistingChecks[c.Name()] = s.newExistingDiffCheck(ctx, c, existingCheck)
 		default:
 			mExistingChecks[c.Name()] = newDiffCheck(c, nil)
 		}
@@ -758,38 +1573,128 @@ func (s *Service) dryRunChecks(ctx context.Context, orgID influxdb.ID, pkg *Pkg)
 		return diffs[i].Name < diffs[j].Name
 	})
 
-	return diffs
+	return diffs, nil
+}
+
+// newExistingDiffCheck provides the diff of a pkg check with an existing check. The
+// status of the existing check is not part of the check itself, it is provided by
+// the check's task.
+func (s *Service) newExistingDiffCheck(ctx context.Context, c *check, existing influxdb.Check) DiffCheck {
+	diff := newDiffCheck(c, existing)
+	diff.OldDetails.Status = s.checkStatus(ctx, existing)
+	return diff
+}
+
+func (s *Service) checkStatus(ctx context.Context, c influxdb.Check) influxdb.Status {
+	t, err := s.taskSVC.FindTaskByID(ctx, c.GetTaskID())
+	if err != nil || t == nil || t.Status == "" {
+		return influxdb.Active
+	}
+	return influxdb.Status(t.Status)
 }
 
-func (s *Service) dryRunDashboards(pkg *Pkg) []DiffDashboard {
+func (s *Service) dryRunDashboards(ctx context.Context, orgID influxdb.ID, pkg *Pkg, stackID influxdb.ID, existingIDs map[string]influxdb.ID) ([]DiffDashboard, error) {
 	dashs := pkg.dashboards()
 
+	// dashboard names are not unique within an org, so the only way to match
+	// a pkg dashboard to an existing one is by way of a prior stack application.
+	mStackDashIDs := make(map[string]influxdb.ID)
+	if stackID != 0 && len(dashs) > 0 {
+		stack, err := s.store.ReadStackByID(ctx, stackID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return nil, err
+			}
+			return nil, internalErr(err)
+		}
+		for _, r := range stack.Resources {
+			if r.Kind.is(KindDashboard) {
+				mStackDashIDs[r.Name] = r.ID
+			}
+		}
+	}
+
 	diffs := make([]DiffDashboard, 0, len(dashs))
 	for _, d := range dashs {
+		if id, ok := existingIDs[d.PkgName()]; ok {
+			existing, err := s.dashSVC.FindDashboardByID(ctx, id)
+			if err == nil && existing.OrganizationID != orgID {
+				err = &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			if err != nil {
+				return nil, existingResourceErr(KindDashboard, d.PkgName(), id, err)
+			}
+			d.existing = existing
+			diffs = append(diffs, newDiffDashboard(d))
+			continue
+		}
+
+		if id, ok := mStackDashIDs[d.PkgName()]; ok {
+			existing, err := s.dashSVC.FindDashboardByID(ctx, id)
+			switch {
+			case err == nil:
+				d.existing = existing
+			case influxdb.ErrorCode(err) != influxdb.ENotFound:
+				return nil, internalErr(err)
+			}
+		}
 		diffs = append(diffs, newDiffDashboard(d))
 	}
-	return diffs
+	return diffs, nil
+}
+
+// labelCache is a lookup of an org's labels by name.
+type labelCache map[string]*influxdb.Label
+
+func (c labelCache) labelID(name string) influxdb.ID {
+	if l, ok := c[name]; ok {
+		return l.ID
+	}
+	return 0
 }
 
-func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg) []DiffLabel {
+func (s *Service) findOrgLabels(ctx context.Context, orgID influxdb.ID) labelCache {
+	existingLabels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
+		OrgID: &orgID,
+	}) // grab em all
+	if err != nil {
+		// TODO: case for err not found here and another case handle where
+		//  err isn't a not found (some other error)
+		return labelCache{}
+	}
+
+	cache := make(labelCache, len(existingLabels))
+	for _, l := range existingLabels {
+		cache[l.Name] = l
+	}
+	return cache
+}
+
+func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg, orgLabels labelCache, existingIDs map[string]influxdb.ID) ([]DiffLabel, error) {
 	mExistingLabels := make(map[string]DiffLabel)
 	labels := pkg.labels()
 	for i := range labels {
 		pkgLabel := labels[i]
-		existingLabels, err := s.labelSVC.FindLabels(ctx, influxdb.LabelFilter{
-			Name:  pkgLabel.Name(),
-			OrgID: &orgID,
-		}, influxdb.FindOptions{Limit: 1})
-		switch {
-		// TODO: case for err not found here and another case handle where
-		//  err isn't a not found (some other error)
-		case err == nil && len(existingLabels) > 0:
-			existingLabel := existingLabels[0]
+		if id, ok := existingIDs[pkgLabel.PkgName()]; ok {
+			existingLabel, err := s.labelSVC.FindLabelByID(ctx, id)
+			if err == nil && existingLabel.OrgID != orgID {
+				err = &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			if err != nil {
+				return nil, existingResourceErr(KindLabel, pkgLabel.PkgName(), id, err)
+			}
 			pkgLabel.existing = existingLabel
 			mExistingLabels[pkgLabel.Name()] = newDiffLabel(pkgLabel, existingLabel)
-		default:
+			continue
+		}
+
+		existingLabel, ok := orgLabels[pkgLabel.Name()]
+		if !ok {
 			mExistingLabels[pkgLabel.Name()] = newDiffLabel(pkgLabel, nil)
+			continue
 		}
+		pkgLabel.existing = existingLabel
+		mExistingLabels[pkgLabel.Name()] = newDiffLabel(pkgLabel, existingLabel)
 	}
 
 	diffs := make([]DiffLabel, 0, len(mExistingLabels))
@@ -800,10 +1705,10 @@ func (s *Service) dryRunLabels(ctx context.Context, orgID influxdb.ID, pkg *Pkg)
 		return diffs[i].Name < diffs[j].Name
 	})
 
-	return diffs
+	return diffs, nil
 }
 
-func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffNotificationEndpoint, error) {
+func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID influxdb.ID, pkg *Pkg, existingIDs map[string]influxdb.ID) ([]DiffNotificationEndpoint, error) {
 	existingEndpoints, _, err := s.endpointSVC.FindNotificationEndpoints(ctx, influxdb.NotificationEndpointFilter{
 		OrgID: &orgID,
 	}) // grab em all
@@ -822,6 +1727,19 @@ func (s *Service) dryRunNotificationEndpoints(ctx context.Context, orgID influxd
 	for i := range endpoints {
 		newEndpoint := endpoints[i]
 
+		if id, ok := existingIDs[newEndpoint.PkgName()]; ok {
+			iExisting, err := s.endpointSVC.FindNotificationEndpointByID(ctx, id)
+			if err == nil && iExisting.GetOrgID() != orgID {
+				err = &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			if err != nil {
+				return nil, existingResourceErr(KindNotificationEndpoint, newEndpoint.PkgName(), id, err)
+			}
+			newEndpoint.existing = iExisting
+			mExistingToNew[newEndpoint.Name()] = newDiffNotificationEndpoint(newEndpoint, iExisting)
+			continue
+		}
+
 		var existing influxdb.NotificationEndpoint
 		if iExisting, ok := mExisting[newEndpoint.Name()]; ok {
 			newEndpoint.existing = iExisting
@@ -849,18 +1767,39 @@ func (s *Service) dryRunNotificationRules(ctx context.Context, orgID influxdb.ID
 		return nil, internalErr(err)
 	}
 	mExisting := make(map[string]influxdb.NotificationEndpoint)
+	mExistingByID := make(map[influxdb.ID]influxdb.NotificationEndpoint)
 	for _, e := range iEndpoints {
 		mExisting[e.GetName()] = e
+		mExistingByID[e.GetID()] = e
 	}
 
+	// a rule refers to an endpoint of the pkg by its pkg name, or by its name
+	// when the names of the pkg resources are prefixed.
 	mPkgEndpoints := make(map[string]influxdb.NotificationEndpoint)
 	for _, e := range pkg.mNotificationEndpoints {
 		influxEndpoint := e.summarize().NotificationEndpoint
 		mPkgEndpoints[e.PkgName()] = influxEndpoint
+		mPkgEndpoints[e.Name()] = influxEndpoint
+	}
+
+	iRules, _, err := s.ruleSVC.FindNotificationRules(ctx, influxdb.NotificationRuleFilter{
+		OrgID: &orgID,
+	}) // grab em all
+	if err != nil {
+		return nil, internalErr(err)
+	}
+	mExistingRules := make(map[string]influxdb.NotificationRule)
+	for _, r := range iRules {
+		mExistingRules[r.GetName()] = r
 	}
 
 	diffs := make([]DiffNotificationRule, 0, len(mExisting))
 	for _, r := range pkg.notificationRules() {
+		if existing, ok := mExistingRules[r.Name()]; ok {
+			r.existing = existing
+			r.existingStatus = s.notificationRuleStatus(ctx, existing)
+		}
+
 		e, ok := mExisting[r.endpointName.String()]
 		if !ok {
 			influxEndpoint, ok := mPkgEndpoints[r.endpointName.String()]
@@ -870,12 +1809,29 @@ func (s *Service) dryRunNotificationRules(ctx context.Context, orgID influxdb.ID
 			}
 			e = influxEndpoint
 		}
-		diffs = append(diffs, newDiffNotificationRule(r, e))
+		diff := newDiffNotificationRule(r, e)
+		if r.existing != nil {
+			diff.OldEndpointID = SafeID(r.existing.GetEndpointID())
+			if oldEndpoint, ok := mExistingByID[r.existing.GetEndpointID()]; ok {
+				diff.OldEndpointName = oldEndpoint.GetName()
+			}
+		}
+		diffs = append(diffs, diff)
 
 	}
 	return diffs, nil
 }
 
+// notificationRuleStatus provides the status of the rule's task. The status is
+// not part of the rule itself, and is required to restore a rule on rollback.
+func (s *Service) notificationRuleStatus(ctx context.Context, r influxdb.NotificationRule) influxdb.Status {
+	t, err := s.taskSVC.FindTaskByID(ctx, r.GetTaskID())
+	if err != nil || t == nil || t.Status == "" {
+		return influxdb.Active
+	}
+	return influxdb.Status(t.Status)
+}
+
 func (s *Service) dryRunSecrets(ctx context.Context, orgID influxdb.ID, pkg *Pkg) error {
 	pkgSecrets := pkg.mSecrets
 	if len(pkgSecrets) == 0 {
@@ -894,52 +1850,165 @@ func (s *Service) dryRunSecrets(ctx context.Context, orgID influxdb.ID, pkg *Pkg
 	return nil
 }
 
-func (s *Service) dryRunTasks(pkg *Pkg) []DiffTask {
-	var diffs []DiffTask
-	for _, t := range pkg.tasks() {
-		diffs = append(diffs, newDiffTask(t))
+func (s *Service) dryRunTasks(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffTask, error) {
+	tasks := pkg.tasks()
+	if len(tasks) == 0 {
+		return []DiffTask{}, nil
+	}
+
+	mExistingTasks, err := s.findOrgTasksByName(ctx, orgID)
+	if err != nil {
+		return nil, internalErr(err)
+	}
+
+	diffs := make([]DiffTask, 0, len(tasks))
+	for _, t := range tasks {
+		existing, ok := mExistingTasks[t.Name()]
+		if ok {
+			t.existing = existing
+		}
+		diffs = append(diffs, newDiffTask(t, t.existing))
 	}
-	return diffs
+	return diffs, nil
 }
 
-func (s *Service) dryRunTelegraf(pkg *Pkg) []DiffTelegraf {
+// findOrgTasksByName returns the system tasks for the org, keyed by name. Tasks
+// backing checks and notification rules are not included.
+func (s *Service) findOrgTasksByName(ctx context.Context, orgID influxdb.ID) (map[string]*influxdb.Task, error) {
+	const limit = 100
+
+	taskType := influxdb.TaskSystemType
+	filter := influxdb.TaskFilter{
+		Type:           &taskType,
+		OrganizationID: &orgID,
+		Limit:          limit,
+	}
+
+	mTasks := make(map[string]*influxdb.Task)
+	for {
+		tasks, _, err := s.taskSVC.FindTasks(ctx, filter)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range tasks {
+			mTasks[t.Name] = t
+		}
+		if len(tasks) < limit {
+			return mTasks, nil
+		}
+		lastID := tasks[len(tasks)-1].ID
+		filter.After = &lastID
+	}
+}
+
+func (s *Service) dryRunTelegraf(ctx context.Context, orgID influxdb.ID, pkg *Pkg) ([]DiffTelegraf, error) {
 	telegrafs := pkg.telegrafs()
+	if len(telegrafs) == 0 {
+		return []DiffTelegraf{}, nil
+	}
+
+	existingTeles, _, err := s.teleSVC.FindTelegrafConfigs(ctx, influxdb.TelegrafConfigFilter{OrgID: &orgID})
+	if err != nil {
+		return nil, internalErr(err)
+	}
+	mExistingTeles := make(map[string]*influxdb.TelegrafConfig, len(existingTeles))
+	for _, t := range existingTeles {
+		mExistingTeles[t.Name] = t
+	}
+
 	diffs := make([]DiffTelegraf, 0, len(telegrafs))
 	for _, t := range telegrafs {
-		diffs = append(diffs, newDiffTelegraf(t))
+		if existing, ok := mExistingTeles[t.Name()]; ok {
+			t.existing = existing
+		}
+		diffs = append(diffs, newDiffTelegraf(t, t.existing))
+	}
+	return diffs, nil
+}
+
+// VariableByNameFinder is an optional interface a variable service may implement
+// to look up a variable by its name within an org. When the variable service provided
+// to the Service implements it, pkg variables are matched to existing variables by
+// name without scanning the org's variables.
+type VariableByNameFinder interface {
+	FindVariableByName(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Variable, error)
+}
+
+// findOrgVariables returns the variables of the org the pkg variables are matched
+// to. No variables are returned when they cannot be found, the pkg variables are
+// then diffed as new.
+func (s *Service) findOrgVariables(ctx context.Context, orgID influxdb.ID) []*influxdb.Variable {
+	existingVars, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
+		OrganizationID: &orgID,
+		// names are unique for vars within an org, make large limit returned
+		// vars, should be more than enough for the time being.
+	}, influxdb.FindOptions{Limit: 100})
+	if err != nil {
+		return nil
 	}
-	return diffs
+	return existingVars
 }
 
-func (s *Service) dryRunVariables(ctx context.Context, orgID influxdb.ID, pkg *Pkg) []DiffVariable {
+func (s *Service) dryRunVariables(ctx context.Context, orgID influxdb.ID, pkg *Pkg, orgVars []*influxdb.Variable, caseInsensitive bool, existingIDs map[string]influxdb.ID) ([]DiffVariable, error) {
 	mExistingLabels := make(map[string]DiffVariable)
 	variables := pkg.variables()
 
-VarLoop:
+	// when the variables are found by name, the org variables are only
+	// fetched when opted into case insensitive matching, a variable not
+	// found by name is new without checking it for a name collision.
+	finder, hasFinder := s.varSVC.(VariableByNameFinder)
+	orgVarsFetched := !hasFinder
+
 	for i := range variables {
 		pkgVar := variables[i]
-		existingLabels, err := s.varSVC.FindVariables(ctx, influxdb.VariableFilter{
-			OrganizationID: &orgID,
-			// TODO: would be ideal to extend find variables to allow for a name matcher
-			//  since names are unique for vars within an org, meanwhile, make large limit
-			// 	returned vars, should be more than enough for the time being.
-		}, influxdb.FindOptions{Limit: 100})
-		switch {
-		case err == nil && len(existingLabels) > 0:
-			for i := range existingLabels {
-				existingVar := existingLabels[i]
-				if existingVar.Name != pkgVar.Name() {
-					continue
+
+		if id, ok := existingIDs[pkgVar.PkgName()]; ok {
+			existingVar, err := s.varSVC.FindVariableByID(ctx, id)
+			if err == nil && existingVar.OrganizationID != orgID {
+				err = &influxdb.Error{Code: influxdb.ENotFound}
+			}
+			if err != nil {
+				return nil, existingResourceErr(KindVariable, pkgVar.PkgName(), id, err)
+			}
+			pkgVar.existing = existingVar
+			mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, existingVar)
+			continue
+		}
+
+		var exactMatch, foldMatch *influxdb.Variable
+		if hasFinder {
+			existingVar, err := finder.FindVariableByName(ctx, orgID, pkgVar.Name())
+			if err == nil && existingVar != nil {
+				exactMatch = existingVar
+			}
+		}
+
+		if exactMatch == nil && (!hasFinder || caseInsensitive) {
+			if !orgVarsFetched {
+				orgVarsFetched = true
+				orgVars = s.findOrgVariables(ctx, orgID)
+			}
+			for _, existingVar := range orgVars {
+				switch {
+				case existingVar.Name == pkgVar.Name():
+					exactMatch = existingVar
+				case foldMatch == nil && strings.EqualFold(existingVar.Name, pkgVar.Name()):
+					foldMatch = existingVar
 				}
-				pkgVar.existing = existingVar
-				mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, existingVar)
-				continue VarLoop
 			}
-			// fallthrough here for when the variable is not found, it'll fall to the
-			// default case and add it as new.
-			fallthrough
+		}
+
+		switch {
+		case exactMatch != nil:
+			pkgVar.existing = exactMatch
+			mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, exactMatch)
+		case foldMatch != nil && caseInsensitive:
+			pkgVar.existing = foldMatch
+			mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, foldMatch)
 		default:
-			mExistingLabels[pkgVar.Name()] = newDiffVariable(pkgVar, nil)
+			diff := newDiffVariable(pkgVar, nil)
+			diff.NameCollision = foldMatch != nil
+			mExistingLabels[pkgVar.Name()] = diff
 		}
 	}
 
@@ -951,7 +2020,19 @@ VarLoop:
 		return diffs[i].Name < diffs[j].Name
 	})
 
-	return diffs
+	return diffs, nil
+}
+
+// existingResourceErr describes the failure to find the platform resource a pkg
+// resource was explicitly mapped to via ApplyWithExistingResourceIDs.
+func existingResourceErr(k Kind, pkgName string, id influxdb.ID, err error) error {
+	if influxdb.ErrorCode(err) != influxdb.ENotFound {
+		return internalErr(err)
+	}
+	return &influxdb.Error{
+		Code: influxdb.ENotFound,
+		Msg:  fmt.Sprintf("existing %s %q with id %q not found", k, pkgName, id),
+	}
 }
 
 type (
@@ -971,8 +2052,8 @@ type (
 	}
 )
 
-func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabelMapping, error) {
-	mappers := []labelMappers{
+func pkgLabelMappers(pkg *Pkg) []labelMappers {
+	return []labelMappers{
 		mapperBuckets(pkg.buckets()),
 		mapperChecks(pkg.checks()),
 		mapperDashboards(pkg.dashboards()),
@@ -982,16 +2063,54 @@ func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabe
 		mapperTelegrafs(pkg.telegrafs()),
 		mapperVariables(pkg.variables()),
 	}
+}
+
+// dryRunLabelMappings diffs the label mappings of the pkg resources with those of the
+// platform. When the pkg is applied to a stack, the resources of the stack are managed
+// by the pkg. A label mapped to a stack resource on the platform that is no longer
+// associated with the resource in the pkg is provided in the diff with NotInPkg
+// set. The apply does not remove the mapping.
+func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg, orgLabels labelCache, stackID influxdb.ID) ([]DiffLabelMapping, error) {
+	mStackResIDs := make(map[influxdb.ID]bool)
+	if stackID != 0 {
+		stack, err := s.store.ReadStackByID(ctx, stackID)
+		if err != nil {
+			if influxdb.ErrorCode(err) == influxdb.ENotFound {
+				return nil, err
+			}
+			return nil, internalErr(err)
+		}
+		for _, r := range stack.Resources {
+			mStackResIDs[r.ID] = true
+		}
+	}
 
 	diffs := make([]DiffLabelMapping, 0)
-	for _, mapper := range mappers {
+	for _, mapper := range pkgLabelMappers(pkg) {
 		for i := 0; i < mapper.Len(); i++ {
 			la := mapper.Association(i)
+			stackManaged := la.Exists() && mStackResIDs[la.ID()]
+			pkgLabels := labelSlcToMap(la.Labels())
 			err := s.dryRunResourceLabelMapping(ctx, la, func(labelID influxdb.ID, labelName string, isNew bool) {
+				if _, ok := pkgLabels[labelName]; stackManaged && !isNew && !ok {
+					diffs = append(diffs, DiffLabelMapping{
+						NotInPkg:  true,
+						ResType:   la.ResourceType(),
+						ResID:     SafeID(la.ID()),
+						ResName:   la.Name(),
+						LabelID:   SafeID(labelID),
+						LabelName: labelName,
+					})
+					return
+				}
+
 				existingLabel, ok := pkg.mLabels[labelName]
 				if !ok {
 					return
 				}
+				if labelID == 0 {
+					labelID = orgLabels.labelID(labelName)
+				}
 				existingLabel.setMapping(la, !isNew)
 				diffs = append(diffs, DiffLabelMapping{
 					IsNew:     isNew,
@@ -1008,7 +2127,27 @@ func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabe
 		}
 	}
 
-	// sort by res type ASC, then res name ASC, then label name ASC
+	sortDiffLabelMappings(diffs)
+
+	return diffs, nil
+}
+
+// DryRunLabelMappings diffs the label mappings of the pkg with those of the platform,
+// without diffing any of the pkg's resources. The resources are taken to exist as
+// matched by a prior dry run of the pkg; the label mappings of a resource that has
+// not been matched to an existing resource are all new.
+func (s *Service) DryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabelMapping, error) {
+	if !pkg.isParsed {
+		if err := pkg.Validate(); err != nil {
+			return nil, failedValidationErr(err)
+		}
+	}
+
+	return s.dryRunLabelMappings(ctx, pkg, labelCache{}, 0)
+}
+
+// sortDiffLabelMappings sorts by res type ASC, then res name ASC, then label name ASC.
+func sortDiffLabelMappings(diffs []DiffLabelMapping) {
 	sort.Slice(diffs, func(i, j int) bool {
 		n, m := diffs[i], diffs[j]
 		if n.ResType < m.ResType {
@@ -1025,8 +2164,6 @@ func (s *Service) dryRunLabelMappings(ctx context.Context, pkg *Pkg) ([]DiffLabe
 		}
 		return n.LabelName < m.LabelName
 	})
-
-	return diffs, nil
 }
 
 func (s *Service) dryRunResourceLabelMapping(ctx context.Context, la labelAssociater, mappingFn labelMappingDiffFn) error {
@@ -1068,6 +2205,44 @@ func (s *Service) dryRunResourceLabelMapping(ctx context.Context, la labelAssoci
 type ApplyOpt struct {
 	EnvRefs        map[string]string
 	MissingSecrets map[string]string
+
+	// BucketRetentionOverride, when set, replaces the retention period of
+	// every bucket in the pkg. A zero duration provides infinite retention.
+	BucketRetentionOverride *time.Duration
+
+	// StackID identifies the stack the pkg is applied to. Dashboards
+	// from a prior application of the stack are updated in place.
+	StackID influxdb.ID
+
+	// CaseInsensitiveVariables matches pkg variables to existing variables
+	// by name without regard to case.
+	CaseInsensitiveVariables bool
+
+	// ExistingResourceIDs maps pkg names (metadata.name) to the IDs of the
+	// platform resources they are applied to, in place of matching by name.
+	ExistingResourceIDs map[string]influxdb.ID
+
+	// SafeMode refuses to apply a pkg whose diff contains destructive changes.
+	SafeMode bool
+
+	// Transforms are run over every object of the pkg, in the order provided,
+	// before the pkg is diffed with the platform.
+	Transforms []func(obj Object) (Object, error)
+
+	// ConflictStrategy determines how buckets, checks, labels, and variables
+	// that match an existing resource by name are applied.
+	ConflictStrategy ConflictStrategy
+
+	// StrictNames fails a dry run of a pkg containing objects without a name,
+	// rather than skipping the objects.
+	StrictNames bool
+
+	// NamePrefix is prepended to the name of every resource of the pkg.
+	NamePrefix string
+
+	// NoRollbackKinds are the kinds whose applied resources are left in place
+	// when a failed apply is rolled back.
+	NoRollbackKinds map[Kind]bool
 }
 
 // ApplyOptFn updates the ApplyOpt per the functional option.
@@ -1089,9 +2264,157 @@ func ApplyWithSecrets(secrets map[string]string) ApplyOptFn {
 	}
 }
 
+// ApplyWithBucketRetentionOverride overrides the retention period of every bucket
+// in the pkg with the provided duration. A zero duration provides infinite retention.
+func ApplyWithBucketRetentionOverride(rp time.Duration) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		if rp < 0 {
+			return fmt.Errorf("bucket retention override must be non negative; got %s", rp)
+		}
+		o.BucketRetentionOverride = &rp
+		return nil
+	}
+}
+
+// ApplyWithStackID associates the application of the pkg with the provided stack.
+func ApplyWithStackID(stackID influxdb.ID) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		o.StackID = stackID
+		return nil
+	}
+}
+
+// ApplyWithCaseInsensitiveVariables matches pkg variables to existing variables
+// regardless of the case of their names. A matched variable is updated in place,
+// retaining the name it has on the platform.
+func ApplyWithCaseInsensitiveVariables() ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		o.CaseInsensitiveVariables = true
+		return nil
+	}
+}
+
+// ApplyWithExistingResourceIDs maps pkg resources, by their pkg name, to the platform
+// resources they update. A mapped resource is found by ID rather than by name, allowing
+// a renamed resource to be updated in place. This applies to buckets, checks, dashboards,
+// labels, notification endpoints, and variables. A mapped resource that is not found
+// within the org fails the dry run.
+func ApplyWithExistingResourceIDs(ids map[string]influxdb.ID) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		o.ExistingResourceIDs = ids
+		return nil
+	}
+}
+
+// ApplyWithSafeMode gates the apply on a dry run of the pkg. When the diff contains
+// any destructive change, such as shortening a bucket's retention or replacing an
+// existing resource with one of a different type, the apply is refused with an
+// error listing the changes. The pkg can be applied without safe mode to confirm.
+func ApplyWithSafeMode() ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		o.SafeMode = true
+		return nil
+	}
+}
+
+// DryRunWithStrictNames fails the dry run of a pkg with an error listing every
+// object that is missing a name. Without it, the objects are skipped and the
+// remainder of the pkg is diffed.
+func DryRunWithStrictNames() ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		o.StrictNames = true
+		return nil
+	}
+}
+
+// ApplyWithNamePrefix prepends the prefix to the name of every resource of the pkg,
+// namespacing the resources applied for a tenant. The pkg names (metadata.name) are
+// left as is, associations and notification endpoint dependencies continue to
+// resolve by them. Existing resources are matched by their prefixed names. The prefix
+// is applied after the env refs are provided, a name provided by an env ref is
+// prefixed as well.
+func ApplyWithNamePrefix(prefix string) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		o.NamePrefix = prefix
+		return nil
+	}
+}
+
+// ApplyWithNoRollbackKinds leaves the applied resources of the provided kinds in place
+// when a failed apply is rolled back, for resources such as buckets where deleting the
+// data they hold is worse than a partial apply. The resources of every other kind are
+// rolled back as usual.
+//
+// Skipping a rollback leaves the platform in an inconsistent state: the resources left
+// in place may be the result of a partial apply, an existing resource that was updated
+// keeps its updated values, and the associations made to them, such as label mappings,
+// are removed as those are always rolled back. The pkg should be applied again to
+// converge the platform on the pkg. The check and notification endpoint kinds are
+// matched by their base kind, providing KindCheckDeadman skips rolling back all checks.
+func ApplyWithNoRollbackKinds(kinds ...Kind) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		for _, k := range kinds {
+			if err := k.OK(); err != nil {
+				return err
+			}
+			if o.NoRollbackKinds == nil {
+				o.NoRollbackKinds = make(map[Kind]bool)
+			}
+			o.NoRollbackKinds[k.baseKind()] = true
+		}
+		return nil
+	}
+}
+
+// ApplyWithTransform runs the transform over every object of the pkg before it is
+// diffed and applied, allowing for policies such as adding a label to, or prefixing
+// the name of, every resource. The transforms are run on a copy of the pkg, leaving
+// the provided pkg as it was parsed. When a transform returns an error the dry run
+// or apply is aborted.
+func ApplyWithTransform(fn func(obj Object) (Object, error)) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		if fn == nil {
+			return errors.New("transform must be provided")
+		}
+		o.Transforms = append(o.Transforms, fn)
+		return nil
+	}
+}
+
+// ConflictStrategy determines how an apply treats an existing resource that a pkg
+// resource matches by name. Resources mapped to an existing resource with
+// ApplyWithExistingResourceIDs are always updated.
+type ConflictStrategy int
+
+const (
+	// ConflictStrategyUpdate updates the existing resource to match the pkg.
+	ConflictStrategyUpdate ConflictStrategy = iota
+	// ConflictStrategySkip leaves the existing resource, and its label
+	// mappings, as is.
+	ConflictStrategySkip
+	// ConflictStrategyError fails the apply before any resource is applied.
+	ConflictStrategyError
+)
+
+// ApplyWithConflictStrategy sets the strategy for the buckets, checks, labels, and
+// variables of the pkg that match an existing resource by name. An apply updates
+// the existing resources when no strategy is provided.
+func ApplyWithConflictStrategy(strategy ConflictStrategy) ApplyOptFn {
+	return func(o *ApplyOpt) error {
+		switch strategy {
+		case ConflictStrategyUpdate, ConflictStrategySkip, ConflictStrategyError:
+		default:
+			return fmt.Errorf("unknown conflict strategy: %d", strategy)
+		}
+		o.ConflictStrategy = strategy
+		return nil
+	}
+}
+
 // Apply will apply all the resources identified in the provided pkg. The entire pkg will be applied
 // in its entirety. If a failure happens midway then the entire pkg will be rolled back to the state
-// from before the pkg were applied.
+// from before the pkg were applied. Cancelling the ctx midway is treated as a failure, the
+// resources applied up to that point are rolled back and no further resources are applied.
 func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (sum Summary, e error) {
 	if !pkg.isParsed {
 		if err := pkg.Validate(); err != nil {
@@ -1106,69 +2429,147 @@ func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg
 		}
 	}
 
+	if len(opt.Transforms) > 0 {
+		transformed, err := pkg.transform(opt.Transforms...)
+		if err != nil {
+			return Summary{}, failedValidationErr(err)
+		}
+		pkg = transformed
+	}
+
 	if err := pkg.applyEnvRefs(opt.EnvRefs); err != nil {
 		return Summary{}, failedValidationErr(err)
 	}
 
-	if !pkg.isVerified {
-		if _, _, err := s.DryRun(ctx, orgID, userID, pkg); err != nil {
+	if opt.NamePrefix != "" {
+		prefixed, err := pkg.withNamePrefix(opt.NamePrefix)
+		if err != nil {
+			return Summary{}, failedValidationErr(err)
+		}
+		pkg = prefixed
+	}
+
+	if opt.BucketRetentionOverride != nil {
+		overridden, err := pkg.withBucketRetention(*opt.BucketRetentionOverride)
+		if err != nil {
+			return Summary{}, failedValidationErr(err)
+		}
+		pkg = overridden
+	}
+
+	if !pkg.isVerified || opt.SafeMode {
+		dryRunOpts := []ApplyOptFn{
+			ApplyWithStackID(opt.StackID),
+			ApplyWithExistingResourceIDs(opt.ExistingResourceIDs),
+		}
+		if opt.CaseInsensitiveVariables {
+			dryRunOpts = append(dryRunOpts, ApplyWithCaseInsensitiveVariables())
+		}
+		_, diff, err := s.DryRun(ctx, orgID, userID, pkg, dryRunOpts...)
+		if err != nil {
 			return Summary{}, err
 		}
+
+		// a rule is applied after its endpoint, rejecting an invalid schedule here
+		// avoids rolling back everything applied before the rule fails to be created.
+		if errs := diff.scheduleErrs(); len(errs) > 0 {
+			return Summary{}, &influxdb.Error{
+				Code: influxdb.EUnprocessableEntity,
+				Msg:  fmt.Sprintf("notification rules have invalid schedules: [%s]", strings.Join(errs, "; ")),
+			}
+		}
+
+		if changes := diff.destructiveChanges(); opt.SafeMode && len(changes) > 0 {
+			return Summary{}, &influxdb.Error{
+				Code: influxdb.EConflict,
+				Msg:  fmt.Sprintf("safe mode refused to apply destructive changes: [%s]", strings.Join(changes, "; ")),
+			}
+		}
+	}
+
+	skipped, err := applyConflictStrategy(pkg, opt)
+	if err != nil {
+		return Summary{}, err
+	}
+
+	phases, err := s.applyGraph.phases()
+	if err != nil {
+		return Summary{}, internalErr(err)
+	}
+	s.warnApplyReqLimit(pkg, phases)
+
+	// the appliers are generated when their phase is reached, this allows for an
+	// applier to rely on the resources from prior phases having been applied. For
+	// instance, the notification rules rely on the notification endpoints.
+	applierGens := map[Kind]func() (applier, error){
+		KindBucket:               func() (applier, error) { return s.applyBuckets(pkg.buckets()), nil },
+		KindCheck:                func() (applier, error) { return s.applyChecks(pkg.checks()), nil },
+		KindDashboard:            func() (applier, error) { return s.applyDashboards(pkg.dashboards()), nil },
+		KindLabel:                func() (applier, error) { return s.applyLabels(pkg.labels()), nil },
+		KindNotificationEndpoint: func() (applier, error) { return s.applyNotificationEndpoints(pkg.notificationEndpoints()), nil },
+		KindNotificationRule:     func() (applier, error) { return s.applyNotificationRulesGenerator(ctx, orgID, pkg) },
+		KindTask:                 func() (applier, error) { return s.applyTasks(pkg.tasks()), nil },
+		KindTelegraf:             func() (applier, error) { return s.applyTelegrafs(pkg.telegrafs()), nil },
+		KindVariable:             func() (applier, error) { return s.applyVariables(pkg.variables()), nil },
+	}
+	for k, funcs := range s.registeredKinds() {
+		if funcs.Apply == nil {
+			continue
+		}
+		k, funcs := k, funcs
+		applierGens[k] = func() (applier, error) { return s.applyRegisteredKind(k, funcs, pkg), nil }
+	}
+	for k := range applierGens {
+		if _, ok := s.applyGraph[k]; !ok {
+			return Summary{}, internalErr(fmt.Errorf("apply graph is missing kind %q", k))
+		}
 	}
 
-	coordinator := &rollbackCoordinator{sem: make(chan struct{}, s.applyReqLimit)}
-	defer coordinator.rollback(s.log, &e, orgID)
+	coordinator := &rollbackCoordinator{
+		noRollbackKinds: opt.NoRollbackKinds,
+		sem:             make(chan struct{}, s.applyReqLimit),
+		observerFn:      s.applyRollbackFn,
+	}
+	defer coordinator.rollback(ctx, s.log, &e, orgID)
 
 	// each grouping here runs for its entirety, then returns an error that
 	// is indicative of running all appliers provided. For instance, the labels
 	// may have 1 variable fail and one of the buckets fails. The errors aggregate so
 	// the caller will be informed of both the failed label variable the failed bucket.
 	// the groupings here allow for steps to occur before exiting. The first step is
-	// adding the dependencies, resources that are associated by other resources. Then the
-	// primary resources. Here we get all the errors associated with them.
+	// adding the secrets that are referenced it the pkg, this allows user to
+	// provide data that does not rest in the pkg. Then each phase of the apply
+	// graph, where resources are applied once their dependencies have been applied.
 	// If those are all good, then we run the secondary(dependent) resources which
 	// rely on the primary resources having been created.
-	appliers := [][]applier{
-		{
-			// adds secrets that are referenced it the pkg, this allows user to
-			// provide data that does not rest in the pkg.
-			s.applySecrets(opt.MissingSecrets),
-		},
-		{
-			// deps for primary resources
-			s.applyLabels(pkg.labels()),
-		},
-		{
-			// primary resources, can have relationships to labels
-			s.applyVariables(pkg.variables()),
-			s.applyBuckets(pkg.buckets()),
-			s.applyChecks(pkg.checks()),
-			s.applyDashboards(pkg.dashboards()),
-			s.applyNotificationEndpoints(pkg.notificationEndpoints()),
-			s.applyTasks(pkg.tasks()),
-			s.applyTelegrafs(pkg.telegrafs()),
-		},
+	if err := coordinator.runTilEnd(ctx, orgID, userID, s.applySecrets(opt.MissingSecrets)); err != nil {
+		return Summary{}, internalErr(err)
 	}
 
-	for _, group := range appliers {
+	for _, phase := range phases {
+		group := make([]applier, 0, len(phase))
+		for _, k := range phase {
+			gen, ok := applierGens[k]
+			if !ok {
+				return Summary{}, internalErr(fmt.Errorf("no applier for kind %q", k))
+			}
+			app, err := gen()
+			if err != nil {
+				return Summary{}, err
+			}
+			group = append(group, app)
+		}
+
 		if err := coordinator.runTilEnd(ctx, orgID, userID, group...); err != nil {
 			return Summary{}, internalErr(err)
 		}
 	}
 
-	// this has to be run after the above primary resources, because it relies on
-	// notification endpoints already being applied.
-	app, err := s.applyNotificationRulesGenerator(ctx, orgID, pkg)
-	if err != nil {
-		return Summary{}, err
-	}
-	if err := coordinator.runTilEnd(ctx, orgID, userID, app); err != nil {
-		return Summary{}, err
-	}
-
 	// secondary resources
-	// this last grouping relies on the above 2 steps having completely successfully
-	secondary := []applier{s.applyLabelMappings(pkg.labelMappings())}
+	// this last grouping relies on the above steps having completely successfully
+	secondary := []applier{
+		s.applyLabelMappings(withoutSkippedMappings(pkg.labelMappings(), skipped)),
+	}
 	if err := coordinator.runTilEnd(ctx, orgID, userID, secondary...); err != nil {
 		return Summary{}, internalErr(err)
 	}
@@ -1178,6 +2579,232 @@ func (s *Service) Apply(ctx context.Context, orgID, userID influxdb.ID, pkg *Pkg
 	return pkg.Summary(), nil
 }
 
+// applyConflictStrategy applies the strategy of the apply to the buckets, checks,
+// labels, and variables of the pkg that match an existing resource by name. The
+// resources skipped by ConflictStrategySkip are returned, so that their label
+// mappings are skipped as well.
+func applyConflictStrategy(pkg *Pkg, opt ApplyOpt) (map[assocMapKey]bool, error) {
+	type conflictResource struct {
+		kind     Kind
+		pkgName  string
+		key      assocMapKey
+		existing bool
+		action   *ApplyAction
+	}
+
+	var resources []conflictResource
+	for _, b := range pkg.buckets() {
+		resources = append(resources, conflictResource{KindBucket, b.PkgName(), assocMapKey{b.ResourceType(), b.Name()}, b.existing != nil, &b.action})
+	}
+	for _, c := range pkg.checks() {
+		resources = append(resources, conflictResource{KindCheck, c.PkgName(), assocMapKey{c.ResourceType(), c.Name()}, c.existing != nil, &c.action})
+	}
+	for _, l := range pkg.labels() {
+		resources = append(resources, conflictResource{KindLabel, l.PkgName(), assocMapKey{influxdb.LabelsResourceType, l.Name()}, l.existing != nil, &l.action})
+	}
+	for _, v := range pkg.variables() {
+		resources = append(resources, conflictResource{KindVariable, v.PkgName(), assocMapKey{v.ResourceType(), v.Name()}, v.existing != nil, &v.action})
+	}
+
+	var conflicts []string
+	skipped := make(map[assocMapKey]bool)
+	for _, r := range resources {
+		*r.action = ApplyActionUnknown
+		if !r.existing {
+			continue
+		}
+		if _, ok := opt.ExistingResourceIDs[r.pkgName]; ok {
+			continue
+		}
+
+		switch opt.ConflictStrategy {
+		case ConflictStrategySkip:
+			*r.action = ApplyActionSkipped
+			skipped[r.key] = true
+		case ConflictStrategyError:
+			conflicts = append(conflicts, fmt.Sprintf("%s %q", r.kind, r.pkgName))
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, &influxdb.Error{
+			Code: influxdb.EConflict,
+			Msg:  fmt.Sprintf("pkg resources conflict with existing resources of the same name: [%s]", strings.Join(conflicts, "; ")),
+		}
+	}
+	return skipped, nil
+}
+
+func withoutSkippedMappings(mappings []SummaryLabelMapping, skipped map[assocMapKey]bool) []SummaryLabelMapping {
+	if len(skipped) == 0 {
+		return mappings
+	}
+
+	out := mappings[:0]
+	for _, m := range mappings {
+		if skipped[assocMapKey{m.ResourceType, m.ResourceName}] {
+			continue
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// ApplyToOrgs applies the pkg to each of the provided orgs in turn. The pkg is cloned for
+// each org, leaving the provided pkg and the application to every other org unaffected
+// by the IDs and org IDs an apply assigns to the pkg's resources. The summaries of the
+// orgs the pkg was applied to are returned by org ID. An error describing each org that
+// failed is returned alongside them, the failed orgs are rolled back as Apply does.
+func (s *Service) ApplyToOrgs(ctx context.Context, orgIDs []influxdb.ID, userID influxdb.ID, pkg *Pkg, opts ...ApplyOptFn) (map[influxdb.ID]Summary, error) {
+	summaries := make(map[influxdb.ID]Summary, len(orgIDs))
+
+	var orgErrs []orgApplyErr
+	for _, orgID := range orgIDs {
+		if err := ctx.Err(); err != nil {
+			orgErrs = append(orgErrs, orgApplyErr{orgID: orgID, err: err})
+			continue
+		}
+
+		clone, err := pkg.Clone()
+		if err != nil {
+			orgErrs = append(orgErrs, orgApplyErr{orgID: orgID, err: failedValidationErr(err)})
+			continue
+		}
+
+		sum, err := s.Apply(ctx, orgID, userID, clone, opts...)
+		if err != nil {
+			orgErrs = append(orgErrs, orgApplyErr{orgID: orgID, err: err})
+			continue
+		}
+		summaries[orgID] = sum
+	}
+
+	return summaries, newOrgsApplyErr(orgErrs)
+}
+
+type orgApplyErr struct {
+	orgID influxdb.ID
+	err   error
+}
+
+// newOrgsApplyErr aggregates the errors from applying a pkg to many orgs. When every
+// org failed with the same error code, that code is retained.
+func newOrgsApplyErr(orgErrs []orgApplyErr) error {
+	if len(orgErrs) == 0 {
+		return nil
+	}
+
+	code := influxdb.ErrorCode(orgErrs[0].err)
+	msgs := make([]string, 0, len(orgErrs))
+	for _, oErr := range orgErrs {
+		if influxdb.ErrorCode(oErr.err) != code {
+			code = influxdb.EInternal
+		}
+		msgs = append(msgs, fmt.Sprintf("org_id=%s err=%q", oErr.orgID, oErr.err.Error()))
+	}
+
+	return &influxdb.Error{
+		Code: code,
+		Msg:  fmt.Sprintf("failed to apply pkg to %d org(s): [%s]", len(orgErrs), strings.Join(msgs, ", ")),
+	}
+}
+
+// applyReqLimitWarnMinResources is the number of resources a pkg must have
+// before a low apply request limit is warned about.
+const applyReqLimitWarnMinResources = 100
+
+// warnApplyReqLimit logs a warning when the apply request limit is lower than the
+// number of phases in the apply graph, and the pkg is large enough for the resulting
+// serialization to make for a noticeably slow apply.
+func (s *Service) warnApplyReqLimit(pkg *Pkg, phases [][]Kind) {
+	if s.applyReqLimit >= len(phases) {
+		return
+	}
+
+	numResources := len(pkg.mBuckets) + len(pkg.mChecks) + len(pkg.mDashboards) +
+		len(pkg.mLabels) + len(pkg.mNotificationEndpoints) + len(pkg.mNotificationRules) +
+		len(pkg.mTasks) + len(pkg.mTelegrafs) + len(pkg.mVariables)
+	if numResources < applyReqLimitWarnMinResources {
+		return
+	}
+
+	s.log.Warn(
+		"apply request limit serializes the application of a large pkg; consider raising the limit",
+		zap.Int("apply_req_limit", s.applyReqLimit),
+		zap.Int("apply_phases", len(phases)),
+		zap.Int("resources", numResources),
+	)
+}
+
+// ApplyGraph declares the order in which the resources of a pkg are applied. Each
+// kind maps to the kinds it depends on. A kind is applied once all of its dependencies
+// have been applied, kinds with no dependency between them are applied concurrently.
+// Secrets are always applied before, and label mappings after, every kind in the graph.
+type ApplyGraph map[Kind][]Kind
+
+// DefaultApplyGraph provides the graph used to apply a pkg when none is provided.
+// The labels are applied first, as all other resources can be associated with them.
+// Then the primary resources, with the notification rules applied after the
+// notification endpoints they reference.
+func DefaultApplyGraph() ApplyGraph {
+	return ApplyGraph{
+		KindLabel:                nil,
+		KindBucket:               {KindLabel},
+		KindCheck:                {KindLabel},
+		KindDashboard:            {KindLabel},
+		KindNotificationEndpoint: {KindLabel},
+		KindNotificationRule:     {KindLabel, KindNotificationEndpoint},
+		KindTask:                 {KindLabel},
+		KindTelegraf:             {KindLabel},
+		KindVariable:             {KindLabel},
+	}
+}
+
+// phases topologically sorts the graph into the groups of kinds that can be
+// applied together. The kinds within a phase are sorted by name.
+func (g ApplyGraph) phases() ([][]Kind, error) {
+	for k, deps := range g {
+		for _, dep := range deps {
+			if _, ok := g[dep]; !ok {
+				return nil, fmt.Errorf("kind %q depends on kind %q which is not in the apply graph", k, dep)
+			}
+		}
+	}
+
+	applied := make(map[Kind]bool, len(g))
+	var phases [][]Kind
+	for len(applied) < len(g) {
+		var phase []Kind
+		for k, deps := range g {
+			if applied[k] {
+				continue
+			}
+			ready := true
+			for _, dep := range deps {
+				if !applied[dep] {
+					ready = false
+					break
+				}
+			}
+			if ready {
+				phase = append(phase, k)
+			}
+		}
+		if len(phase) == 0 {
+			return nil, errors.New("apply graph contains a dependency cycle")
+		}
+
+		sort.Slice(phase, func(i, j int) bool {
+			return phase[i] < phase[j]
+		})
+		for _, k := range phase {
+			applied[k] = true
+		}
+		phases = append(phases, phase)
+	}
+	return phases, nil
+}
+
 func (s *Service) applyBuckets(buckets []*bucket) applier {
 	const resource = "bucket"
 
@@ -1190,7 +2817,13 @@ func (s *Service) applyBuckets(buckets []*bucket) applier {
 			buckets[i].OrgID = orgID
 			b = *buckets[i]
 		})
+		if b.action == ApplyActionSkipped {
+			return nil
+		}
 		if !b.shouldApply() {
+			mutex.Do(func() {
+				buckets[i].action = ApplyActionUnchanged
+			})
 			return nil
 		}
 
@@ -1204,6 +2837,7 @@ func (s *Service) applyBuckets(buckets []*bucket) applier {
 
 		mutex.Do(func() {
 			buckets[i].id = influxBucket.ID
+			buckets[i].action = newApplyAction(b.existing != nil)
 			rollbackBuckets = append(rollbackBuckets, buckets[i])
 		})
 
@@ -1217,6 +2851,7 @@ func (s *Service) applyBuckets(buckets []*bucket) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindBucket,
 			fn:       func(_ influxdb.ID) error { return s.rollbackBuckets(rollbackBuckets) },
 		},
 	}
@@ -1233,10 +2868,10 @@ func (s *Service) rollbackBuckets(buckets []*bucket) error {
 			continue
 		}
 
-		rp := b.RetentionRules.RP()
 		_, err := s.bucketSVC.UpdateBucket(context.Background(), b.ID(), influxdb.BucketUpdate{
-			Description:     &b.Description,
-			RetentionPeriod: &rp,
+			Description:        &b.existing.Description,
+			RetentionPeriod:    &b.existing.RetentionPeriod,
+			ShardGroupDuration: &b.existing.ShardGroupDuration,
 		})
 		if err != nil {
 			errs = append(errs, b.ID().String())
@@ -1254,10 +2889,15 @@ func (s *Service) rollbackBuckets(buckets []*bucket) error {
 func (s *Service) applyBucket(ctx context.Context, b bucket) (influxdb.Bucket, error) {
 	rp := b.RetentionRules.RP()
 	if b.existing != nil {
-		influxBucket, err := s.bucketSVC.UpdateBucket(ctx, b.ID(), influxdb.BucketUpdate{
+		upd := influxdb.BucketUpdate{
 			Description:     &b.Description,
 			RetentionPeriod: &rp,
-		})
+		}
+		if b.shardGroupDurationChanged() {
+			sgd := b.RetentionRules.ShardGroupDuration()
+			upd.ShardGroupDuration = &sgd
+		}
+		influxBucket, err := s.bucketSVC.UpdateBucket(ctx, b.ID(), upd)
 		if err != nil {
 			return influxdb.Bucket{}, err
 		}
@@ -1265,10 +2905,11 @@ func (s *Service) applyBucket(ctx context.Context, b bucket) (influxdb.Bucket, e
 	}
 
 	influxBucket := influxdb.Bucket{
-		OrgID:           b.OrgID,
-		Description:     b.Description,
-		Name:            b.Name(),
-		RetentionPeriod: rp,
+		OrgID:              b.OrgID,
+		Description:        b.Description,
+		Name:               b.Name(),
+		RetentionPeriod:    rp,
+		ShardGroupDuration: b.RetentionRules.ShardGroupDuration(),
 	}
 	err := s.bucketSVC.CreateBucket(ctx, &influxBucket)
 	if err != nil {
@@ -1290,6 +2931,9 @@ func (s *Service) applyChecks(checks []*check) applier {
 			checks[i].orgID = orgID
 			c = *checks[i]
 		})
+		if c.action == ApplyActionSkipped {
+			return nil
+		}
 
 		influxBucket, err := s.applyCheck(ctx, c, userID)
 		if err != nil {
@@ -1301,6 +2945,7 @@ func (s *Service) applyChecks(checks []*check) applier {
 
 		mutex.Do(func() {
 			checks[i].id = influxBucket.GetID()
+			checks[i].action = newApplyAction(c.existing != nil)
 			rollbackChecks = append(rollbackChecks, checks[i])
 		})
 
@@ -1314,6 +2959,7 @@ func (s *Service) applyChecks(checks []*check) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindCheck,
 			fn:       func(_ influxdb.ID) error { return s.rollbackChecks(rollbackChecks) },
 		},
 	}
@@ -1393,29 +3039,89 @@ func (s *Service) applyDashboards(dashboards []*dashboard) applier {
 
 		mutex.Do(func() {
 			dashboards[i].id = influxBucket.ID
+			dashboards[i].action = newApplyAction(d.existing != nil)
 			rollbackDashboards = append(rollbackDashboards, dashboards[i])
 		})
 		return nil
 	}
 
-	return applier{
-		creater: creater{
-			entries: len(dashboards),
-			fn:      createFn,
-		},
-		rollbacker: rollbacker{
-			resource: resource,
-			fn: func(_ influxdb.ID) error {
-				return s.deleteByIDs("dashboard", len(rollbackDashboards), s.dashSVC.DeleteDashboard, func(i int) influxdb.ID {
-					return rollbackDashboards[i].ID()
-				})
-			},
-		},
-	}
+	return applier{
+		creater: creater{
+			entries: len(dashboards),
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			kind:     KindDashboard,
+			fn: func(_ influxdb.ID) error {
+				return s.rollbackDashboards(rollbackDashboards)
+			},
+		},
+	}
+}
+
+func (s *Service) rollbackDashboards(dashboards []*dashboard) error {
+	var errs []string
+	for _, d := range dashboards {
+		if d.existing == nil {
+			err := s.dashSVC.DeleteDashboard(context.Background(), d.ID())
+			if err != nil {
+				errs = append(errs, d.ID().String())
+			}
+			continue
+		}
+
+		ctx := context.Background()
+		_, err := s.dashSVC.UpdateDashboard(ctx, d.ID(), influxdb.DashboardUpdate{
+			Name:        &d.existing.Name,
+			Description: &d.existing.Description,
+		})
+		if err == nil {
+			err = s.replaceDashboardCells(ctx, d.ID(), d.existing.Cells)
+		}
+		if err != nil {
+			errs = append(errs, d.ID().String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(`dashboard_ids=[%s] err="unable to rollback"`, strings.Join(errs, ", "))
+	}
+
+	return nil
 }
 
 func (s *Service) applyDashboard(ctx context.Context, d dashboard) (influxdb.Dashboard, error) {
 	cells := convertChartsToCells(d.Charts)
+	if d.existing != nil {
+		// hold onto the views of the existing cells so that they can
+		// be restored on rollback.
+		for _, c := range d.existing.Cells {
+			if c.View != nil {
+				continue
+			}
+			view, err := s.dashSVC.GetDashboardCellView(ctx, d.existing.ID, c.ID)
+			if err != nil {
+				return influxdb.Dashboard{}, err
+			}
+			c.View = view
+		}
+
+		name := d.Name()
+		updatedDash, err := s.dashSVC.UpdateDashboard(ctx, d.ID(), influxdb.DashboardUpdate{
+			Name:        &name,
+			Description: &d.Description,
+		})
+		if err != nil {
+			return influxdb.Dashboard{}, err
+		}
+		if err := s.replaceDashboardCells(ctx, d.ID(), cells); err != nil {
+			return influxdb.Dashboard{}, err
+		}
+		updatedDash.Cells = cells
+		return *updatedDash, nil
+	}
+
 	influxDashboard := influxdb.Dashboard{
 		OrganizationID: d.OrgID,
 		Description:    d.Description,
@@ -1430,6 +3136,29 @@ func (s *Service) applyDashboard(ctx context.Context, d dashboard) (influxdb.Das
 	return influxDashboard, nil
 }
 
+// replaceDashboardCells removes all the cells from the dashboard and adds
+// the provided cells in their place.
+func (s *Service) replaceDashboardCells(ctx context.Context, dashID influxdb.ID, cells []*influxdb.Cell) error {
+	current, err := s.dashSVC.FindDashboardByID(ctx, dashID)
+	if err != nil {
+		return err
+	}
+	for _, c := range current.Cells {
+		if err := s.dashSVC.RemoveDashboardCell(ctx, dashID, c.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, c := range cells {
+		cell := &influxdb.Cell{CellProperty: c.CellProperty}
+		err := s.dashSVC.AddDashboardCell(ctx, dashID, cell, influxdb.AddDashboardCellOptions{View: c.View})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func convertChartsToCells(ch []chart) []*influxdb.Cell {
 	icells := make([]*influxdb.Cell, 0, len(ch))
 	for _, c := range ch {
@@ -1462,7 +3191,13 @@ func (s *Service) applyLabels(labels []*label) applier {
 			labels[i].OrgID = orgID
 			l = *labels[i]
 		})
+		if l.action == ApplyActionSkipped {
+			return nil
+		}
 		if !l.shouldApply() {
+			mutex.Do(func() {
+				labels[i].action = ApplyActionUnchanged
+			})
 			return nil
 		}
 
@@ -1476,6 +3211,7 @@ func (s *Service) applyLabels(labels []*label) applier {
 
 		mutex.Do(func() {
 			labels[i].id = influxLabel.ID
+			labels[i].action = newApplyAction(l.existing != nil)
 			rollBackLabels = append(rollBackLabels, labels[i])
 		})
 
@@ -1489,6 +3225,7 @@ func (s *Service) applyLabels(labels []*label) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindLabel,
 			fn:       func(_ influxdb.ID) error { return s.rollbackLabels(rollBackLabels) },
 		},
 	}
@@ -1563,18 +3300,8 @@ func (s *Service) applyNotificationEndpoints(endpoints []*notificationEndpoint)
 
 		mutex.Do(func() {
 			endpoints[i].id = influxEndpoint.GetID()
-			for _, secret := range influxEndpoint.SecretFields() {
-				switch {
-				case strings.HasSuffix(secret.Key, "-routing-key"):
-					endpoints[i].routingKey.Secret = secret.Key
-				case strings.HasSuffix(secret.Key, "-token"):
-					endpoints[i].token.Secret = secret.Key
-				case strings.HasSuffix(secret.Key, "-username"):
-					endpoints[i].username.Secret = secret.Key
-				case strings.HasSuffix(secret.Key, "-password"):
-					endpoints[i].password.Secret = secret.Key
-				}
-			}
+			endpoints[i].action = newApplyAction(endpoint.existing != nil)
+			s.setEndpointSecretKeys(endpoints[i], influxEndpoint)
 			rollbackEndpoints = append(rollbackEndpoints, endpoints[i])
 		})
 
@@ -1588,6 +3315,7 @@ func (s *Service) applyNotificationEndpoints(endpoints []*notificationEndpoint)
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindNotificationEndpoint,
 			fn: func(_ influxdb.ID) error {
 				return s.rollbackNotificationEndpoints(rollbackEndpoints)
 			},
@@ -1600,7 +3328,7 @@ func (s *Service) applyNotificationEndpoint(ctx context.Context, e notificationE
 		// stub out userID since we're always using hte http client which will fill it in for us with the token
 		// feels a bit broken that is required.
 		// TODO: look into this userID requirement
-		updatedEndpoint, err := s.endpointSVC.UpdateNotificationEndpoint(ctx, e.ID(), e.existing, userID)
+		updatedEndpoint, err := s.endpointSVC.UpdateNotificationEndpoint(ctx, e.ID(), endpointWithPkgSecrets(e), userID)
 		if err != nil {
 			return nil, err
 		}
@@ -1616,6 +3344,93 @@ func (s *Service) applyNotificationEndpoint(ctx context.Context, e notificationE
 	return actual, nil
 }
 
+// endpointWithPkgSecrets returns a copy of the existing endpoint with the secrets
+// provided by the pkg applied to it. Secrets the pkg leaves blank retain the
+// existing endpoint's secret. The existing endpoint is left untouched so it can
+// be restored on rollback.
+func endpointWithPkgSecrets(e notificationEndpoint) influxdb.NotificationEndpoint {
+	switch existing := e.existing.(type) {
+	case *endpoint.HTTP:
+		updated := *existing
+		applyPkgSecret(&updated.Token, e.token)
+		applyPkgSecret(&updated.Username, e.username)
+		applyPkgSecret(&updated.Password, e.password)
+		return &updated
+	case *endpoint.PagerDuty:
+		updated := *existing
+		applyPkgSecret(&updated.RoutingKey, e.routingKey)
+		return &updated
+	case *endpoint.Slack:
+		updated := *existing
+		applyPkgSecret(&updated.Token, e.token)
+		return &updated
+	default:
+		return e.existing
+	}
+}
+
+func applyPkgSecret(existing *influxdb.SecretField, ref *references) {
+	if ref == nil {
+		return
+	}
+
+	field := ref.SecretField()
+	switch {
+	case field.Key != "":
+		*existing = field
+	case field.Value != nil:
+		existing.Value = field.Value
+	}
+}
+
+// endpointSecretFields maps the suffix of a platform endpoint's secret key to the
+// secret reference of the pkg endpoint the key belongs to. An endpoint type with a
+// secret field of its own adds the suffix of the field's key here.
+var endpointSecretFields = map[string]func(e *notificationEndpoint) *references{
+	"-routing-key": func(e *notificationEndpoint) *references { return e.routingKey },
+	"-token":       func(e *notificationEndpoint) *references { return e.token },
+	"-username":    func(e *notificationEndpoint) *references { return e.username },
+	"-password":    func(e *notificationEndpoint) *references { return e.password },
+}
+
+// endpointSecretField provides the secret reference of the pkg endpoint the secret key
+// belongs to. The longest matching suffix wins, so a suffix may end with another.
+func endpointSecretField(e *notificationEndpoint, key string) (*references, bool) {
+	var (
+		matched string
+		fieldFn func(e *notificationEndpoint) *references
+	)
+	for suffix, fn := range endpointSecretFields {
+		if strings.HasSuffix(key, suffix) && len(suffix) > len(matched) {
+			matched, fieldFn = suffix, fn
+		}
+	}
+	if fieldFn == nil {
+		return nil, false
+	}
+
+	ref := fieldFn(e)
+	return ref, ref != nil
+}
+
+// setEndpointSecretKeys points the pkg endpoint's secret references at the secret
+// keys of the platform endpoint it was created or updated as. A secret key without a
+// matching secret field is logged, as the pkg endpoint loses track of the secret.
+func (s *Service) setEndpointSecretKeys(e *notificationEndpoint, influxEndpoint influxdb.NotificationEndpoint) {
+	for _, secret := range influxEndpoint.SecretFields() {
+		ref, ok := endpointSecretField(e, secret.Key)
+		if !ok {
+			s.log.Warn(
+				"notification endpoint secret key does not match a secret field",
+				zap.String("endpoint", e.Name()),
+				zap.String("secret_key", secret.Key),
+			)
+			continue
+		}
+		ref.Secret = secret.Key
+	}
+}
+
 func (s *Service) rollbackNotificationEndpoints(endpoints []*notificationEndpoint) error {
 	var errs []string
 	for _, e := range endpoints {
@@ -1660,12 +3475,14 @@ func (s *Service) applyNotificationRulesGenerator(ctx context.Context, orgID inf
 		}
 	}
 	for _, e := range pkg.notificationEndpoints() {
-		if _, ok := mEndpoints[e.PkgName()]; ok {
-			continue
-		}
-		mEndpoints[e.PkgName()] = mVal{
-			id:    e.ID(),
-			eType: e.summarize().NotificationEndpoint.Type(),
+		for _, name := range []string{e.PkgName(), e.Name()} {
+			if _, ok := mEndpoints[name]; ok {
+				continue
+			}
+			mEndpoints[name] = mVal{
+				id:    e.ID(),
+				eType: e.summarize().NotificationEndpoint.Type(),
+			}
 		}
 	}
 
@@ -1698,12 +3515,14 @@ func (s *Service) applyNotificationRules(rules []*notificationRule) applier {
 
 	mutex := new(doMutex)
 	rollbackEndpoints := make([]*notificationRule, 0, len(rules))
+	var rollbackUserID influxdb.ID
 
 	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
 		var rule notificationRule
 		mutex.Do(func() {
 			rules[i].orgID = orgID
 			rule = *rules[i]
+			rollbackUserID = userID
 		})
 
 		influxRule, err := s.applyNotificationRule(ctx, rule, userID)
@@ -1716,6 +3535,7 @@ func (s *Service) applyNotificationRules(rules []*notificationRule) applier {
 
 		mutex.Do(func() {
 			rules[i].id = influxRule.GetID()
+			rules[i].action = newApplyAction(rule.existing != nil)
 			rollbackEndpoints = append(rollbackEndpoints, rules[i])
 		})
 
@@ -1729,18 +3549,28 @@ func (s *Service) applyNotificationRules(rules []*notificationRule) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindNotificationRule,
 			fn: func(_ influxdb.ID) error {
-				return s.rollbackNotificationRules(rollbackEndpoints)
+				return s.rollbackNotificationRules(rollbackUserID, rollbackEndpoints)
 			},
 		},
 	}
 }
 
-func (s *Service) applyNotificationRule(ctx context.Context, e notificationRule, userID influxdb.ID) (influxdb.NotificationRule, error) {
+func (s *Service) applyNotificationRule(ctx context.Context, r notificationRule, userID influxdb.ID) (influxdb.NotificationRule, error) {
 	actual := influxdb.NotificationRuleCreate{
-		NotificationRule: e.toInfluxRule(),
-		Status:           e.Status(),
+		NotificationRule: r.toInfluxRule(),
+		Status:           r.Status(),
+	}
+
+	if r.existing != nil {
+		updatedRule, err := s.ruleSVC.UpdateNotificationRule(ctx, r.ID(), actual, userID)
+		if err != nil {
+			return nil, err
+		}
+		return updatedRule, nil
 	}
+
 	err := s.ruleSVC.CreateNotificationRule(ctx, actual, userID)
 	if err != nil {
 		return nil, err
@@ -1749,21 +3579,62 @@ func (s *Service) applyNotificationRule(ctx context.Context, e notificationRule,
 	return actual, nil
 }
 
-func (s *Service) rollbackNotificationRules(rules []*notificationRule) error {
+func (s *Service) rollbackNotificationRules(userID influxdb.ID, rules []*notificationRule) error {
 	var errs []string
-	for _, e := range rules {
-		err := s.ruleSVC.DeleteNotificationRule(context.Background(), e.ID())
+	for _, r := range rules {
+		if r.existing == nil {
+			err := s.ruleSVC.DeleteNotificationRule(context.Background(), r.ID())
+			if err != nil {
+				errs = append(errs, r.ID().String())
+			}
+			continue
+		}
+
+		_, err := s.ruleSVC.UpdateNotificationRule(context.Background(), r.ID(), influxdb.NotificationRuleCreate{
+			NotificationRule: r.existing,
+			Status:           r.existingStatus,
+		}, userID)
 		if err != nil {
-			errs = append(errs, e.ID().String())
+			errs = append(errs, r.ID().String())
 		}
 	}
 
 	if len(errs) > 0 {
-		return fmt.Errorf(`notication_rule_ids=[%s] err="unable to delete"`, strings.Join(errs, ", "))
+		return fmt.Errorf(`notication_rule_ids=[%s] err="unable to rollback"`, strings.Join(errs, ", "))
 	}
 	return nil
 }
 
+// applyRegisteredKind applies the resources of a kind registered with RegisterKind.
+// The kind is rolled back only when its apply succeeded.
+func (s *Service) applyRegisteredKind(k Kind, funcs KindFuncs, pkg *Pkg) applier {
+	var applied bool
+	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
+		if err := funcs.Apply(ctx, orgID, userID, pkg); err != nil {
+			return &applyErrBody{name: string(k), msg: err.Error()}
+		}
+		applied = true
+		return nil
+	}
+
+	return applier{
+		creater: creater{
+			entries: 1,
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: string(k),
+			kind:     k,
+			fn: func(orgID influxdb.ID) error {
+				if !applied || funcs.Rollback == nil {
+					return nil
+				}
+				return funcs.Rollback(context.Background(), orgID, pkg)
+			},
+		},
+	}
+}
+
 func (s *Service) applySecrets(secrets map[string]string) applier {
 	const resource = "secrets"
 
@@ -1773,20 +3644,38 @@ func (s *Service) applySecrets(secrets map[string]string) applier {
 		}
 	}
 
-	mutex := new(doMutex)
-	rollbackSecrets := make([]string, 0)
+	var (
+		// secrets that did not exist before the apply, these are deleted on rollback
+		rollbackNewKeys []string
+		// secrets that existed before the apply, these are restored on rollback
+		rollbackPriorSecrets = make(map[string]string)
+	)
 
 	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		err := s.secretSVC.PutSecrets(ctx, orgID, secrets)
+		var (
+			newKeys []string
+			prior   = make(map[string]string)
+		)
+		for key := range secrets {
+			v, err := s.secretSVC.LoadSecret(ctx, orgID, key)
+			switch {
+			case err == nil:
+				prior[key] = v
+			case influxdb.ErrorCode(err) == influxdb.ENotFound:
+				newKeys = append(newKeys, key)
+			default:
+				return &applyErrBody{name: "secrets", msg: err.Error()}
+			}
+		}
+
+		// patching here so that only the provided secrets are touched, putting the
+		// secrets would remove all other secrets from the org.
+		err := s.secretSVC.PatchSecrets(ctx, orgID, secrets)
 		if err != nil {
 			return &applyErrBody{name: "secrets", msg: err.Error()}
 		}
 
-		mutex.Do(func() {
-			for key := range secrets {
-				rollbackSecrets = append(rollbackSecrets, key)
-			}
-		})
+		rollbackNewKeys, rollbackPriorSecrets = newKeys, prior
 
 		return nil
 	}
@@ -1799,12 +3688,27 @@ func (s *Service) applySecrets(secrets map[string]string) applier {
 		rollbacker: rollbacker{
 			resource: resource,
 			fn: func(orgID influxdb.ID) error {
-				return s.secretSVC.DeleteSecret(context.Background(), orgID)
+				return s.rollbackSecrets(orgID, rollbackNewKeys, rollbackPriorSecrets)
 			},
 		},
 	}
 }
 
+func (s *Service) rollbackSecrets(orgID influxdb.ID, newKeys []string, prior map[string]string) error {
+	ctx := context.Background()
+	if len(newKeys) > 0 {
+		if err := s.secretSVC.DeleteSecret(ctx, orgID, newKeys...); err != nil {
+			return err
+		}
+	}
+	if len(prior) > 0 {
+		if err := s.secretSVC.PatchSecrets(ctx, orgID, prior); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (s *Service) applyTasks(tasks []*task) applier {
 	const resource = "tasks"
 
@@ -1818,20 +3722,14 @@ func (s *Service) applyTasks(tasks []*task) applier {
 			t = *tasks[i]
 		})
 
-		newTask, err := s.taskSVC.CreateTask(ctx, influxdb.TaskCreate{
-			Type:           influxdb.TaskSystemType,
-			Flux:           t.flux(),
-			OwnerID:        userID,
-			Description:    t.description,
-			Status:         string(t.Status()),
-			OrganizationID: t.orgID,
-		})
+		newTask, err := s.applyTask(ctx, userID, t)
 		if err != nil {
 			return &applyErrBody{name: t.Name(), msg: err.Error()}
 		}
 
 		mutex.Do(func() {
 			tasks[i].id = newTask.ID
+			tasks[i].action = newApplyAction(t.existing != nil)
 			rollbackTasks = append(rollbackTasks, *tasks[i])
 		})
 
@@ -1845,38 +3743,96 @@ func (s *Service) applyTasks(tasks []*task) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
-			fn: func(_ influxdb.ID) error {
-				return s.deleteByIDs("task", len(rollbackTasks), s.taskSVC.DeleteTask, func(i int) influxdb.ID {
-					return rollbackTasks[i].ID()
-				})
-			},
+			kind:     KindTask,
+			fn:       func(_ influxdb.ID) error { return s.rollbackTasks(rollbackTasks) },
 		},
 	}
 }
 
+func (s *Service) rollbackTasks(tasks []task) error {
+	var errs []string
+	for _, t := range tasks {
+		if t.existing == nil {
+			err := s.taskSVC.DeleteTask(context.Background(), t.ID())
+			if err != nil {
+				errs = append(errs, t.ID().String())
+			}
+			continue
+		}
+
+		_, err := s.taskSVC.UpdateTask(context.Background(), t.ID(), influxdb.TaskUpdate{
+			Flux:        &t.existing.Flux,
+			Description: &t.existing.Description,
+			Status:      &t.existing.Status,
+		})
+		if err != nil {
+			errs = append(errs, t.ID().String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(`task_ids=[%s] err="unable to rollback"`, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (s *Service) applyTask(ctx context.Context, userID influxdb.ID, t task) (influxdb.Task, error) {
+	flux := t.flux()
+	status := string(t.Status())
+
+	if t.existing != nil {
+		updatedTask, err := s.taskSVC.UpdateTask(ctx, t.ID(), influxdb.TaskUpdate{
+			Flux:        &flux,
+			Description: &t.description,
+			Status:      &status,
+		})
+		if err != nil {
+			return influxdb.Task{}, err
+		}
+		return *updatedTask, nil
+	}
+
+	newTask, err := s.taskSVC.CreateTask(ctx, influxdb.TaskCreate{
+		Type:           influxdb.TaskSystemType,
+		Flux:           flux,
+		OwnerID:        userID,
+		Description:    t.description,
+		Status:         status,
+		OrganizationID: t.orgID,
+	})
+	if err != nil {
+		return influxdb.Task{}, err
+	}
+	return *newTask, nil
+}
+
 func (s *Service) applyTelegrafs(teles []*telegraf) applier {
 	const resource = "telegrafs"
 
 	mutex := new(doMutex)
 	rollbackTelegrafs := make([]*telegraf, 0, len(teles))
+	var rollbackUserID influxdb.ID
 
 	createFn := func(ctx context.Context, i int, orgID, userID influxdb.ID) *applyErrBody {
-		var cfg influxdb.TelegrafConfig
+		var t telegraf
 		mutex.Do(func() {
 			teles[i].config.OrgID = orgID
-			cfg = teles[i].summarize().TelegrafConfig
+			t = *teles[i]
+			rollbackUserID = userID
 		})
 
-		err := s.teleSVC.CreateTelegrafConfig(ctx, &cfg, userID)
+		cfg, err := s.applyTelegraf(ctx, userID, t)
 		if err != nil {
 			return &applyErrBody{
-				name: cfg.Name,
+				name: t.Name(),
 				msg:  err.Error(),
 			}
 		}
 
 		mutex.Do(func() {
 			teles[i].config = cfg
+			teles[i].action = newApplyAction(t.existing != nil)
 			rollbackTelegrafs = append(rollbackTelegrafs, teles[i])
 		})
 
@@ -1890,15 +3846,55 @@ func (s *Service) applyTelegrafs(teles []*telegraf) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindTelegraf,
 			fn: func(_ influxdb.ID) error {
-				return s.deleteByIDs("telegraf", len(rollbackTelegrafs), s.teleSVC.DeleteTelegrafConfig, func(i int) influxdb.ID {
-					return rollbackTelegrafs[i].ID()
-				})
+				return s.rollbackTelegrafs(rollbackUserID, rollbackTelegrafs)
 			},
 		},
 	}
 }
 
+func (s *Service) rollbackTelegrafs(userID influxdb.ID, teles []*telegraf) error {
+	var errs []string
+	for _, t := range teles {
+		if t.existing == nil {
+			err := s.teleSVC.DeleteTelegrafConfig(context.Background(), t.ID())
+			if err != nil {
+				errs = append(errs, t.ID().String())
+			}
+			continue
+		}
+
+		_, err := s.teleSVC.UpdateTelegrafConfig(context.Background(), t.ID(), t.existing, userID)
+		if err != nil {
+			errs = append(errs, t.ID().String())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf(`telegraf_ids=[%s] err="unable to rollback"`, strings.Join(errs, ", "))
+	}
+
+	return nil
+}
+
+func (s *Service) applyTelegraf(ctx context.Context, userID influxdb.ID, t telegraf) (influxdb.TelegrafConfig, error) {
+	cfg := t.summarize().TelegrafConfig
+
+	if t.existing != nil {
+		updatedCfg, err := s.teleSVC.UpdateTelegrafConfig(ctx, t.ID(), &cfg, userID)
+		if err != nil {
+			return influxdb.TelegrafConfig{}, err
+		}
+		return *updatedCfg, nil
+	}
+
+	if err := s.teleSVC.CreateTelegrafConfig(ctx, &cfg, userID); err != nil {
+		return influxdb.TelegrafConfig{}, err
+	}
+	return cfg, nil
+}
+
 func (s *Service) applyVariables(vars []*variable) applier {
 	const resource = "variable"
 
@@ -1911,7 +3907,13 @@ func (s *Service) applyVariables(vars []*variable) applier {
 			vars[i].OrgID = orgID
 			v = *vars[i]
 		})
+		if v.action == ApplyActionSkipped {
+			return nil
+		}
 		if !v.shouldApply() {
+			mutex.Do(func() {
+				vars[i].action = ApplyActionUnchanged
+			})
 			return nil
 		}
 		influxVar, err := s.applyVariable(ctx, v)
@@ -1924,6 +3926,7 @@ func (s *Service) applyVariables(vars []*variable) applier {
 
 		mutex.Do(func() {
 			vars[i].id = influxVar.ID
+			vars[i].action = newApplyAction(v.existing != nil)
 			rollBackVars = append(rollBackVars, vars[i])
 		})
 		return nil
@@ -1936,6 +3939,7 @@ func (s *Service) applyVariables(vars []*variable) applier {
 		},
 		rollbacker: rollbacker{
 			resource: resource,
+			kind:     KindVariable,
 			fn:       func(_ influxdb.ID) error { return s.rollbackVariables(rollBackVars) },
 		},
 	}
@@ -1994,7 +3998,19 @@ func (s *Service) applyVariable(ctx context.Context, v variable) (influxdb.Varia
 	return influxVar, nil
 }
 
+// LabelMappingBatchCreator is an optional interface a label service may implement
+// to create many label mappings in a single call. When the label service provided
+// to the Service implements it, the label mappings of a pkg are applied in one batch.
+// The batch is expected to be all or nothing, a failed batch is not rolled back.
+type LabelMappingBatchCreator interface {
+	BatchCreateLabelMappings(ctx context.Context, mappings []*influxdb.LabelMapping) error
+}
+
 func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applier {
+	if batcher, ok := s.labelSVC.(LabelMappingBatchCreator); ok {
+		return s.applyLabelMappingsBatch(batcher, labelMappings)
+	}
+
 	const resource = "label_mapping"
 
 	mutex := new(doMutex)
@@ -2005,13 +4021,7 @@ func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applie
 		mutex.Do(func() {
 			mapping = labelMappings[i]
 		})
-		if mapping.exists || mapping.LabelID == 0 || mapping.ResourceID == 0 {
-			// this block here does 2 things, it does not write a
-			// mapping when one exists. it also avoids having to worry
-			// about deleting an existing mapping since it will not be
-			// passed to the delete function below b/c it is never added
-			// to the list of mappings that is referenced in the delete
-			// call.
+		if !mapping.shouldApply() {
 			return nil
 		}
 
@@ -2047,6 +4057,53 @@ func (s *Service) applyLabelMappings(labelMappings []SummaryLabelMapping) applie
 	}
 }
 
+func (s *Service) applyLabelMappingsBatch(batcher LabelMappingBatchCreator, labelMappings []SummaryLabelMapping) applier {
+	const resource = "label_mapping"
+
+	var mappings []*influxdb.LabelMapping
+	for _, mapping := range labelMappings {
+		if !mapping.shouldApply() {
+			continue
+		}
+		mappings = append(mappings, &influxdb.LabelMapping{
+			LabelID:      influxdb.ID(mapping.LabelID),
+			ResourceID:   influxdb.ID(mapping.ResourceID),
+			ResourceType: mapping.ResourceType,
+		})
+	}
+
+	var rollbackMappings []influxdb.LabelMapping
+	createFn := func(ctx context.Context, _ int, orgID, userID influxdb.ID) *applyErrBody {
+		if err := batcher.BatchCreateLabelMappings(ctx, mappings); err != nil {
+			return &applyErrBody{
+				name: fmt.Sprintf("batch of %d", len(mappings)),
+				msg:  err.Error(),
+			}
+		}
+
+		for _, m := range mappings {
+			rollbackMappings = append(rollbackMappings, *m)
+		}
+		return nil
+	}
+
+	entries := 1
+	if len(mappings) == 0 {
+		entries = 0
+	}
+
+	return applier{
+		creater: creater{
+			entries: entries,
+			fn:      createFn,
+		},
+		rollbacker: rollbacker{
+			resource: resource,
+			fn:       func(_ influxdb.ID) error { return s.rollbackLabelMappings(rollbackMappings) },
+		},
+	}
+}
+
 func (s *Service) rollbackLabelMappings(mappings []influxdb.LabelMapping) error {
 	var errs []string
 	for i := range mappings {
@@ -2115,7 +4172,10 @@ type (
 
 	rollbacker struct {
 		resource string
-		fn       func(orgID influxdb.ID) error
+		// kind is the kind of the resources rolled back, it is empty for the
+		// secrets and label mappings, which are always rolled back.
+		kind Kind
+		fn   func(orgID influxdb.ID) error
 	}
 
 	creater struct {
@@ -2127,10 +4187,59 @@ type (
 type rollbackCoordinator struct {
 	rollbacks []rollbacker
 
-	sem chan struct{}
+	// applied counts the resources successfully applied, and therefore
+	// rolled back on failure, by the index of their rollbacker.
+	mu      sync.Mutex
+	applied map[int]int
+
+	// failedResource and failedErr identify the first applier to fail,
+	// reported to the observerFn when the apply is rolled back.
+	failedResource string
+	failedErr      error
+
+	// noRollbackKinds are the kinds left in place when the apply is rolled back.
+	noRollbackKinds map[Kind]bool
+
+	sem        chan struct{}
+	observerFn ApplyRollbackFn
+}
+
+func (r *rollbackCoordinator) incApplied(rollbackIdx int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.applied == nil {
+		r.applied = make(map[int]int)
+	}
+	r.applied[rollbackIdx]++
+}
+
+func (r *rollbackCoordinator) setFailed(resource string, errBody applyErrBody) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.failedErr != nil {
+		return
+	}
+	r.failedResource = resource
+	r.failedErr = applyErrs{&errBody}.toError(resource, "failed to create")
 }
 
+// runTilEnd runs all the appliers concurrently and returns the aggregate of their
+// errors. When the ctx is done, whether before or while the appliers run, the ctx's
+// error is returned instead, so that the apply is rolled back and no later appliers
+// are run.
 func (r *rollbackCoordinator) runTilEnd(ctx context.Context, orgID, userID influxdb.ID, appliers ...applier) error {
+	if err := ctx.Err(); err != nil {
+		return cancelledErr(err)
+	}
+
+	var entries int
+	for _, app := range appliers {
+		entries += app.creater.entries
+	}
+	if entries == 1 {
+		return r.runSingle(ctx, orgID, userID, appliers)
+	}
+
 	errStr := newErrStream(ctx)
 
 	wg := new(sync.WaitGroup)
@@ -2139,6 +4248,7 @@ func (r *rollbackCoordinator) runTilEnd(ctx context.Context, orgID, userID influ
 		// that temp var gets recycled between iterations
 		app := appliers[i]
 		r.rollbacks = append(r.rollbacks, app.rollbacker)
+		rollbackIdx := len(r.rollbacks) - 1
 		for idx := range make([]struct{}, app.creater.entries) {
 			r.sem <- struct{}{}
 			wg.Add(1)
@@ -2153,26 +4263,130 @@ func (r *rollbackCoordinator) runTilEnd(ctx context.Context, orgID, userID influ
 				defer cancel()
 
 				if err := app.creater.fn(ctx, i, orgID, userID); err != nil {
+					r.setFailed(resource, *err)
 					errStr.add(errMsg{resource: resource, err: *err})
+					return
 				}
+				r.incApplied(rollbackIdx)
 			}(idx, app.rollbacker.resource)
 		}
 	}
 	wg.Wait()
 
-	return errStr.close()
+	err := errStr.close()
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		// the errStream drops errors once the ctx is done, the cancellation is
+		// what failed the appliers
+		return cancelledErr(ctxErr)
+	}
+	return err
+}
+
+// runSingle behaves as runTilEnd for appliers that contain exactly one
+// creater entry between them, without the goroutine and channels of the
+// errStream that are only needed to collect concurrent errors.
+func (r *rollbackCoordinator) runSingle(ctx context.Context, orgID, userID influxdb.ID, appliers []applier) error {
+	var errBody *applyErrBody
+	var resource string
+	for _, app := range appliers {
+		r.rollbacks = append(r.rollbacks, app.rollbacker)
+		if app.creater.entries == 0 {
+			continue
+		}
+
+		resource = app.rollbacker.resource
+		errBody = func() *applyErrBody {
+			ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			defer cancel()
+			return app.creater.fn(ctx, 0, orgID, userID)
+		}()
+		if errBody == nil {
+			r.incApplied(len(r.rollbacks) - 1)
+		} else {
+			r.setFailed(resource, *errBody)
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		return cancelledErr(err)
+	}
+	if errBody == nil {
+		return nil
+	}
+	return applyErrs{errBody}.toError(resource, "failed to create")
+}
+
+// cancelledErr is the error of an apply whose ctx was done before all of its
+// appliers completed.
+func cancelledErr(err error) error {
+	return fmt.Errorf("pkg apply cancelled: %w", err)
 }
 
-func (r *rollbackCoordinator) rollback(l *zap.Logger, err *error, orgID influxdb.ID) {
+func (r *rollbackCoordinator) rollback(ctx context.Context, l *zap.Logger, err *error, orgID influxdb.ID) {
 	if *err == nil {
 		return
 	}
 
-	for _, r := range r.rollbacks {
-		if err := r.fn(orgID); err != nil {
-			l.Error("failed to delete "+r.resource, zap.Error(err))
+	l.Info("rolling back pkg apply", zap.Stringer("org_id", orgID))
+
+	// the appliers are rolled back in the reverse of the order they were run,
+	// so that a resource is rolled back before the resources it depends on. For
+	// instance, the notification rules are deleted before the notification
+	// endpoints they reference.
+	var rolledBack, failed, skipped int
+	for i := len(r.rollbacks) - 1; i >= 0; i-- {
+		rb := r.rollbacks[i]
+		r.mu.Lock()
+		count := r.applied[i]
+		r.mu.Unlock()
+
+		if r.noRollbackKinds[rb.kind] {
+			skipped += count
+			l.Warn("skipped rolling back "+rb.resource,
+				zap.String("resource", rb.resource),
+				zap.Int("count", count),
+			)
+			continue
+		}
+
+		if err := rb.fn(orgID); err != nil {
+			failed++
+			l.Error("failed to delete "+rb.resource,
+				zap.String("resource", rb.resource),
+				zap.Int("count", count),
+				zap.Error(err),
+			)
+			continue
 		}
+		rolledBack += count
+		l.Debug("rolled back "+rb.resource,
+			zap.String("resource", rb.resource),
+			zap.Int("count", count),
+		)
 	}
+
+	l.Info("rolled back pkg apply",
+		zap.Stringer("org_id", orgID),
+		zap.Int("resources_rolled_back", rolledBack),
+		zap.Int("failed_rollbacks", failed),
+		zap.Int("skipped_rollbacks", skipped),
+	)
+
+	if r.observerFn == nil {
+		return
+	}
+	rb := ApplyRollback{
+		Err:             *err,
+		RolledBack:      rolledBack,
+		FailedRollbacks: failed,
+		Skipped:         skipped,
+	}
+	r.mu.Lock()
+	if r.failedErr != nil {
+		rb.Resource, rb.Err = r.failedResource, r.failedErr
+	}
+	r.mu.Unlock()
+	r.observerFn(ctx, orgID, rb)
 }
 
 type errMsg struct {
@@ -2257,12 +4471,20 @@ func (a applyErrs) toError(resType, msg string) error {
 	return errors.New(errMsg)
 }
 
+// validURLs validates the urls of a stack. A url provided more than once is rejected,
+// as each url of a stack is applied in turn and a repeated url would apply its pkg twice.
 func validURLs(urls []string) error {
+	seen := make(map[string]bool, len(urls))
 	for _, u := range urls {
 		if _, err := url.Parse(u); err != nil {
 			msg := fmt.Sprintf("url invalid for entry %q", u)
 			return toInfluxError(influxdb.EInvalid, msg)
 		}
+		if seen[u] {
+			msg := fmt.Sprintf("url duplicated for entry %q", u)
+			return toInfluxError(influxdb.EInvalid, msg)
+		}
+		seen[u] = true
 	}
 	return nil
 }
@@ -8,6 +8,9 @@ import (
 	"math/rand"
 	"regexp"
 	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -19,7 +22,9 @@ import (
 	"github.com/influxdata/influxdb/notification/rule"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest"
+	"go.uber.org/zap/zaptest/observer"
 )
 
 func TestService(t *testing.T) {
@@ -40,7 +45,15 @@ func TestService(t *testing.T) {
 			o(&opt)
 		}
 
+		applyGraph := opt.applyGraph
+		if applyGraph == nil {
+			applyGraph = DefaultApplyGraph()
+		}
+
 		return NewService(
+			WithApplyGraph(applyGraph),
+			WithApplyQuota(opt.applyQuotaFn),
+			WithApplyRollbackObserver(opt.applyRollbackFn),
 			WithIDGenerator(opt.idGen),
 			WithTimeGenerator(opt.timeGen),
 			WithStore(opt.store),
@@ -93,6 +106,7 @@ func TestService(t *testing.T) {
 							Description:    "bucket 1 description",
 							RetentionRules: retentionRules{newRetentionRule(time.Hour)},
 						},
+						RetentionShrink: true,
 					}
 					assert.Contains(t, diff.Buckets, expected)
 				})
@@ -121,6 +135,89 @@ func TestService(t *testing.T) {
 					assert.Contains(t, diff.Buckets, expected)
 				})
 			})
+
+			t.Run("bucket mapped to an existing id", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{
+							ID:              id,
+							OrgID:           influxdb.ID(100),
+							Name:            "renamed bucket",
+							Description:     "old desc",
+							RetentionPeriod: 30 * time.Hour,
+						}, nil
+					}
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg,
+						ApplyWithExistingResourceIDs(map[string]influxdb.ID{"rucket_11": 3}),
+					)
+					require.NoError(t, err)
+
+					require.Len(t, diff.Buckets, 2)
+
+					expected := DiffBucket{
+						ID:   SafeID(3),
+						Name: "rucket_11",
+						Old: &DiffBucketValues{
+							Description:    "old desc",
+							RetentionRules: retentionRules{newRetentionRule(30 * time.Hour)},
+						},
+						New: DiffBucketValues{
+							Description:    "bucket 1 description",
+							RetentionRules: retentionRules{newRetentionRule(time.Hour)},
+						},
+						RetentionShrink: true,
+					}
+					assert.Contains(t, diff.Buckets, expected)
+					assert.Equal(t, 1, fakeBktSVC.FindBucketByIDCalls.Count())
+				})
+			})
+
+			t.Run("bucket mapped to an id outside the org", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id, OrgID: influxdb.ID(9000), Name: "other org"}, nil
+					}
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, _, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg,
+						ApplyWithExistingResourceIDs(map[string]influxdb.ID{"rucket_11": 3}),
+					)
+					require.Error(t, err)
+					assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+				})
+			})
+
+			t.Run("diffs only the buckets", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						if name != "rucket_11" {
+							return nil, errors.New("not found")
+						}
+						return &influxdb.Bucket{ID: 1, OrgID: orgID, Name: name}, nil
+					}
+					fakeLabelSVC := mock.NewLabelService()
+					svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+
+					diffs, err := svc.DryRunBuckets(context.TODO(), influxdb.ID(100), pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diffs, 2)
+					for _, d := range diffs {
+						assert.Equal(t, d.Name != "rucket_11", d.IsNew())
+					}
+
+					assert.Zero(t, fakeLabelSVC.FindLabelsCalls.Count())
+					assert.False(t, pkg.isVerified)
+				})
+			})
 		})
 
 		t.Run("checks", func(t *testing.T) {
@@ -162,21 +259,78 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("check details", func(t *testing.T) {
+			testfileRunner(t, "testdata/checks.yml", func(t *testing.T, pkg *Pkg) {
+				fakeCheckSVC := mock.NewCheckService()
+				existing := &icheck.Deadman{
+					Base: icheck.Base{
+						ID:     influxdb.ID(1),
+						Name:   "display name",
+						TaskID: influxdb.ID(2),
+					},
+					Level:     notification.Critical,
+					StaleTime: toNotificationDuration(10 * time.Minute),
+					TimeSince: toNotificationDuration(90 * time.Second),
+				}
+				fakeCheckSVC.FindCheckFn = func(ctx context.Context, f influxdb.CheckFilter) (influxdb.Check, error) {
+					if f.Name != nil && *f.Name == "display name" {
+						return existing, nil
+					}
+					return nil, errors.New("not found")
+				}
+
+				fakeTaskSVC := mock.NewTaskService()
+				fakeTaskSVC.FindTaskByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+					return &influxdb.Task{ID: id, Status: string(influxdb.Inactive)}, nil
+				}
+
+				svc := newTestService(WithCheckSVC(fakeCheckSVC), WithTaskSVC(fakeTaskSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Checks, 2)
+				newCheck := diff.Checks[0]
+				assert.Nil(t, newCheck.OldDetails)
+				assert.Empty(t, newCheck.ChangedDetails())
+
+				updated := diff.Checks[1]
+				require.NotNil(t, updated.OldDetails)
+				assert.Equal(t, influxdb.Inactive, updated.OldDetails.Status)
+				assert.Equal(t, influxdb.Active, updated.NewDetails.Status)
+				assert.Empty(t, updated.OldDetails.Query)
+				assert.Contains(t, updated.NewDetails.Query, `from(bucket: "rucket_1")`)
+
+				expectedThresholds := []DiffCheckThreshold{{
+					Type:      "deadman",
+					Level:     "CRIT",
+					StaleTime: "10m0s",
+					TimeSince: "1m30s",
+				}}
+				assert.Equal(t, expectedThresholds, updated.OldDetails.Thresholds)
+				assert.Equal(t, expectedThresholds, updated.NewDetails.Thresholds)
+
+				assert.Equal(t, []string{"status", "query"}, updated.ChangedDetails())
+			})
+		})
+
 		t.Run("labels", func(t *testing.T) {
 			t.Run("two labels updated", func(t *testing.T) {
 				testfileRunner(t, "testdata/label.json", func(t *testing.T, pkg *Pkg) {
 					fakeLabelSVC := mock.NewLabelService()
 					fakeLabelSVC.FindLabelsFn = func(_ context.Context, filter influxdb.LabelFilter) ([]*influxdb.Label, error) {
-						return []*influxdb.Label{
-							{
+						var labels []*influxdb.Label
+						for _, name := range []string{"label_1", "label_2", "display name"} {
+							labels = append(labels, &influxdb.Label{
 								ID:   influxdb.ID(1),
-								Name: filter.Name,
+								Name: name,
 								Properties: map[string]string{
 									"color":       "old color",
 									"description": "old description",
 								},
-							},
-						}, nil
+							})
+						}
+						return labels, nil
 					}
 					svc := newTestService(WithLabelSVC(fakeLabelSVC))
 
@@ -203,6 +357,8 @@ func TestService(t *testing.T) {
 					expected.New.Color = "#000000"
 					expected.New.Description = "label 2 description"
 					assert.Contains(t, diff.Labels, expected)
+
+					assert.Equal(t, 1, fakeLabelSVC.FindLabelsCalls.Count())
 				})
 			})
 
@@ -336,6 +492,7 @@ func TestService(t *testing.T) {
 				assert.Equal(t, influxdb.Active, actual.Status)
 				assert.Equal(t, (10 * time.Minute).String(), actual.Every)
 				assert.Equal(t, (30 * time.Second).String(), actual.Offset)
+				assert.Empty(t, actual.ScheduleErr)
 
 				expectedStatusRules := []SummaryStatusRule{
 					{CurrentLevel: "CRIT", PreviousLevel: "OK"},
@@ -350,6 +507,60 @@ func TestService(t *testing.T) {
 				assert.Equal(t, expectedTagRules, actual.TagRules)
 			})
 
+			t.Run("shows the endpoint change of an existing rule", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
+					newEndpoint := func(id influxdb.ID, name string) influxdb.NotificationEndpoint {
+						return &endpoint.HTTP{
+							Base: endpoint.Base{
+								ID:   &id,
+								Name: name,
+							},
+							Method:     "POST",
+							AuthMethod: "none",
+							URL:        "https://www.example.com/endpoint",
+						}
+					}
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+						return []influxdb.NotificationEndpoint{
+							newEndpoint(1, "endpoint_0"),
+							newEndpoint(2, "endpoint_old"),
+						}, 2, nil
+					}
+
+					fakeRuleStore := mock.NewNotificationRuleStore()
+					fakeRuleStore.FindNotificationRulesF = func(ctx context.Context, f influxdb.NotificationRuleFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+						return []influxdb.NotificationRule{
+							&rule.HTTP{
+								Base: rule.Base{
+									ID:         3,
+									Name:       "rule_0",
+									EndpointID: 2,
+								},
+							},
+						}, 1, nil
+					}
+
+					svc := newTestService(
+						WithNotificationEndpointSVC(fakeEndpointSVC),
+						WithNotificationRuleSVC(fakeRuleStore),
+					)
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.NotificationRules, 1)
+
+					actual := diff.NotificationRules[0]
+					assert.False(t, actual.IsNew())
+					assert.Equal(t, SafeID(2), actual.OldEndpointID)
+					assert.Equal(t, "endpoint_old", actual.OldEndpointName)
+					assert.Equal(t, SafeID(1), actual.EndpointID)
+					assert.Equal(t, "endpoint_0", actual.EndpointName)
+					assert.True(t, actual.EndpointChanged())
+				})
+			})
+
 			t.Run("should error if endpoint name is not in pkg or in platform", func(t *testing.T) {
 				testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
 					svc := newTestService()
@@ -358,6 +569,28 @@ func TestService(t *testing.T) {
 					require.Error(t, err)
 				})
 			})
+
+			t.Run("reports an offset that is not less than every", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, opt ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+						id := influxdb.ID(1)
+						return []influxdb.NotificationEndpoint{
+							&endpoint.HTTP{Base: endpoint.Base{ID: &id, Name: "endpoint_0"}},
+						}, 1, nil
+					}
+
+					pkg.mNotificationRules["rule_UUID"].offset = 10 * time.Minute
+
+					svc := newTestService(WithNotificationEndpointSVC(fakeEndpointSVC))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.NotificationRules, 1)
+					assert.Equal(t, "offset 10m0s must be less than every 10m0s", diff.NotificationRules[0].ScheduleErr)
+				})
+			})
 		})
 
 		t.Run("secrets not returns missing secrets", func(t *testing.T) {
@@ -375,6 +608,45 @@ func TestService(t *testing.T) {
 			})
 		})
 
+		t.Run("tasks", func(t *testing.T) {
+			testfileRunner(t, "testdata/tasks.yml", func(t *testing.T, pkg *Pkg) {
+				fakeTaskSVC := mock.NewTaskService()
+				fakeTaskSVC.FindTasksFn = func(_ context.Context, filter influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+					if filter.OrganizationID == nil || *filter.OrganizationID != influxdb.ID(100) {
+						return nil, 0, errors.New("unexpected org id")
+					}
+					return []*influxdb.Task{
+						{
+							ID:          influxdb.ID(3),
+							Name:        "task_1",
+							Description: "old desc",
+							Cron:        "15 * * * *",
+							Status:      string(influxdb.Active),
+						},
+					}, 1, nil
+				}
+				svc := newTestService(WithTaskSVC(fakeTaskSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Tasks, 2)
+
+				newTask := diff.Tasks[0]
+				assert.Equal(t, "task_0", newTask.Name)
+				assert.True(t, newTask.IsNew())
+				assert.Zero(t, newTask.ID)
+
+				existingTask := diff.Tasks[1]
+				assert.Equal(t, "task_1", existingTask.Name)
+				assert.False(t, existingTask.IsNew())
+				assert.Equal(t, SafeID(3), existingTask.ID)
+				require.NotNil(t, existingTask.Old)
+				assert.Equal(t, "old desc", existingTask.Old.Description)
+				assert.Equal(t, "desc_1", existingTask.New.Description)
+			})
+		})
+
 		t.Run("variables", func(t *testing.T) {
 			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
 				fakeVarSVC := mock.NewVariableService()
@@ -424,116 +696,1302 @@ func TestService(t *testing.T) {
 				assert.Equal(t, expected, diff.Variables[2])
 			})
 		})
-	})
-
-	t.Run("Apply", func(t *testing.T) {
-		t.Run("buckets", func(t *testing.T) {
-			t.Run("successfully creates pkg of buckets", func(t *testing.T) {
-				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
-					fakeBktSVC := mock.NewBucketService()
-					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
-						b.ID = influxdb.ID(b.RetentionPeriod)
-						return nil
-					}
-					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
-						// forces the bucket to be created a new
-						return nil, errors.New("an error")
-					}
-					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
-						return &influxdb.Bucket{ID: id}, nil
-					}
-
-					svc := newTestService(WithBucketSVC(fakeBktSVC))
-
-					orgID := influxdb.ID(9000)
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+		t.Run("variables fetched once for all pkg variables", func(t *testing.T) {
+			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
+				fakeVarSVC := mock.NewVariableService()
+				svc := newTestService(WithVariableSVC(fakeVarSVC))
 
-					require.Len(t, sum.Buckets, 2)
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
 
-					expected := SummaryBucket{
-						ID:                SafeID(time.Hour),
-						OrgID:             SafeID(orgID),
-						Name:              "rucket_11",
-						Description:       "bucket 1 description",
-						RetentionPeriod:   time.Hour,
-						LabelAssociations: []SummaryLabel{},
-					}
-					assert.Contains(t, sum.Buckets, expected)
-				})
+				require.Len(t, diff.Variables, 4)
+				assert.Equal(t, 1, fakeVarSVC.FindVariablesCalls.Count())
 			})
+		})
 
-			t.Run("will not apply bucket if no changes to be applied", func(t *testing.T) {
-				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
-					orgID := influxdb.ID(9000)
+		t.Run("strict names", func(t *testing.T) {
+			newPkg := func(t *testing.T) *Pkg {
+				return newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: ""
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name: ""
+`), EncodingYAML, ValidSkipParseError())
+			}
 
-					pkg.isVerified = true
-					stubExisting := func(name string, id influxdb.ID) {
-						pkgBkt := pkg.mBuckets[name]
-						pkgBkt.existing = &influxdb.Bucket{
-							// makes all pkg changes same as they are on thes existing bucket
-							ID:              id,
-							OrgID:           orgID,
-							Name:            pkgBkt.Name(),
-							Description:     pkgBkt.Description,
-							RetentionPeriod: pkgBkt.RetentionRules.RP(),
-						}
-					}
-					stubExisting("rucket_11", 3)
-					stubExisting("rucket_222", 4)
+			t.Run("skips objects without a name by default", func(t *testing.T) {
+				svc := newTestService()
 
-					fakeBktSVC := mock.NewBucketService()
-					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
-						return &influxdb.Bucket{ID: id}, nil
-					}
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, newPkg(t))
+				require.NoError(t, err)
 
-					svc := newTestService(WithBucketSVC(fakeBktSVC))
+				require.Len(t, diff.Buckets, 1)
+				assert.Equal(t, "rucket_1", diff.Buckets[0].Name)
+			})
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+			t.Run("fails on objects without a name", func(t *testing.T) {
+				svc := newTestService()
 
-					require.Len(t, sum.Buckets, 2)
+				_, _, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, newPkg(t), DryRunWithStrictNames())
+				require.Error(t, err)
 
-					expected := SummaryBucket{
-						ID:                SafeID(3),
-						OrgID:             SafeID(orgID),
-						Name:              "rucket_11",
-						Description:       "bucket 1 description",
-						RetentionPeriod:   time.Hour,
-						LabelAssociations: []SummaryLabel{},
-					}
-					assert.Contains(t, sum.Buckets, expected)
-					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
-					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
-				})
+				assert.Equal(t, influxdb.EUnprocessableEntity, influxdb.ErrorCode(err))
+				assert.Contains(t, err.Error(), `objects[1] kind="Bucket"`)
+				assert.Contains(t, err.Error(), `objects[2] kind="Label"`)
+				assert.NotContains(t, err.Error(), "objects[0]")
 			})
+		})
 
-			t.Run("rolls back all created buckets on an error", func(t *testing.T) {
-				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
-					fakeBktSVC := mock.NewBucketService()
-					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
-						// forces the bucket to be created a new
-						return nil, errors.New("an error")
-					}
-					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
-						if fakeBktSVC.CreateBucketCalls.Count() == 1 {
-							return errors.New("blowed up ")
-						}
-						return nil
-					}
-
-					pkg.mBuckets["copybuck1"] = pkg.mBuckets["rucket_11"]
-					pkg.mBuckets["copybuck2"] = pkg.mBuckets["rucket_11"]
+		t.Run("variables not fetched for a pkg without variables", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				fakeVarSVC := mock.NewVariableService()
+				svc := newTestService(WithVariableSVC(fakeVarSVC))
 
-					svc := newTestService(WithBucketSVC(fakeBktSVC))
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
 
-					orgID := influxdb.ID(9000)
+				assert.Empty(t, diff.Variables)
+				assert.Zero(t, fakeVarSVC.FindVariablesCalls.Count())
+			})
+		})
+
+		t.Run("variables found by name when supported", func(t *testing.T) {
+			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
+				fakeVarSVC := &fakeVarByNameSVC{
+					VariableService: mock.NewVariableService(),
+					findByNameFn: func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Variable, error) {
+						return &influxdb.Variable{
+							ID:             influxdb.ID(len(name)),
+							OrganizationID: orgID,
+							Name:           name,
+						}, nil
+					},
+				}
+				svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Variables, 4)
+				for _, v := range diff.Variables {
+					assert.False(t, v.IsNew(), v.Name)
+				}
+				assert.Zero(t, fakeVarSVC.FindVariablesCalls.Count())
+			})
+		})
+
+		t.Run("variables not found by name are new without scanning the org", func(t *testing.T) {
+			testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
+				fakeVarSVC := &fakeVarByNameSVC{
+					VariableService: mock.NewVariableService(),
+					findByNameFn: func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Variable, error) {
+						return nil, &influxdb.Error{Code: influxdb.ENotFound}
+					},
+				}
+				fakeVarSVC.FindVariablesF = func(_ context.Context, filter influxdb.VariableFilter, opts ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+					return []*influxdb.Variable{{ID: influxdb.ID(1), Name: "VAR_CONST_3"}}, nil
+				}
+				svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Variables, 4)
+				for _, v := range diff.Variables {
+					assert.True(t, v.IsNew(), v.Name)
+					assert.False(t, v.NameCollision, v.Name)
+				}
+				assert.Zero(t, fakeVarSVC.FindVariablesCalls.Count())
+
+				_, diff, err = svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg, ApplyWithCaseInsensitiveVariables())
+				require.NoError(t, err)
+
+				require.Len(t, diff.Variables, 4)
+				assert.Equal(t, "var_const_3", diff.Variables[1].Name)
+				assert.False(t, diff.Variables[1].IsNew())
+				assert.Equal(t, 1, fakeVarSVC.FindVariablesCalls.Count())
+			})
+		})
+
+		t.Run("reports unresolved env refs", func(t *testing.T) {
+			testfileRunner(t, "testdata/env_refs.yml", func(t *testing.T, pkg *Pkg) {
+				svc := newTestService()
+
+				envRefs := map[string]string{
+					"label-1-name-ref":    "label_1",
+					"check-1-name-ref":    "check_1",
+					"dash-1-name-ref":     "dash_1",
+					"endpoint-1-name-ref": "endpoint_1",
+					"rule-1-name-ref":     "rule_1",
+					"task-1-name-ref":     "task_1",
+					"telegraf-1-name-ref": "telegraf_1",
+				}
+				sum, _, _ := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg, ApplyWithEnvRefs(envRefs))
+
+				assert.Equal(t, []string{"bkt-1-name-ref", "var-1-name-ref"}, sum.MissingEnvs)
+
+				require.Len(t, sum.Labels, 1)
+				assert.Equal(t, "label_1", sum.Labels[0].Name)
+				require.Len(t, sum.Buckets, 1)
+				assert.Equal(t, "$bkt-1-name-ref", sum.Buckets[0].Name)
+			})
+		})
+
+		t.Run("variable name collisions", func(t *testing.T) {
+			newFakeVarSVC := func() *mock.VariableService {
+				fakeVarSVC := mock.NewVariableService()
+				fakeVarSVC.FindVariablesF = func(_ context.Context, filter influxdb.VariableFilter, opts ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+					return []*influxdb.Variable{
+						{
+							ID:          influxdb.ID(1),
+							Name:        "VAR_CONST_3",
+							Description: "old desc",
+						},
+					}, nil
+				}
+				return fakeVarSVC
+			}
+
+			t.Run("flags case insensitive match as a collision", func(t *testing.T) {
+				testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
+					svc := newTestService(WithVariableSVC(newFakeVarSVC()))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.Variables, 4)
+					for _, v := range diff.Variables {
+						assert.True(t, v.IsNew())
+						assert.Equal(t, v.Name == "var_const_3", v.NameCollision, v.Name)
+					}
+				})
+			})
+
+			t.Run("matches existing variable when opted into case insensitive names", func(t *testing.T) {
+				testfileRunner(t, "testdata/variables", func(t *testing.T, pkg *Pkg) {
+					svc := newTestService(WithVariableSVC(newFakeVarSVC()))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg, ApplyWithCaseInsensitiveVariables())
+					require.NoError(t, err)
+
+					require.Len(t, diff.Variables, 4)
+					actual := diff.Variables[1]
+					assert.Equal(t, "var_const_3", actual.Name)
+					assert.Equal(t, SafeID(1), actual.ID)
+					assert.False(t, actual.IsNew())
+					assert.False(t, actual.NameCollision)
+				})
+			})
+		})
+
+		t.Run("label mappings only", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeLabelSVC := mock.NewLabelService()
+				fakeLabelSVC.FindResourceLabelsFn = func(_ context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+					if f.ResourceID != 1 {
+						return nil, nil
+					}
+					return []*influxdb.Label{{ID: 10, Name: "label_1"}}, nil
+				}
+
+				pkg.mBuckets["rucket_1"].existing = &influxdb.Bucket{ID: 1, Name: "rucket_1"}
+
+				svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+
+				diffs, err := svc.DryRunLabelMappings(context.TODO(), pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diffs, 4)
+				for _, d := range diffs {
+					assert.Equal(t, influxdb.BucketsResourceType, d.ResType)
+					isExisting := d.ResName == "rucket_1" && d.LabelName == "label_1"
+					assert.Equal(t, !isExisting, d.IsNew, d.ResName+":"+d.LabelName)
+				}
+				assert.Equal(t, 1, fakeLabelSVC.FindResourceLabelsCalls.Count())
+				assert.Zero(t, fakeBktSVC.FindBucketByNameCalls.Count())
+			})
+		})
+
+		t.Run("apply quota", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					return nil, errors.New("not found")
+				}
+
+				var gotKinds []Kind
+				quotaFn := func(ctx context.Context, orgID influxdb.ID, kind Kind, count int) error {
+					gotKinds = append(gotKinds, kind)
+					if kind == KindBucket && count > 1 {
+						return errors.New("bucket quota exceeded")
+					}
+					return nil
+				}
+
+				svc := newTestService(
+					WithApplyQuota(quotaFn),
+					WithBucketSVC(fakeBktSVC),
+				)
+
+				_, _, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EForbidden, influxdb.ErrorCode(err))
+				assert.Equal(t, []Kind{KindBucket}, gotKinds)
+
+				_, err = svc.Apply(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.Error(t, err)
+				assert.Equal(t, influxdb.EForbidden, influxdb.ErrorCode(err))
+				assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+			})
+		})
+
+		t.Run("apply quota does not count existing telegrafs", func(t *testing.T) {
+			testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
+				fakeTeleSVC := mock.NewTelegrafConfigStore()
+				fakeTeleSVC.FindTelegrafConfigsF = func(_ context.Context, _ influxdb.TelegrafConfigFilter, _ ...influxdb.FindOptions) ([]*influxdb.TelegrafConfig, int, error) {
+					return []*influxdb.TelegrafConfig{{ID: 3, OrgID: 100, Name: "display name"}}, 1, nil
+				}
+
+				var gotKinds []Kind
+				quotaFn := func(ctx context.Context, orgID influxdb.ID, kind Kind, count int) error {
+					gotKinds = append(gotKinds, kind)
+					return errors.New("quota exceeded")
+				}
+
+				svc := newTestService(
+					WithApplyQuota(quotaFn),
+					WithTelegrafSVC(fakeTeleSVC),
+				)
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(100), 0, pkg)
+				require.NoError(t, err)
+				require.Len(t, diff.Telegrafs, 1)
+				assert.False(t, diff.Telegrafs[0].IsNew())
+				assert.Empty(t, gotKinds)
+			})
+		})
+	})
+
+	t.Run("Apply", func(t *testing.T) {
+		t.Run("apply graph", func(t *testing.T) {
+			t.Run("default graph phases", func(t *testing.T) {
+				phases, err := DefaultApplyGraph().phases()
+				require.NoError(t, err)
+
+				expected := [][]Kind{
+					{KindLabel},
+					{
+						KindBucket,
+						KindCheck,
+						KindDashboard,
+						KindNotificationEndpoint,
+						KindTask,
+						KindTelegraf,
+						KindVariable,
+					},
+					{KindNotificationRule},
+				}
+				assert.Equal(t, expected, phases)
+			})
+
+			t.Run("custom kind is placed after its dependencies", func(t *testing.T) {
+				g := DefaultApplyGraph()
+				g[KindCheck] = []Kind{KindLabel, KindNotificationRule}
+
+				phases, err := g.phases()
+				require.NoError(t, err)
+
+				require.Len(t, phases, 4)
+				assert.Equal(t, []Kind{KindCheck}, phases[3])
+			})
+
+			t.Run("rejects invalid graphs", func(t *testing.T) {
+				tests := []struct {
+					name  string
+					graph ApplyGraph
+				}{
+					{
+						name: "dependency cycle",
+						graph: ApplyGraph{
+							KindLabel:  {KindBucket},
+							KindBucket: {KindLabel},
+						},
+					},
+					{
+						name: "unknown dependency",
+						graph: ApplyGraph{
+							KindBucket: {KindLabel},
+						},
+					},
+				}
+
+				for _, tt := range tests {
+					fn := func(t *testing.T) {
+						_, err := tt.graph.phases()
+						require.Error(t, err)
+					}
+					t.Run(tt.name, fn)
+				}
+			})
+		})
+
+		t.Run("applies every kind concurrently", func(t *testing.T) {
+			// this test is most useful when run with the race detector, it asserts
+			// the appliers running concurrently do not step on one another.
+			pkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: CheckDeadman
+metadata:
+  name: check_1
+spec:
+  every: 5m
+  level: cRiT
+  query:  >
+    from(bucket: "rucket_1") |> range(start: v.timeRangeStart, stop: v.timeRangeStop)
+  statusMessageTemplate: "Check: ${ r._check_name } is: ${ r._level }"
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Dashboard
+metadata:
+  name: dash_1
+spec:
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointHTTP
+metadata:
+  name: endpoint_1
+spec:
+  type: none
+  method: get
+  url:  https://www.example.com/endpoint/noneauth
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: rule_1
+spec:
+  endpointName: endpoint_1
+  every: 10m
+  messageTemplate: "Notification Rule: ${ r._notification_rule_name }"
+  statusRules:
+    - currentLevel: WARN
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Task
+metadata:
+  name: task_1
+spec:
+  every: 10m
+  query:  >
+    from(bucket: "rucket_1")
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Telegraf
+metadata:
+  name: tele_1
+spec:
+  config: |
+    [agent]
+      interval = "10s"
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Variable
+metadata:
+  name: var_1
+spec:
+  type: constant
+  values: [first val]
+  associations:
+    - kind: Label
+      name: label_1
+`), EncodingYAML)
+
+			newID := func() influxdb.ID {
+				return influxdb.ID(rand.Int()) + 1
+			}
+
+			fakeLabelSVC := mock.NewLabelService()
+			fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+				l.ID = newID()
+				return nil
+			}
+			fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, m *influxdb.LabelMapping) error {
+				if m.ResourceID == 0 || m.LabelID == 0 {
+					return errors.New("missing id")
+				}
+				return nil
+			}
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+				b.ID = newID()
+				return nil
+			}
+			fakeCheckSVC := mock.NewCheckService()
+			fakeCheckSVC.CreateCheckFn = func(ctx context.Context, c influxdb.CheckCreate, _ influxdb.ID) error {
+				c.SetID(newID())
+				return nil
+			}
+			fakeDashSVC := mock.NewDashboardService()
+			fakeDashSVC.CreateDashboardF = func(_ context.Context, d *influxdb.Dashboard) error {
+				d.ID = newID()
+				return nil
+			}
+			fakeEndpointSVC := mock.NewNotificationEndpointService()
+			fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, e influxdb.NotificationEndpoint, _ influxdb.ID) error {
+				e.SetID(newID())
+				return nil
+			}
+			fakeRuleStore := mock.NewNotificationRuleStore()
+			fakeRuleStore.CreateNotificationRuleF = func(ctx context.Context, nr influxdb.NotificationRuleCreate, _ influxdb.ID) error {
+				if nr.GetEndpointID() == 0 {
+					return errors.New("missing endpoint id")
+				}
+				nr.SetID(newID())
+				return nil
+			}
+			fakeTaskSVC := mock.NewTaskService()
+			fakeTaskSVC.CreateTaskFn = func(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+				return &influxdb.Task{ID: newID()}, nil
+			}
+			fakeTeleSVC := mock.NewTelegrafConfigStore()
+			fakeTeleSVC.CreateTelegrafConfigF = func(_ context.Context, cfg *influxdb.TelegrafConfig, _ influxdb.ID) error {
+				cfg.ID = newID()
+				return nil
+			}
+			fakeVarSVC := mock.NewVariableService()
+			fakeVarSVC.CreateVariableF = func(_ context.Context, v *influxdb.Variable) error {
+				v.ID = newID()
+				return nil
+			}
+
+			svc := newTestService(
+				WithBucketSVC(fakeBktSVC),
+				WithCheckSVC(fakeCheckSVC),
+				WithDashboardSVC(fakeDashSVC),
+				WithLabelSVC(fakeLabelSVC),
+				WithNotificationEndpointSVC(fakeEndpointSVC),
+				WithNotificationRuleSVC(fakeRuleStore),
+				WithTaskSVC(fakeTaskSVC),
+				WithTelegrafSVC(fakeTeleSVC),
+				WithVariableSVC(fakeVarSVC),
+			)
+
+			sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+			require.NoError(t, err)
+
+			assert.Len(t, sum.Buckets, 1)
+			assert.Len(t, sum.Checks, 1)
+			assert.Len(t, sum.Dashboards, 1)
+			assert.Len(t, sum.Labels, 1)
+			assert.Len(t, sum.NotificationEndpoints, 1)
+			assert.Len(t, sum.NotificationRules, 1)
+			assert.Len(t, sum.Tasks, 1)
+			assert.Len(t, sum.TelegrafConfigs, 1)
+			assert.Len(t, sum.Variables, 1)
+			assert.Equal(t, 8, fakeLabelSVC.CreateLabelMappingCalls.Count())
+		})
+
+		t.Run("secrets", func(t *testing.T) {
+			t.Run("rolls back only the secrets provided on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/label.yml", func(t *testing.T, pkg *Pkg) {
+					fakeSecretSVC := mock.NewSecretService()
+					fakeSecretSVC.LoadSecretFn = func(_ context.Context, orgID influxdb.ID, k string) (string, error) {
+						if k == "existing" {
+							return "old val", nil
+						}
+						return "", &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					var patches []map[string]string
+					fakeSecretSVC.PatchSecretsFn = func(_ context.Context, orgID influxdb.ID, m map[string]string) error {
+						patches = append(patches, m)
+						return nil
+					}
+					var deletedKeys []string
+					fakeSecretSVC.DeleteSecretFn = func(_ context.Context, orgID influxdb.ID, ks ...string) error {
+						deletedKeys = append(deletedKeys, ks...)
+						return nil
+					}
+
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						return errors.New("blowed up ")
+					}
+
+					svc := newTestService(WithLabelSVC(fakeLabelSVC), WithSecretSVC(fakeSecretSVC))
+
+					secrets := map[string]string{
+						"existing": "new val",
+						"new":      "new val",
+					}
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithSecrets(secrets))
+					require.Error(t, err)
+
+					assert.Equal(t, []string{"new"}, deletedKeys)
+					require.Len(t, patches, 2)
+					assert.Equal(t, secrets, patches[0])
+					assert.Equal(t, map[string]string{"existing": "old val"}, patches[1])
+				})
+			})
+		})
+
+		t.Run("buckets", func(t *testing.T) {
+			t.Run("successfully creates pkg of buckets", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = influxdb.ID(b.RetentionPeriod)
+						return nil
+					}
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						// forces the bucket to be created a new
+						return nil, errors.New("an error")
+					}
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id}, nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					orgID := influxdb.ID(9000)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 2)
+
+					expected := SummaryBucket{
+						ID:                SafeID(time.Hour),
+						OrgID:             SafeID(orgID),
+						Name:              "rucket_11",
+						Description:       "bucket 1 description",
+						RetentionPeriod:   time.Hour,
+						LabelAssociations: []SummaryLabel{},
+						Action:            ApplyActionCreated,
+					}
+					assert.Contains(t, sum.Buckets, expected)
+				})
+			})
+
+			t.Run("diffs the summaries of reapplying an unchanged pkg as unchanged", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					var mu sync.Mutex
+					existing := make(map[string]influxdb.Bucket)
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						mu.Lock()
+						defer mu.Unlock()
+						b, ok := existing[name]
+						if !ok {
+							return nil, errors.New("not found")
+						}
+						return &b, nil
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						mu.Lock()
+						defer mu.Unlock()
+						b.ID = influxdb.ID(len(existing) + 1)
+						existing[b.Name] = *b
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					orgID := influxdb.ID(9000)
+
+					before, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					after, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, after.Buckets, 2)
+					for _, b := range after.Buckets {
+						assert.Equal(t, ApplyActionUnchanged, b.Action, b.Name)
+					}
+
+					delta := DiffSummaries(before, after)
+					assert.Empty(t, delta.Added)
+					assert.Empty(t, delta.Removed)
+					assert.Empty(t, delta.Changed)
+				})
+			})
+
+			t.Run("overrides bucket retention when provided", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = influxdb.ID(rand.Int()) + 1
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithBucketRetentionOverride(72*time.Hour))
+					require.NoError(t, err)
+
+					require.Len(t, diff.Buckets, 2)
+					for _, b := range diff.Buckets {
+						assert.Equal(t, 72*time.Hour, b.New.RetentionRules.RP())
+					}
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithBucketRetentionOverride(72*time.Hour))
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 2)
+					for _, b := range sum.Buckets {
+						assert.Equal(t, 72*time.Hour, b.RetentionPeriod)
+					}
+
+					// the override is applied to a copy, the provided pkg is unchanged
+					assert.Equal(t, time.Hour, pkg.mBuckets["rucket_11"].RetentionRules.RP())
+					assert.Zero(t, pkg.mBuckets["rucket_222"].RetentionRules.RP())
+				})
+			})
+
+			t.Run("rejects negative bucket retention override", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					svc := newTestService()
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithBucketRetentionOverride(-time.Hour))
+					require.Error(t, err)
+				})
+			})
+
+			t.Run("will not apply bucket if no changes to be applied", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					pkg.isVerified = true
+					stubExisting := func(name string, id influxdb.ID) {
+						pkgBkt := pkg.mBuckets[name]
+						pkgBkt.existing = &influxdb.Bucket{
+							// makes all pkg changes same as they are on thes existing bucket
+							ID:              id,
+							OrgID:           orgID,
+							Name:            pkgBkt.Name(),
+							Description:     pkgBkt.Description,
+							RetentionPeriod: pkgBkt.RetentionRules.RP(),
+						}
+					}
+					stubExisting("rucket_11", 3)
+					stubExisting("rucket_222", 4)
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id}, nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 2)
+
+					expected := SummaryBucket{
+						ID:                SafeID(3),
+						OrgID:             SafeID(orgID),
+						Name:              "rucket_11",
+						Description:       "bucket 1 description",
+						RetentionPeriod:   time.Hour,
+						LabelAssociations: []SummaryLabel{},
+						Action:            ApplyActionUnchanged,
+					}
+					assert.Contains(t, sum.Buckets, expected)
+					assert.Equal(t, map[Kind]int{KindBucket: 2}, sum.SkippedCounts)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+
+			t.Run("applies the shard group duration of a bucket", func(t *testing.T) {
+				pkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  retentionRules:
+    - type: expire
+      everySeconds: 86400
+      shardGroupDurationSeconds: 3600
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_2
+spec:
+  retentionRules:
+    - type: expire
+      everySeconds: 86400
+      shardGroupDurationSeconds: 7200
+`), EncodingYAML)
+
+				fakeBktSVC := mock.NewBucketService()
+				fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+					if name != "rucket_2" {
+						return nil, errors.New("not found")
+					}
+					return &influxdb.Bucket{
+						ID:                 influxdb.ID(2),
+						OrgID:              orgID,
+						Name:               name,
+						RetentionPeriod:    24 * time.Hour,
+						ShardGroupDuration: time.Hour,
+					}, nil
+				}
+				var created influxdb.Bucket
+				fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+					b.ID = influxdb.ID(1)
+					created = *b
+					return nil
+				}
+				var updated influxdb.BucketUpdate
+				fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+					updated = upd
+					return &influxdb.Bucket{ID: id}, nil
+				}
+
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(9000), 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, diff.Buckets, 2)
+				assert.Nil(t, diff.Buckets[0].Old)
+				assert.Equal(t, time.Hour, diff.Buckets[0].New.RetentionRules.ShardGroupDuration())
+				require.NotNil(t, diff.Buckets[1].Old)
+				assert.Equal(t, time.Hour, diff.Buckets[1].Old.RetentionRules.ShardGroupDuration())
+				assert.Equal(t, 2*time.Hour, diff.Buckets[1].New.RetentionRules.ShardGroupDuration())
+
+				_, err = svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+				require.NoError(t, err)
+
+				assert.Equal(t, time.Hour, created.ShardGroupDuration)
+				require.NotNil(t, updated.ShardGroupDuration)
+				assert.Equal(t, 2*time.Hour, *updated.ShardGroupDuration)
+			})
+
+			t.Run("reports the action taken on each bucket", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					orgID := influxdb.ID(9000)
+
+					pkg.isVerified = true
+					pkgBkt := pkg.mBuckets["rucket_11"]
+					pkgBkt.existing = &influxdb.Bucket{
+						ID:              3,
+						OrgID:           orgID,
+						Name:            pkgBkt.Name(),
+						Description:     "old desc",
+						RetentionPeriod: pkgBkt.RetentionRules.RP(),
+					}
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = 4
+						return nil
+					}
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id}, nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 2)
+					actions := make(map[string]ApplyAction)
+					for _, b := range sum.Buckets {
+						actions[b.Name] = b.Action
+					}
+					expected := map[string]ApplyAction{
+						"rucket_11":    ApplyActionUpdated,
+						"display name": ApplyActionCreated,
+					}
+					assert.Equal(t, expected, actions)
+				})
+			})
+
+			t.Run("applies the conflict strategy to buckets matched by name", func(t *testing.T) {
+				newPkg := func(t *testing.T) (*Pkg, influxdb.ID) {
+					pkg := newParsedPkg(t, FromFile("testdata/bucket.yml"), EncodingYAML)
+					orgID := influxdb.ID(9000)
+
+					pkg.isVerified = true
+					pkgBkt := pkg.mBuckets["rucket_11"]
+					pkgBkt.existing = &influxdb.Bucket{
+						ID:              3,
+						OrgID:           orgID,
+						Name:            pkgBkt.Name(),
+						Description:     "old desc",
+						RetentionPeriod: pkgBkt.RetentionRules.RP(),
+					}
+					return pkg, orgID
+				}
+
+				newFakeBktSVC := func() *mock.BucketService {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = 4
+						return nil
+					}
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id}, nil
+					}
+					return fakeBktSVC
+				}
+
+				t.Run("skip", func(t *testing.T) {
+					pkg, orgID := newPkg(t)
+					fakeBktSVC := newFakeBktSVC()
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg, ApplyWithConflictStrategy(ConflictStrategySkip))
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 2)
+					actions := make(map[string]ApplyAction)
+					for _, b := range sum.Buckets {
+						actions[b.Name] = b.Action
+					}
+					expected := map[string]ApplyAction{
+						"rucket_11":    ApplyActionSkipped,
+						"display name": ApplyActionCreated,
+					}
+					assert.Equal(t, expected, actions)
+					assert.Equal(t, map[Kind]int{KindBucket: 1}, sum.SkippedCounts)
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+					assert.Equal(t, 1, fakeBktSVC.CreateBucketCalls.Count())
+				})
+
+				t.Run("skip updates buckets mapped by ID", func(t *testing.T) {
+					pkg, orgID := newPkg(t)
+					fakeBktSVC := newFakeBktSVC()
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg,
+						ApplyWithConflictStrategy(ConflictStrategySkip),
+						ApplyWithExistingResourceIDs(map[string]influxdb.ID{"rucket_11": 3}),
+					)
+					require.NoError(t, err)
+
+					for _, b := range sum.Buckets {
+						assert.NotEqual(t, ApplyActionSkipped, b.Action, b.Name)
+					}
+					assert.Equal(t, 1, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+
+				t.Run("error", func(t *testing.T) {
+					pkg, orgID := newPkg(t)
+					fakeBktSVC := newFakeBktSVC()
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg, ApplyWithConflictStrategy(ConflictStrategyError))
+					require.Error(t, err)
+
+					assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+					assert.Contains(t, err.Error(), `Bucket "rucket_11"`)
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+				})
+			})
+
+			t.Run("transforms the pkg objects before applying them", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						if !strings.HasPrefix(b.Name, "prod_") {
+							return errors.New("bucket name is missing prefix: " + b.Name)
+						}
+						b.ID = influxdb.ID(rand.Int()) + 1
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					prefixName := ApplyWithTransform(func(obj Object) (Object, error) {
+						obj.Spec[fieldName] = "prod_" + obj.Name()
+						return obj, nil
+					})
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, prefixName)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Buckets, 2)
+					var names []string
+					for _, b := range sum.Buckets {
+						names = append(names, b.Name)
+					}
+					assert.ElementsMatch(t, []string{"prod_rucket_11", "prod_rucket_222"}, names)
+					assert.Equal(t, 2, fakeBktSVC.CreateBucketCalls.Count())
+
+					for _, b := range pkg.Summary().Buckets {
+						assert.NotContains(t, b.Name, "prod_")
+					}
+				})
+			})
+
+			t.Run("aborts the apply when a transform fails", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					failing := ApplyWithTransform(func(obj Object) (Object, error) {
+						return Object{}, errors.New("policy violation")
+					})
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, failing)
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), "policy violation")
+
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+				})
+			})
+
+			t.Run("rolls back all created buckets on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						// forces the bucket to be created a new
+						return nil, errors.New("an error")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						if fakeBktSVC.CreateBucketCalls.Count() == 1 {
+							return errors.New("blowed up ")
+						}
+						return nil
+					}
+
+					pkg.mBuckets["copybuck1"] = pkg.mBuckets["rucket_11"]
+					pkg.mBuckets["copybuck2"] = pkg.mBuckets["rucket_11"]
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					orgID := influxdb.ID(9000)
 
 					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
 					require.Error(t, err)
 
-					assert.GreaterOrEqual(t, fakeBktSVC.DeleteBucketCalls.Count(), 1)
+					assert.GreaterOrEqual(t, fakeBktSVC.DeleteBucketCalls.Count(), 1)
+				})
+			})
+
+			t.Run("rolls back the created buckets when the ctx is cancelled", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					ctx, cancel := context.WithCancel(context.Background())
+					defer cancel()
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = influxdb.ID(1)
+						// the bucket is created, but the apply is cancelled before it completes
+						cancel()
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, err := svc.Apply(ctx, influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), "pkg apply cancelled")
+
+					assert.GreaterOrEqual(t, fakeBktSVC.DeleteBucketCalls.Count(), fakeBktSVC.CreateBucketCalls.Count())
+				})
+			})
+
+			t.Run("leaves the created buckets in place when their kind is not rolled back", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						if b.Name == "display name" {
+							return errors.New("blowed up")
+						}
+						b.ID = influxdb.ID(1)
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithNoRollbackKinds(KindBucket))
+					require.Error(t, err)
+
+					assert.Equal(t, 2, fakeBktSVC.CreateBucketCalls.Count())
+					assert.Zero(t, fakeBktSVC.DeleteBucketCalls.Count())
+				})
+			})
+
+			t.Run("rejects an unsupported kind to not roll back", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithNoRollbackKinds(Kind("bukket")))
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), "unsupported kind provided")
+
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+				})
+			})
+
+			t.Run("reports a rollback to the observer", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						return errors.New("blowed up")
+					}
+
+					var (
+						calls    int
+						rollback ApplyRollback
+					)
+					observer := func(_ context.Context, orgID influxdb.ID, rb ApplyRollback) {
+						calls++
+						assert.Equal(t, influxdb.ID(9000), orgID)
+						rollback = rb
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC), WithApplyRollbackObserver(observer))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					require.Equal(t, 1, calls)
+					assert.Equal(t, "bucket", rollback.Resource)
+					require.Error(t, rollback.Err)
+					assert.Contains(t, rollback.Err.Error(), "blowed up")
+					assert.Zero(t, rollback.RolledBack)
+				})
+			})
+
+			t.Run("applies and rolls back a registered kind", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						return errors.New("blowed up")
+					}
+
+					const kindDBRP = Kind("DBRP")
+					graph := DefaultApplyGraph()
+					graph[kindDBRP] = []Kind{KindLabel}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC), WithApplyGraph(graph))
+
+					var dryRuns, applies, rollbacks int
+					err := svc.RegisterKind(kindDBRP, KindFuncs{
+						DryRun: func(_ context.Context, orgID influxdb.ID, _ *Pkg) error {
+							dryRuns++
+							return nil
+						},
+						Apply: func(_ context.Context, orgID, _ influxdb.ID, _ *Pkg) error {
+							applies++
+							assert.Equal(t, influxdb.ID(9000), orgID)
+							return nil
+						},
+						Rollback: func(_ context.Context, orgID influxdb.ID, _ *Pkg) error {
+							rollbacks++
+							return nil
+						},
+					})
+					require.NoError(t, err)
+
+					_, err = svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Equal(t, 1, dryRuns)
+					assert.Equal(t, 1, applies)
+					assert.Equal(t, 1, rollbacks)
+				})
+			})
+
+			t.Run("fails the dry run of a registered kind", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					err := svc.RegisterKind(KindBucket, KindFuncs{
+						DryRun: func(context.Context, influxdb.ID, *Pkg) error {
+							return errors.New("bucket policy violation")
+						},
+					})
+					require.NoError(t, err)
+
+					_, err = svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+					assert.Equal(t, influxdb.EUnprocessableEntity, influxdb.ErrorCode(err))
+
+					assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+				})
+			})
+
+			t.Run("rejects registering a kind missing from the apply graph", func(t *testing.T) {
+				svc := newTestService()
+
+				err := svc.RegisterKind(Kind("DBRP"), KindFuncs{})
+				require.Error(t, err)
+			})
+
+			t.Run("reports the error of a single bucket", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						return errors.New("blowed up")
+					}
+
+					delete(pkg.mBuckets, "rucket_222")
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+					assert.Contains(t, err.Error(), `resource_type="bucket"`)
+					assert.Contains(t, err.Error(), `name="rucket_11"`)
+
+					assert.Equal(t, 1, fakeBktSVC.CreateBucketCalls.Count())
+				})
+			})
+
+			t.Run("logs each rollback action", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("not found")
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						if b.Name == "display name" {
+							return errors.New("blowed up")
+						}
+						b.ID = influxdb.ID(3)
+						return nil
+					}
+
+					core, logs := observer.New(zap.DebugLevel)
+					svc := newTestService(WithBucketSVC(fakeBktSVC))
+					svc.log = zap.New(core)
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					bktLogs := logs.FilterMessage("rolled back bucket").All()
+					require.Len(t, bktLogs, 1)
+					assert.Equal(t, int64(1), bktLogs[0].ContextMap()["count"])
+
+					summaryLogs := logs.FilterMessage("rolled back pkg apply").All()
+					require.Len(t, summaryLogs, 1)
+					fields := summaryLogs[0].ContextMap()
+					assert.Equal(t, int64(1), fields["resources_rolled_back"])
+					assert.Equal(t, int64(0), fields["failed_rollbacks"])
+				})
+			})
+
+			t.Run("safe mode", func(t *testing.T) {
+				newFakeBktSVC := func(existingRP time.Duration) *mock.BucketService {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						if name != "rucket_11" {
+							return nil, errors.New("not found")
+						}
+						return &influxdb.Bucket{
+							ID:              influxdb.ID(1),
+							OrgID:           orgID,
+							Name:            name,
+							RetentionPeriod: existingRP,
+						}, nil
+					}
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id}, nil
+					}
+					return fakeBktSVC
+				}
+
+				t.Run("refuses to shorten a bucket retention", func(t *testing.T) {
+					testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+						fakeBktSVC := newFakeBktSVC(30 * time.Hour)
+						svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+						_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithSafeMode())
+						require.Error(t, err)
+						assert.Equal(t, influxdb.EConflict, influxdb.ErrorCode(err))
+						assert.Contains(t, err.Error(), `bucket "rucket_11" retention shortened from 30h0m0s to 1h0m0s`)
+
+						assert.Zero(t, fakeBktSVC.CreateBucketCalls.Count())
+						assert.Zero(t, fakeBktSVC.UpdateBucketCalls.Count())
+					})
+				})
+
+				t.Run("applies non destructive changes", func(t *testing.T) {
+					testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+						fakeBktSVC := newFakeBktSVC(time.Minute)
+						svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+						_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg, ApplyWithSafeMode())
+						require.NoError(t, err)
+
+						assert.Equal(t, 1, fakeBktSVC.UpdateBucketCalls.Count())
+					})
 				})
 			})
 		})
@@ -638,6 +2096,7 @@ func TestService(t *testing.T) {
 							Color:       "#FFFFFF",
 							Description: "label 1 description",
 						},
+						Action: ApplyActionCreated,
 					})
 
 					assert.Contains(t, sum.Labels, SummaryLabel{
@@ -651,6 +2110,7 @@ func TestService(t *testing.T) {
 							Color:       "#000000",
 							Description: "label 2 description",
 						},
+						Action: ApplyActionCreated,
 					})
 				})
 			})
@@ -733,6 +2193,7 @@ func TestService(t *testing.T) {
 							Color:       "#FFFFFF",
 							Description: "label 1 description",
 						},
+						Action: ApplyActionUnchanged,
 					})
 
 					assert.Contains(t, sum.Labels, SummaryLabel{
@@ -746,6 +2207,7 @@ func TestService(t *testing.T) {
 							Color:       "#000000",
 							Description: "label 2 description",
 						},
+						Action: ApplyActionCreated,
 					})
 
 					assert.Equal(t, 1, fakeLabelSVC.CreateLabelCalls.Count()) // only called for second label
@@ -810,6 +2272,73 @@ func TestService(t *testing.T) {
 					assert.True(t, deletedDashs[1])
 				})
 			})
+
+			t.Run("updates dashboard from a prior stack application", func(t *testing.T) {
+				testfileRunner(t, "testdata/dashboard.yml", func(t *testing.T, pkg *Pkg) {
+					stackID, dashID := influxdb.ID(3), influxdb.ID(9)
+					fakeStore := &fakeStore{
+						readFn: func(ctx context.Context, id influxdb.ID) (Stack, error) {
+							if id != stackID {
+								return Stack{}, errors.New("wrong stack id")
+							}
+							return Stack{
+								ID: stackID,
+								Resources: []StackResource{
+									{ID: dashID, Kind: KindDashboard, Name: "dash_1"},
+								},
+							}, nil
+						},
+					}
+
+					fakeDashSVC := mock.NewDashboardService()
+					fakeDashSVC.FindDashboardByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Dashboard, error) {
+						return &influxdb.Dashboard{
+							ID:    id,
+							Name:  "old name",
+							Cells: []*influxdb.Cell{{ID: 1}},
+						}, nil
+					}
+					fakeDashSVC.GetDashboardCellViewF = func(_ context.Context, _, _ influxdb.ID) (*influxdb.View, error) {
+						return &influxdb.View{}, nil
+					}
+					fakeDashSVC.UpdateDashboardF = func(_ context.Context, id influxdb.ID, upd influxdb.DashboardUpdate) (*influxdb.Dashboard, error) {
+						return &influxdb.Dashboard{ID: id, Name: *upd.Name}, nil
+					}
+					var removedCells []influxdb.ID
+					fakeDashSVC.RemoveDashboardCellF = func(_ context.Context, _, cellID influxdb.ID) error {
+						removedCells = append(removedCells, cellID)
+						return nil
+					}
+					fakeDashSVC.AddDashboardCellF = func(_ context.Context, id influxdb.ID, c *influxdb.Cell, opts influxdb.AddDashboardCellOptions) error {
+						if opts.View == nil {
+							return errors.New("missing cell view")
+						}
+						return nil
+					}
+
+					svc := newTestService(WithDashboardSVC(fakeDashSVC), WithStore(fakeStore))
+
+					orgID := influxdb.ID(9000)
+
+					_, diff, err := svc.DryRun(context.TODO(), orgID, 0, pkg, ApplyWithStackID(stackID))
+					require.NoError(t, err)
+
+					require.Len(t, diff.Dashboards, 1)
+					assert.False(t, diff.Dashboards[0].IsNew())
+					assert.Equal(t, SafeID(dashID), diff.Dashboards[0].ID)
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg, ApplyWithStackID(stackID))
+					require.NoError(t, err)
+
+					require.Len(t, sum.Dashboards, 1)
+					assert.Equal(t, SafeID(dashID), sum.Dashboards[0].ID)
+					assert.Equal(t, "display name", sum.Dashboards[0].Name)
+
+					assert.Equal(t, 0, fakeDashSVC.CreateDashboardCalls.Count())
+					assert.Equal(t, []influxdb.ID{1}, removedCells)
+					assert.Equal(t, 1, fakeDashSVC.AddDashboardCellCalls.Count())
+				})
+			})
 		})
 
 		t.Run("label mapping", func(t *testing.T) {
@@ -907,6 +2436,40 @@ func TestService(t *testing.T) {
 				)
 			})
 
+			t.Run("batches label mappings when label service supports it", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = influxdb.ID(rand.Int())
+						return nil
+					}
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, id influxdb.ID, s string) (*influxdb.Bucket, error) {
+						return nil, errors.New("an error")
+					}
+
+					fakeLabelSVC := &fakeBatchLabelSVC{LabelService: mock.NewLabelService()}
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						l.ID = influxdb.ID(rand.Int())
+						return nil
+					}
+
+					var batches [][]*influxdb.LabelMapping
+					fakeLabelSVC.batchCreateFn = func(_ context.Context, mappings []*influxdb.LabelMapping) error {
+						batches = append(batches, mappings)
+						return nil
+					}
+
+					svc := newTestService(WithBucketSVC(fakeBktSVC), WithLabelSVC(fakeLabelSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, batches, 1)
+					assert.Len(t, batches[0], 4)
+					assert.Zero(t, fakeLabelSVC.CreateLabelMappingCalls.Count())
+				})
+			})
+
 			t.Run("maps checks with labels", func(t *testing.T) {
 				testLabelMappingFn(
 					t,
@@ -1052,9 +2615,122 @@ func TestService(t *testing.T) {
 				)
 			})
 
+			t.Run("reports label mappings of stack resources no longer in the pkg", func(t *testing.T) {
+				testfileRunner(t, "testdata/bucket_associates_label.yml", func(t *testing.T, pkg *Pkg) {
+					const (
+						stackID  = influxdb.ID(1)
+						bucketID = influxdb.ID(3)
+					)
+
+					fakeStore := &fakeStore{
+						readFn: func(ctx context.Context, id influxdb.ID) (Stack, error) {
+							return Stack{
+								ID: stackID,
+								Resources: []StackResource{
+									{APIVersion: APIVersion, ID: bucketID, Kind: KindBucket, Name: "rucket_1"},
+								},
+							}, nil
+						},
+					}
+
+					fakeBktSVC := mock.NewBucketService()
+					fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+						if name != "rucket_1" {
+							return nil, errors.New("not found")
+						}
+						return &influxdb.Bucket{ID: bucketID, OrgID: orgID, Name: name}, nil
+					}
+					fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+						b.ID = influxdb.ID(rand.Int())
+						return nil
+					}
+					fakeBktSVC.UpdateBucketFn = func(_ context.Context, id influxdb.ID, upd influxdb.BucketUpdate) (*influxdb.Bucket, error) {
+						return &influxdb.Bucket{ID: id}, nil
+					}
+
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						l.ID = influxdb.ID(rand.Int())
+						return nil
+					}
+					fakeLabelSVC.FindResourceLabelsFn = func(_ context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+						if f.ResourceID != bucketID {
+							return nil, nil
+						}
+						return []*influxdb.Label{
+							{ID: 10, Name: "label_1"},
+							{ID: 20, Name: "manual"},
+						}, nil
+					}
+					var deleted []influxdb.LabelMapping
+					fakeLabelSVC.DeleteLabelMappingFn = func(_ context.Context, m *influxdb.LabelMapping) error {
+						deleted = append(deleted, *m)
+						return nil
+					}
+
+					svc := newTestService(
+						WithStore(fakeStore),
+						WithBucketSVC(fakeBktSVC),
+						WithLabelSVC(fakeLabelSVC),
+					)
+
+					orgID := influxdb.ID(9000)
+
+					_, diff, err := svc.DryRun(context.TODO(), orgID, 0, pkg, ApplyWithStackID(stackID))
+					require.NoError(t, err)
+
+					var notInPkg []DiffLabelMapping
+					for _, m := range diff.LabelMappings {
+						if m.NotInPkg {
+							notInPkg = append(notInPkg, m)
+						}
+					}
+					expected := DiffLabelMapping{
+						NotInPkg:  true,
+						ResType:   influxdb.BucketsResourceType,
+						ResID:     SafeID(bucketID),
+						ResName:   "rucket_1",
+						LabelID:   20,
+						LabelName: "manual",
+					}
+					assert.Equal(t, []DiffLabelMapping{expected}, notInPkg)
+
+					// the mapping is left in place, so it is not a destructive change
+					_, err = svc.Apply(context.TODO(), orgID, 0, pkg, ApplyWithStackID(stackID), ApplyWithSafeMode())
+					require.NoError(t, err)
+
+					assert.Empty(t, deleted)
+				})
+			})
 		})
 
 		t.Run("notification endpoints", func(t *testing.T) {
+			t.Run("sets the secret keys of the endpoint", func(t *testing.T) {
+				core, logs := observer.New(zap.WarnLevel)
+				svc := NewService(WithLogger(zap.New(core)))
+
+				e := &notificationEndpoint{
+					identity: identity{name: &references{val: "endpoint_1"}},
+					password: &references{},
+					token:    &references{},
+					username: &references{},
+				}
+				svc.setEndpointSecretKeys(e, &endpoint.HTTP{
+					Username: influxdb.SecretField{Key: "1-username"},
+					Password: influxdb.SecretField{Key: "1-password"},
+					Token:    influxdb.SecretField{Key: "1-api-key"},
+				})
+
+				assert.Equal(t, "1-username", e.username.Secret)
+				assert.Equal(t, "1-password", e.password.Secret)
+				assert.Empty(t, e.token.Secret)
+
+				require.Equal(t, 1, logs.Len())
+				fields := logs.All()[0].ContextMap()
+				assert.Equal(t, "endpoint_1", fields["endpoint"])
+				assert.Equal(t, "1-api-key", fields["secret_key"])
+			})
+
 			t.Run("successfully creates pkg of endpoints", func(t *testing.T) {
 				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
 					fakeEndpointSVC := mock.NewNotificationEndpointService()
@@ -1105,27 +2781,97 @@ func TestService(t *testing.T) {
 					fakeEndpointSVC := mock.NewNotificationEndpointService()
 					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
 						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
-						if fakeEndpointSVC.CreateNotificationEndpointCalls.Count() == 5 {
-							return errors.New("hit that kill count")
-						}
+						if fakeEndpointSVC.CreateNotificationEndpointCalls.Count() == 5 {
+							return errors.New("hit that kill count")
+						}
+						return nil
+					}
+
+					// create some dupes
+					for name, endpoint := range pkg.mNotificationEndpoints {
+						pkg.mNotificationEndpoints["copy"+name] = endpoint
+					}
+
+					svc := newTestService(WithNotificationEndpointSVC(fakeEndpointSVC))
+
+					orgID := influxdb.ID(9000)
+
+					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.Error(t, err)
+
+					assert.GreaterOrEqual(t, fakeEndpointSVC.DeleteNotificationEndpointCalls.Count(), 5)
+				})
+			})
+
+			t.Run("applies pkg secrets to existing endpoints on update", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_endpoint.yml", func(t *testing.T, pkg *Pkg) {
+					existingID := influxdb.ID(9)
+					oldToken := "old token"
+					existing := &endpoint.Slack{
+						Base: endpoint.Base{
+							ID:   &existingID,
+							Name: "slack name",
+						},
+						URL:   "https://hooks.slack.com/services/bip/piddy/boppidy",
+						Token: influxdb.SecretField{Key: "9-token", Value: &oldToken},
+					}
+
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+						return []influxdb.NotificationEndpoint{existing}, 1, nil
+					}
+					fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+						nr.SetID(influxdb.ID(fakeEndpointSVC.CreateNotificationEndpointCalls.Count() + 1))
 						return nil
 					}
-
-					// create some dupes
-					for name, endpoint := range pkg.mNotificationEndpoints {
-						pkg.mNotificationEndpoints["copy"+name] = endpoint
+					var updated influxdb.NotificationEndpoint
+					fakeEndpointSVC.UpdateNotificationEndpointF = func(ctx context.Context, id influxdb.ID, nr influxdb.NotificationEndpoint, userID influxdb.ID) (influxdb.NotificationEndpoint, error) {
+						updated = nr
+						return nr, nil
 					}
 
 					svc := newTestService(WithNotificationEndpointSVC(fakeEndpointSVC))
 
-					orgID := influxdb.ID(9000)
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
 
-					_, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.Error(t, err)
+					require.Equal(t, 1, fakeEndpointSVC.UpdateNotificationEndpointCalls.Count())
+					slack, ok := updated.(*endpoint.Slack)
+					require.True(t, ok)
+					assert.Equal(t, "9-token", slack.Token.Key)
+					require.NotNil(t, slack.Token.Value)
+					assert.Equal(t, "tokenval", *slack.Token.Value)
 
-					assert.GreaterOrEqual(t, fakeEndpointSVC.DeleteNotificationEndpointCalls.Count(), 5)
+					// the existing endpoint is left intact for rollbacks
+					assert.Equal(t, "old token", *existing.Token.Value)
 				})
 			})
+
+			t.Run("preserves existing secrets the pkg leaves blank", func(t *testing.T) {
+				existingID := influxdb.ID(9)
+				oldPass := "old password"
+				existing := &endpoint.HTTP{
+					Base:       endpoint.Base{ID: &existingID, Name: "basic"},
+					AuthMethod: "basic",
+					Username:   influxdb.SecretField{Key: "9-username"},
+					Password:   influxdb.SecretField{Key: "9-password", Value: &oldPass},
+				}
+
+				e := notificationEndpoint{
+					kind:     notificationKindHTTP,
+					httpType: notificationHTTPAuthTypeBasic,
+					username: &references{val: "new user"},
+					password: &references{},
+					existing: existing,
+				}
+
+				updated, ok := endpointWithPkgSecrets(e).(*endpoint.HTTP)
+				require.True(t, ok)
+				assert.Equal(t, "9-username", updated.Username.Key)
+				require.NotNil(t, updated.Username.Value)
+				assert.Equal(t, "new user", *updated.Username.Value)
+				assert.Equal(t, existing.Password, updated.Password)
+			})
 		})
 
 		t.Run("notification rules", func(t *testing.T) {
@@ -1213,6 +2959,216 @@ func TestService(t *testing.T) {
 					assert.Equal(t, 1, fakeRuleStore.DeleteNotificationRuleCalls.Count())
 				})
 			})
+
+			t.Run("rolls back notification rules before their endpoints", func(t *testing.T) {
+				pkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointHTTP
+metadata:
+  name: endpoint_1
+spec:
+  type: none
+  method: get
+  url:  https://www.example.com/endpoint/noneauth
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: rule_1
+spec:
+  endpointName: endpoint_1
+  every: 10m
+  messageTemplate: "Notification Rule: ${ r._notification_rule_name }"
+  statusRules:
+    - currentLevel: WARN
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: rule_2
+spec:
+  endpointName: endpoint_1
+  every: 10m
+  messageTemplate: "Notification Rule: ${ r._notification_rule_name }"
+  statusRules:
+    - currentLevel: WARN
+`), EncodingYAML)
+
+				var deletes []string
+
+				fakeEndpointSVC := mock.NewNotificationEndpointService()
+				fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+					nr.SetID(influxdb.ID(9))
+					return nil
+				}
+				fakeEndpointSVC.DeleteNotificationEndpointF = func(ctx context.Context, id influxdb.ID) ([]influxdb.SecretField, influxdb.ID, error) {
+					deletes = append(deletes, "endpoint")
+					return nil, 0, nil
+				}
+
+				fakeRuleStore := mock.NewNotificationRuleStore()
+				fakeRuleStore.CreateNotificationRuleF = func(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+					if fakeRuleStore.CreateNotificationRuleCalls.Count() == 1 {
+						return errors.New("limit hit")
+					}
+					nr.SetID(1)
+					return nil
+				}
+				fakeRuleStore.DeleteNotificationRuleF = func(ctx context.Context, id influxdb.ID) error {
+					deletes = append(deletes, "rule")
+					return nil
+				}
+
+				svc := newTestService(
+					WithNotificationEndpointSVC(fakeEndpointSVC),
+					WithNotificationRuleSVC(fakeRuleStore),
+				)
+
+				_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+				require.Error(t, err)
+
+				assert.Equal(t, []string{"rule", "endpoint"}, deletes)
+			})
+
+			t.Run("refuses to apply a rule with an invalid schedule", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC := mock.NewNotificationEndpointService()
+					fakeEndpointSVC.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+						id := influxdb.ID(9)
+						return []influxdb.NotificationEndpoint{
+							&endpoint.HTTP{Base: endpoint.Base{ID: &id, Name: "endpoint_0"}},
+						}, 1, nil
+					}
+					fakeRuleStore := mock.NewNotificationRuleStore()
+
+					pkg.mNotificationRules["rule_UUID"].offset = time.Hour
+
+					svc := newTestService(
+						WithNotificationEndpointSVC(fakeEndpointSVC),
+						WithNotificationRuleSVC(fakeRuleStore),
+					)
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+					assert.Equal(t, influxdb.EUnprocessableEntity, influxdb.ErrorCode(err))
+
+					assert.Zero(t, fakeRuleStore.CreateNotificationRuleCalls.Count())
+				})
+			})
+
+			newExistingRuleSVCs := func() (*mock.NotificationEndpointService, *mock.NotificationRuleStore, *mock.TaskService) {
+				fakeEndpointSVC := mock.NewNotificationEndpointService()
+				fakeEndpointSVC.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+					id := influxdb.ID(9)
+					return []influxdb.NotificationEndpoint{
+						&endpoint.HTTP{
+							Base: endpoint.Base{
+								ID:   &id,
+								Name: "endpoint_0",
+							},
+						},
+					}, 1, nil
+				}
+
+				fakeRuleStore := mock.NewNotificationRuleStore()
+				fakeRuleStore.FindNotificationRulesF = func(ctx context.Context, f influxdb.NotificationRuleFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationRule, int, error) {
+					return []influxdb.NotificationRule{
+						&rule.HTTP{
+							Base: rule.Base{
+								ID:          3,
+								Name:        "rule_0",
+								Description: "old desc",
+								EndpointID:  9,
+								TaskID:      4,
+							},
+						},
+					}, 1, nil
+				}
+
+				fakeTaskSVC := mock.NewTaskService()
+				fakeTaskSVC.FindTaskByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+					if id != 4 {
+						return nil, errors.New("wrong id")
+					}
+					return &influxdb.Task{ID: id, Status: string(influxdb.Inactive)}, nil
+				}
+				return fakeEndpointSVC, fakeRuleStore, fakeTaskSVC
+			}
+
+			t.Run("updates existing notification rule", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC, fakeRuleStore, fakeTaskSVC := newExistingRuleSVCs()
+					fakeRuleStore.UpdateNotificationRuleF = func(ctx context.Context, id influxdb.ID, nr influxdb.NotificationRuleCreate, userID influxdb.ID) (influxdb.NotificationRule, error) {
+						if id != 3 {
+							return nil, errors.New("wrong id")
+						}
+						return nr.NotificationRule, nil
+					}
+
+					svc := newTestService(
+						WithNotificationEndpointSVC(fakeEndpointSVC),
+						WithNotificationRuleSVC(fakeRuleStore),
+						WithTaskSVC(fakeTaskSVC),
+					)
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, diff.NotificationRules, 1)
+					assert.False(t, diff.NotificationRules[0].IsNew())
+					assert.Equal(t, SafeID(3), diff.NotificationRules[0].ID)
+					require.NotNil(t, diff.NotificationRules[0].Old)
+					assert.Equal(t, "old desc", diff.NotificationRules[0].Old.Description)
+					assert.Equal(t, influxdb.Inactive, diff.NotificationRules[0].Old.Status)
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.NotificationRules, 1)
+					assert.Equal(t, SafeID(3), sum.NotificationRules[0].ID)
+					assert.Equal(t, "desc_0", sum.NotificationRules[0].Description)
+
+					assert.Zero(t, fakeRuleStore.CreateNotificationRuleCalls.Count())
+					assert.Equal(t, 1, fakeRuleStore.UpdateNotificationRuleCalls.Count())
+				})
+			})
+
+			t.Run("restores existing notification rule on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/notification_rule.yml", func(t *testing.T, pkg *Pkg) {
+					fakeEndpointSVC, fakeRuleStore, fakeTaskSVC := newExistingRuleSVCs()
+					var updates []influxdb.NotificationRuleCreate
+					fakeRuleStore.UpdateNotificationRuleF = func(ctx context.Context, id influxdb.ID, nr influxdb.NotificationRuleCreate, userID influxdb.ID) (influxdb.NotificationRule, error) {
+						updates = append(updates, nr)
+						return nr.NotificationRule, nil
+					}
+
+					// the label mapping is applied after the rule, failing here
+					// forces the rule update to be rolled back.
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						l.ID = influxdb.ID(1)
+						return nil
+					}
+					fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, _ *influxdb.LabelMapping) error {
+						return errors.New("expected error")
+					}
+
+					svc := newTestService(
+						WithLabelSVC(fakeLabelSVC),
+						WithNotificationEndpointSVC(fakeEndpointSVC),
+						WithNotificationRuleSVC(fakeRuleStore),
+						WithTaskSVC(fakeTaskSVC),
+					)
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Zero(t, fakeRuleStore.DeleteNotificationRuleCalls.Count())
+					require.Len(t, updates, 2)
+					assert.Equal(t, "old desc", updates[1].GetDescription())
+					assert.Equal(t, influxdb.Inactive, updates[1].Status)
+				})
+			})
 		})
 
 		t.Run("tasks", func(t *testing.T) {
@@ -1253,6 +3209,84 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("updates existing tasks", func(t *testing.T) {
+				testfileRunner(t, "testdata/tasks.yml", func(t *testing.T, pkg *Pkg) {
+					existing := &influxdb.Task{
+						ID:          influxdb.ID(3),
+						Name:        "task_1",
+						Description: "old desc",
+						Flux:        "old flux",
+						Status:      string(influxdb.Inactive),
+					}
+
+					fakeTaskSVC := mock.NewTaskService()
+					fakeTaskSVC.FindTasksFn = func(_ context.Context, _ influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+						return []*influxdb.Task{existing}, 1, nil
+					}
+					fakeTaskSVC.CreateTaskFn = func(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+						return &influxdb.Task{ID: influxdb.ID(1), Description: tc.Description}, nil
+					}
+					var updates []influxdb.TaskUpdate
+					fakeTaskSVC.UpdateTaskFn = func(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+						if id != existing.ID {
+							return nil, errors.New("unexpected task id: " + id.String())
+						}
+						updates = append(updates, upd)
+						return &influxdb.Task{ID: id, Description: *upd.Description}, nil
+					}
+
+					svc := newTestService(WithTaskSVC(fakeTaskSVC))
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					assert.Equal(t, 1, fakeTaskSVC.CreateTaskCalls.Count())
+					require.Len(t, updates, 1)
+					assert.Equal(t, "desc_1", *updates[0].Description)
+					assert.Equal(t, string(influxdb.Active), *updates[0].Status)
+
+					require.Len(t, sum.Tasks, 2)
+					assert.Equal(t, SafeID(existing.ID), sum.Tasks[1].ID)
+				})
+			})
+
+			t.Run("restores existing tasks on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/tasks.yml", func(t *testing.T, pkg *Pkg) {
+					existing := &influxdb.Task{
+						ID:          influxdb.ID(3),
+						Name:        "task_1",
+						Description: "old desc",
+						Flux:        "old flux",
+						Status:      string(influxdb.Inactive),
+					}
+
+					fakeTaskSVC := mock.NewTaskService()
+					fakeTaskSVC.FindTasksFn = func(_ context.Context, _ influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+						return []*influxdb.Task{existing}, 1, nil
+					}
+					fakeTaskSVC.CreateTaskFn = func(ctx context.Context, tc influxdb.TaskCreate) (*influxdb.Task, error) {
+						return nil, errors.New("expected error")
+					}
+					var updates []influxdb.TaskUpdate
+					fakeTaskSVC.UpdateTaskFn = func(ctx context.Context, id influxdb.ID, upd influxdb.TaskUpdate) (*influxdb.Task, error) {
+						updates = append(updates, upd)
+						return &influxdb.Task{ID: id}, nil
+					}
+
+					svc := newTestService(WithTaskSVC(fakeTaskSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Zero(t, fakeTaskSVC.DeleteTaskCalls.Count())
+					require.Len(t, updates, 2)
+					restored := updates[1]
+					assert.Equal(t, existing.Flux, *restored.Flux)
+					assert.Equal(t, existing.Description, *restored.Description)
+					assert.Equal(t, existing.Status, *restored.Status)
+				})
+			})
+
 			t.Run("rolls back all created tasks on an error", func(t *testing.T) {
 				testfileRunner(t, "testdata/tasks.yml", func(t *testing.T, pkg *Pkg) {
 					fakeTaskSVC := mock.NewTaskService()
@@ -1299,6 +3333,92 @@ func TestService(t *testing.T) {
 				})
 			})
 
+			t.Run("updates existing telegraf", func(t *testing.T) {
+				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
+					existing := &influxdb.TelegrafConfig{
+						ID:     3,
+						OrgID:  9000,
+						Name:   "display name",
+						Config: "old config",
+					}
+
+					fakeTeleSVC := mock.NewTelegrafConfigStore()
+					fakeTeleSVC.FindTelegrafConfigsF = func(_ context.Context, _ influxdb.TelegrafConfigFilter, _ ...influxdb.FindOptions) ([]*influxdb.TelegrafConfig, int, error) {
+						return []*influxdb.TelegrafConfig{existing}, 1, nil
+					}
+					var updates []influxdb.TelegrafConfig
+					fakeTeleSVC.UpdateTelegrafConfigF = func(_ context.Context, id influxdb.ID, tc *influxdb.TelegrafConfig, _ influxdb.ID) (*influxdb.TelegrafConfig, error) {
+						if id != existing.ID {
+							return nil, errors.New("wrong id here")
+						}
+						updates = append(updates, *tc)
+						return tc, nil
+					}
+
+					svc := newTestService(WithTelegrafSVC(fakeTeleSVC))
+
+					_, diff, err := svc.DryRun(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+					require.Len(t, diff.Telegrafs, 1)
+					teleDiff := diff.Telegrafs[0]
+					assert.False(t, teleDiff.IsNew())
+					assert.Equal(t, SafeID(existing.ID), teleDiff.ID)
+					require.NotNil(t, teleDiff.Old)
+					assert.Equal(t, "old config", teleDiff.Old.Config)
+					assert.Contains(t, teleDiff.New.Config, "[agent]")
+
+					sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.NoError(t, err)
+
+					assert.Zero(t, fakeTeleSVC.CreateTelegrafConfigCalls.Count())
+					require.Len(t, updates, 1)
+					assert.Equal(t, "desc", updates[0].Description)
+					require.Len(t, sum.TelegrafConfigs, 1)
+					assert.Equal(t, existing.ID, sum.TelegrafConfigs[0].TelegrafConfig.ID)
+				})
+			})
+
+			t.Run("restores existing telegraf on an error", func(t *testing.T) {
+				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
+					existing := &influxdb.TelegrafConfig{
+						ID:     3,
+						OrgID:  9000,
+						Name:   "display name",
+						Config: "old config",
+					}
+
+					fakeTeleSVC := mock.NewTelegrafConfigStore()
+					fakeTeleSVC.FindTelegrafConfigsF = func(_ context.Context, _ influxdb.TelegrafConfigFilter, _ ...influxdb.FindOptions) ([]*influxdb.TelegrafConfig, int, error) {
+						return []*influxdb.TelegrafConfig{existing}, 1, nil
+					}
+					var updates []influxdb.TelegrafConfig
+					fakeTeleSVC.UpdateTelegrafConfigF = func(_ context.Context, id influxdb.ID, tc *influxdb.TelegrafConfig, _ influxdb.ID) (*influxdb.TelegrafConfig, error) {
+						updates = append(updates, *tc)
+						return tc, nil
+					}
+
+					// the label mapping is applied after the telegraf, failing here
+					// forces the telegraf update to be rolled back.
+					fakeLabelSVC := mock.NewLabelService()
+					fakeLabelSVC.CreateLabelFn = func(_ context.Context, l *influxdb.Label) error {
+						l.ID = influxdb.ID(1)
+						return nil
+					}
+					fakeLabelSVC.CreateLabelMappingFn = func(_ context.Context, _ *influxdb.LabelMapping) error {
+						return errors.New("expected error")
+					}
+
+					svc := newTestService(WithTelegrafSVC(fakeTeleSVC), WithLabelSVC(fakeLabelSVC))
+
+					_, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg)
+					require.Error(t, err)
+
+					assert.Zero(t, fakeTeleSVC.DeleteTelegrafConfigCalls.Count())
+					require.Len(t, updates, 2)
+					assert.Equal(t, "old config", updates[1].Config)
+				})
+			})
+
 			t.Run("rolls back all created telegrafs on an error", func(t *testing.T) {
 				testfileRunner(t, "testdata/telegraf.yml", func(t *testing.T, pkg *Pkg) {
 					fakeTeleSVC := mock.NewTelegrafConfigStore()
@@ -1400,32 +3520,219 @@ func TestService(t *testing.T) {
 						},
 					}
 
-					fakeVarSVC := mock.NewVariableService()
-					fakeVarSVC.CreateVariableF = func(_ context.Context, l *influxdb.Variable) error {
-						if l.Name == "var_const" {
-							return errors.New("shouldn't get here")
-						}
-						return nil
-					}
-					fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, v *influxdb.VariableUpdate) (*influxdb.Variable, error) {
-						if id > influxdb.ID(1) {
-							return nil, errors.New("this id should not be updated")
-						}
-						return &influxdb.Variable{ID: id}, nil
+					fakeVarSVC := mock.NewVariableService()
+					fakeVarSVC.CreateVariableF = func(_ context.Context, l *influxdb.Variable) error {
+						if l.Name == "var_const" {
+							return errors.New("shouldn't get here")
+						}
+						return nil
+					}
+					fakeVarSVC.UpdateVariableF = func(_ context.Context, id influxdb.ID, v *influxdb.VariableUpdate) (*influxdb.Variable, error) {
+						if id > influxdb.ID(1) {
+							return nil, errors.New("this id should not be updated")
+						}
+						return &influxdb.Variable{ID: id}, nil
+					}
+
+					svc := newTestService(WithVariableSVC(fakeVarSVC))
+
+					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
+					require.NoError(t, err)
+
+					require.Len(t, sum.Variables, 4)
+					expected := sum.Variables[1]
+					assert.Equal(t, SafeID(1), expected.ID)
+					assert.Equal(t, "var_const_3", expected.Name)
+
+					assert.Equal(t, 3, fakeVarSVC.CreateVariableCalls.Count()) // only called for last 3 labels
+				})
+			})
+		})
+
+		t.Run("prefixes the names of the pkg resources", func(t *testing.T) {
+			pkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name:
+    envRef:
+      key: bkt-name-ref
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationEndpointHTTP
+metadata:
+  name: endpoint_1
+spec:
+  type: none
+  method: get
+  url:  https://www.example.com/endpoint/noneauth
+---
+apiVersion: influxdata.com/v2alpha1
+kind: NotificationRule
+metadata:
+  name: rule_1
+spec:
+  endpointName: endpoint_1
+  every: 10m
+  messageTemplate: "Notification Rule: ${ r._notification_rule_name }"
+  statusRules:
+    - currentLevel: WARN
+`), EncodingYAML)
+
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+				b.ID = 1
+				return nil
+			}
+
+			fakeEndpointSVC := mock.NewNotificationEndpointService()
+			fakeEndpointSVC.FindNotificationEndpointsF = func(ctx context.Context, f influxdb.NotificationEndpointFilter, _ ...influxdb.FindOptions) ([]influxdb.NotificationEndpoint, int, error) {
+				// an endpoint matching the unprefixed name must not be used by the rule
+				id := influxdb.ID(5)
+				return []influxdb.NotificationEndpoint{
+					&endpoint.HTTP{Base: endpoint.Base{ID: &id, Name: "endpoint_1"}},
+				}, 1, nil
+			}
+			fakeEndpointSVC.CreateNotificationEndpointF = func(ctx context.Context, nr influxdb.NotificationEndpoint, userID influxdb.ID) error {
+				nr.SetID(9)
+				return nil
+			}
+
+			var createdRule influxdb.NotificationRule
+			fakeRuleStore := mock.NewNotificationRuleStore()
+			fakeRuleStore.CreateNotificationRuleF = func(ctx context.Context, nr influxdb.NotificationRuleCreate, userID influxdb.ID) error {
+				nr.SetID(3)
+				createdRule = nr.NotificationRule
+				return nil
+			}
+
+			svc := newTestService(
+				WithBucketSVC(fakeBktSVC),
+				WithNotificationEndpointSVC(fakeEndpointSVC),
+				WithNotificationRuleSVC(fakeRuleStore),
+			)
+
+			sum, err := svc.Apply(context.TODO(), influxdb.ID(9000), 0, pkg,
+				ApplyWithEnvRefs(map[string]string{"bkt-name-ref": "rucket_1"}),
+				ApplyWithNamePrefix("tenant_"),
+			)
+			require.NoError(t, err)
+
+			require.Len(t, sum.Buckets, 1)
+			assert.Equal(t, "tenant_rucket_1", sum.Buckets[0].Name)
+			require.Len(t, sum.NotificationEndpoints, 1)
+			assert.Equal(t, "tenant_endpoint_1", sum.NotificationEndpoints[0].NotificationEndpoint.GetName())
+			require.Len(t, sum.NotificationRules, 1)
+			assert.Equal(t, "tenant_rule_1", sum.NotificationRules[0].Name)
+
+			require.NotNil(t, createdRule)
+			assert.Equal(t, "tenant_rule_1", createdRule.GetName())
+			assert.Equal(t, influxdb.ID(9), createdRule.GetEndpointID())
+
+			for _, b := range pkg.Summary().Buckets {
+				assert.NotContains(t, b.Name, "tenant_")
+			}
+		})
+
+		t.Run("warns of a low apply request limit", func(t *testing.T) {
+			newPkg := func(numLabels int) *Pkg {
+				pkg := &Pkg{mLabels: make(map[string]*label)}
+				for i := 0; i < numLabels; i++ {
+					name := "label_" + strconv.Itoa(i)
+					pkg.mLabels[name] = &label{identity: identity{name: &references{val: name}}}
+				}
+				return pkg
+			}
+
+			phases, err := DefaultApplyGraph().phases()
+			require.NoError(t, err)
+
+			tests := []struct {
+				name          string
+				limit         int
+				numLabels     int
+				expectWarning bool
+			}{
+				{name: "low limit with large pkg", limit: 1, numLabels: applyReqLimitWarnMinResources, expectWarning: true},
+				{name: "low limit with small pkg", limit: 1, numLabels: 1},
+				{name: "default limit with large pkg", limit: 5, numLabels: applyReqLimitWarnMinResources},
+			}
+
+			for _, tt := range tests {
+				fn := func(t *testing.T) {
+					core, logs := observer.New(zap.WarnLevel)
+					svc := NewService(WithLogger(zap.New(core)), WithApplyReqLimit(tt.limit))
+
+					svc.warnApplyReqLimit(newPkg(tt.numLabels), phases)
+
+					if !tt.expectWarning {
+						assert.Zero(t, logs.Len())
+						return
+					}
+					require.Equal(t, 1, logs.Len())
+					fields := logs.All()[0].ContextMap()
+					assert.Equal(t, int64(tt.limit), fields["apply_req_limit"])
+					assert.Equal(t, int64(tt.numLabels), fields["resources"])
+				}
+				t.Run(tt.name, fn)
+			}
+		})
+	})
+
+	t.Run("ApplyToOrgs", func(t *testing.T) {
+		newFakeBktSVC := func(failOrgID influxdb.ID) *mock.BucketService {
+			fakeBktSVC := mock.NewBucketService()
+			fakeBktSVC.FindBucketByNameFn = func(_ context.Context, orgID influxdb.ID, name string) (*influxdb.Bucket, error) {
+				return nil, errors.New("not found")
+			}
+			fakeBktSVC.CreateBucketFn = func(_ context.Context, b *influxdb.Bucket) error {
+				if b.OrgID == failOrgID {
+					return errors.New("blowed up")
+				}
+				b.ID = influxdb.ID(fakeBktSVC.CreateBucketCalls.Count() + 1)
+				return nil
+			}
+			return fakeBktSVC
+		}
+
+		t.Run("applies a copy of the pkg to each org", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				fakeBktSVC := newFakeBktSVC(0)
+				svc := newTestService(WithBucketSVC(fakeBktSVC))
+
+				orgIDs := []influxdb.ID{100, 200}
+				summaries, err := svc.ApplyToOrgs(context.TODO(), orgIDs, 0, pkg)
+				require.NoError(t, err)
+
+				require.Len(t, summaries, len(orgIDs))
+				for _, orgID := range orgIDs {
+					sum := summaries[orgID]
+					require.Len(t, sum.Buckets, 2)
+					for _, b := range sum.Buckets {
+						assert.Equal(t, SafeID(orgID), b.OrgID)
+						assert.NotZero(t, b.ID)
 					}
+				}
+				assert.Equal(t, 4, fakeBktSVC.CreateBucketCalls.Count())
 
-					svc := newTestService(WithVariableSVC(fakeVarSVC))
+				for _, b := range pkg.buckets() {
+					assert.Zero(t, b.ID())
+					assert.Zero(t, b.OrgID)
+				}
+			})
+		})
 
-					sum, err := svc.Apply(context.TODO(), orgID, 0, pkg)
-					require.NoError(t, err)
+		t.Run("reports the orgs that failed", func(t *testing.T) {
+			testfileRunner(t, "testdata/bucket.yml", func(t *testing.T, pkg *Pkg) {
+				svc := newTestService(WithBucketSVC(newFakeBktSVC(200)))
 
-					require.Len(t, sum.Variables, 4)
-					expected := sum.Variables[1]
-					assert.Equal(t, SafeID(1), expected.ID)
-					assert.Equal(t, "var_const_3", expected.Name)
+				summaries, err := svc.ApplyToOrgs(context.TODO(), []influxdb.ID{100, 200}, 0, pkg)
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), "org_id="+influxdb.ID(200).String())
+				assert.NotContains(t, err.Error(), "org_id="+influxdb.ID(100).String())
 
-					assert.Equal(t, 3, fakeVarSVC.CreateVariableCalls.Count()) // only called for last 3 labels
-				})
+				require.Len(t, summaries, 1)
+				assert.Len(t, summaries[100].Buckets, 2)
 			})
 		})
 	})
@@ -3033,6 +5340,304 @@ func TestService(t *testing.T) {
 			require.Len(t, vars, 1)
 			assert.Equal(t, "variable", vars[0].Name)
 		})
+
+		t.Run("includes backing tasks when requested", func(t *testing.T) {
+			taskSVC := mock.NewTaskService()
+			taskSVC.FindTasksFn = func(ctx context.Context, f influxdb.TaskFilter) ([]*influxdb.Task, int, error) {
+				return []*influxdb.Task{
+					{ID: 31, Type: influxdb.TaskSystemType},
+					{ID: 32, Type: "threshold"},
+				}, 2, nil
+			}
+			taskSVC.FindTaskByIDFn = func(ctx context.Context, id influxdb.ID) (*influxdb.Task, error) {
+				return &influxdb.Task{
+					ID:    id,
+					Name:  "task_" + id.String(),
+					Every: time.Minute.String(),
+					Flux:  `option task = { name: "larry" } from(bucket: "rucket") |> yield()`,
+				}, nil
+			}
+
+			checkSVC := mock.NewCheckService()
+			checkSVC.FindChecksFn = func(ctx context.Context, f influxdb.CheckFilter, _ ...influxdb.FindOptions) ([]influxdb.Check, int, error) {
+				return nil, 0, errors.New("backing tasks do not require the checks")
+			}
+
+			svc := newTestService(WithTaskSVC(taskSVC), WithCheckSVC(checkSVC))
+
+			pkg, err := svc.CreatePkg(context.TODO(), CreateWithAllOrgResources(CreateByOrgIDOpt{
+				OrgID:               influxdb.ID(9000),
+				ResourceKinds:       []Kind{KindTask},
+				IncludeBackingTasks: true,
+			}))
+			require.NoError(t, err)
+
+			require.Len(t, pkg.Summary().Tasks, 2)
+			assert.Equal(t, 2, taskSVC.FindTaskByIDCalls.Count())
+		})
+
+		t.Run("pages through all labels and variables", func(t *testing.T) {
+			page := func(total int, opts []influxdb.FindOptions) (int, int) {
+				start, end := opts[0].Offset, opts[0].Offset+opts[0].Limit
+				if start > total {
+					start = total
+				}
+				if end > total {
+					end = total
+				}
+				return start, end
+			}
+
+			const numLabels, numVars = 250, 150
+
+			labelSVC := &fakePagedLabelSVC{LabelService: mock.NewLabelService()}
+			labelSVC.findLabelsFn = func(_ context.Context, _ influxdb.LabelFilter, opts ...influxdb.FindOptions) ([]*influxdb.Label, error) {
+				start, end := page(numLabels, opts)
+				var labels []*influxdb.Label
+				for i := start; i < end; i++ {
+					labels = append(labels, &influxdb.Label{ID: influxdb.ID(i + 1)})
+				}
+				return labels, nil
+			}
+			labelSVC.FindLabelByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Label, error) {
+				return &influxdb.Label{ID: id, Name: "label_" + id.String()}, nil
+			}
+
+			varSVC := mock.NewVariableService()
+			varSVC.FindVariablesF = func(_ context.Context, _ influxdb.VariableFilter, opts ...influxdb.FindOptions) ([]*influxdb.Variable, error) {
+				start, end := page(numVars, opts)
+				var vars []*influxdb.Variable
+				for i := start; i < end; i++ {
+					vars = append(vars, &influxdb.Variable{ID: influxdb.ID(i + 1)})
+				}
+				return vars, nil
+			}
+			varSVC.FindVariableByIDF = func(_ context.Context, id influxdb.ID) (*influxdb.Variable, error) {
+				return &influxdb.Variable{
+					ID:   id,
+					Name: "var_" + id.String(),
+					Arguments: &influxdb.VariableArguments{
+						Type:   "constant",
+						Values: influxdb.VariableConstantValues{"a"},
+					},
+				}, nil
+			}
+
+			svc := newTestService(WithLabelSVC(labelSVC), WithVariableSVC(varSVC))
+
+			pkg, err := svc.CreatePkg(context.TODO(), CreateWithAllOrgResources(CreateByOrgIDOpt{
+				OrgID:         influxdb.ID(9000),
+				ResourceKinds: []Kind{KindLabel, KindVariable},
+			}))
+			require.NoError(t, err)
+
+			summary := pkg.Summary()
+			assert.Len(t, summary.Labels, numLabels)
+			assert.Len(t, summary.Variables, numVars)
+		})
+
+		t.Run("aborts clone when context is cancelled", func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+
+			bktSVC := mock.NewBucketService()
+			bktSVC.FindBucketsFn = func(ctx context.Context, _ influxdb.BucketFilter, _ ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+				cancel()
+				<-ctx.Done()
+				return nil, 0, ctx.Err()
+			}
+
+			svc := newTestService(WithBucketSVC(bktSVC))
+
+			_, err := svc.CreatePkg(ctx, CreateWithAllOrgResources(CreateByOrgIDOpt{
+				OrgID:         influxdb.ID(9000),
+				ResourceKinds: []Kind{KindBucket},
+			}))
+			require.Error(t, err)
+			assert.Equal(t, context.Canceled, err)
+		})
+
+		t.Run("clones many orgs concurrently within the apply request limit", func(t *testing.T) {
+			const limit = 2
+
+			var inFlight, maxInFlight int64
+			bktSVC := mock.NewBucketService()
+			bktSVC.FindBucketsFn = func(_ context.Context, f influxdb.BucketFilter, _ ...influxdb.FindOptions) ([]*influxdb.Bucket, int, error) {
+				n := atomic.AddInt64(&inFlight, 1)
+				defer atomic.AddInt64(&inFlight, -1)
+				for {
+					max := atomic.LoadInt64(&maxInFlight)
+					if n <= max || atomic.CompareAndSwapInt64(&maxInFlight, max, n) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+
+				orgID := *f.OrganizationID
+				return []*influxdb.Bucket{{ID: orgID, OrgID: orgID, Name: "bucket_" + orgID.String()}}, 1, nil
+			}
+			bktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+				return &influxdb.Bucket{ID: id, OrgID: id, Name: "bucket_" + id.String()}, nil
+			}
+
+			svc := newTestService(
+				WithBucketSVC(bktSVC),
+				WithLabelSVC(mock.NewLabelService()),
+				WithApplyReqLimit(limit),
+			)
+
+			var setters []CreatePkgSetFn
+			var expectedNames []string
+			for i := 5; i > 0; i-- {
+				orgID := influxdb.ID(i)
+				setters = append(setters, CreateWithAllOrgResources(CreateByOrgIDOpt{
+					OrgID:         orgID,
+					ResourceKinds: []Kind{KindBucket},
+				}))
+				expectedNames = append([]string{"bucket_" + orgID.String()}, expectedNames...)
+			}
+
+			pkg, err := svc.CreatePkg(context.TODO(), setters...)
+			require.NoError(t, err)
+
+			var names []string
+			for _, b := range pkg.Summary().Buckets {
+				names = append(names, b.Name)
+			}
+			assert.Equal(t, expectedNames, names)
+			assert.LessOrEqual(t, atomic.LoadInt64(&maxInFlight), int64(limit))
+		})
+	})
+
+	t.Run("ExportStream", func(t *testing.T) {
+		bkts := map[influxdb.ID]*influxdb.Bucket{
+			1: {ID: 1, Name: "bucket_1", Description: "desc 1", RetentionPeriod: time.Hour},
+			2: {ID: 2, Name: "bucket_2", Description: "desc 2", RetentionPeriod: 2 * time.Hour},
+		}
+
+		encodings := []Encoding{EncodingJSON, EncodingYAML}
+		for _, encoding := range encodings {
+			fn := func(t *testing.T) {
+				bktSVC := mock.NewBucketService()
+				bktSVC.FindBucketByIDFn = func(_ context.Context, id influxdb.ID) (*influxdb.Bucket, error) {
+					b, ok := bkts[id]
+					if !ok {
+						return nil, errors.New("uh ohhh, wrong id here: " + id.String())
+					}
+					return b, nil
+				}
+
+				svc := newTestService(WithBucketSVC(bktSVC), WithLabelSVC(mock.NewLabelService()))
+
+				var buf bytes.Buffer
+				err := svc.ExportStream(context.TODO(), &buf, encoding, CreateWithExistingResources(
+					ResourceToClone{Kind: KindBucket, ID: 1},
+					ResourceToClone{Kind: KindBucket, ID: 2},
+				))
+				require.NoError(t, err)
+
+				pkg, err := Parse(encoding, FromReader(&buf), ValidWithoutResources())
+				require.NoError(t, err)
+
+				sumBkts := pkg.Summary().Buckets
+				require.Len(t, sumBkts, 2)
+				for i, b := range sumBkts {
+					expected := bkts[influxdb.ID(i+1)]
+					assert.Equal(t, expected.Name, b.Name)
+					assert.Equal(t, expected.Description, b.Description)
+					assert.Equal(t, expected.RetentionPeriod, b.RetentionPeriod)
+				}
+			}
+			t.Run(encoding.String(), fn)
+		}
+
+		t.Run("with no resources", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := newTestService().ExportStream(context.TODO(), &buf, EncodingJSON)
+			require.NoError(t, err)
+			assert.Equal(t, "[]\n", buf.String())
+		})
+
+		t.Run("with invalid encoding", func(t *testing.T) {
+			var buf bytes.Buffer
+			err := newTestService().ExportStream(context.TODO(), &buf, EncodingUnknown)
+			require.Error(t, err)
+		})
+	})
+
+	t.Run("ExportResource", func(t *testing.T) {
+		t.Run("exports a rule with its endpoint and labels", func(t *testing.T) {
+			endpointSVC := mock.NewNotificationEndpointService()
+			endpointSVC.FindNotificationEndpointByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationEndpoint, error) {
+				if id != 2 {
+					return nil, errors.New("uh ohhh, wrong id here: " + id.String())
+				}
+				return &endpoint.Slack{
+					Base: endpoint.Base{
+						ID:     newTestIDPtr(2),
+						Name:   "endpoint_0",
+						Status: influxdb.TaskStatusActive,
+					},
+					URL: "http://example.com",
+				}, nil
+			}
+			ruleSVC := mock.NewNotificationRuleStore()
+			ruleSVC.FindNotificationRuleByIDF = func(ctx context.Context, id influxdb.ID) (influxdb.NotificationRule, error) {
+				if id != 1 {
+					return nil, errors.New("uh ohhh, wrong id here: " + id.String())
+				}
+				return &rule.Slack{
+					Base: rule.Base{
+						ID:         1,
+						Name:       "rule_0",
+						EndpointID: 2,
+						Every:      mustDuration(t, time.Hour),
+						StatusRules: []notification.StatusRule{
+							{CurrentLevel: notification.Critical},
+						},
+					},
+					MessageTemplate: "msg",
+				}, nil
+			}
+			labelSVC := mock.NewLabelService()
+			labelSVC.FindResourceLabelsFn = func(_ context.Context, f influxdb.LabelMappingFilter) ([]*influxdb.Label, error) {
+				if f.ResourceID != 1 {
+					return nil, nil
+				}
+				return []*influxdb.Label{{ID: 3, Name: "label_1"}}, nil
+			}
+
+			svc := newTestService(
+				WithLabelSVC(labelSVC),
+				WithNotificationEndpointSVC(endpointSVC),
+				WithNotificationRuleSVC(ruleSVC),
+			)
+
+			pkg, err := svc.ExportResource(context.TODO(), KindNotificationRule, 1)
+			require.NoError(t, err)
+
+			b, err := pkg.Encode(EncodingJSON)
+			require.NoError(t, err)
+
+			newPkg, err := Parse(EncodingJSON, FromReader(bytes.NewReader(b)))
+			require.NoError(t, err)
+
+			sum := newPkg.Summary()
+
+			require.Len(t, sum.NotificationRules, 1)
+			assert.Equal(t, "rule_0", sum.NotificationRules[0].Name)
+			require.Len(t, sum.NotificationEndpoints, 1)
+			assert.Equal(t, "endpoint_0", sum.NotificationEndpoints[0].NotificationEndpoint.GetName())
+			assert.NotEmpty(t, sum.NotificationRules[0].EndpointName)
+			require.Len(t, sum.Labels, 1)
+			assert.Equal(t, "label_1", sum.Labels[0].Name)
+			require.Len(t, sum.NotificationRules[0].LabelAssociations, 1)
+		})
+
+		t.Run("rejects an invalid resource", func(t *testing.T) {
+			_, err := newTestService().ExportResource(context.TODO(), KindNotificationRule, 0)
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EUnprocessableEntity, influxdb.ErrorCode(err))
+		})
 	})
 
 	t.Run("InitStack", func(t *testing.T) {
@@ -3052,7 +5657,7 @@ func TestService(t *testing.T) {
 
 		t.Run("when store call is successful", func(t *testing.T) {
 			svc := newTestService(
-				WithIDGenerator(newFakeIDGen(3)),
+				WithIDGenerator(mock.NewIncrementingIDGenerator(3)),
 				WithTimeGenerator(newTimeGen(now)),
 				WithStore(newFakeStore(safeCreateFn)),
 			)
@@ -3065,6 +5670,20 @@ func TestService(t *testing.T) {
 			assert.Equal(t, now, stack.UpdatedAt)
 		})
 
+		t.Run("generates predictable stack ids", func(t *testing.T) {
+			svc := newTestService(
+				WithIDGenerator(mock.NewIncrementingIDGenerator(3)),
+				WithTimeGenerator(newTimeGen(now)),
+				WithStore(newFakeStore(safeCreateFn)),
+			)
+
+			for _, expected := range []influxdb.ID{3, 4, 5} {
+				stack, err := svc.InitStack(context.Background(), 9000, Stack{OrgID: 3333})
+				require.NoError(t, err)
+				assert.Equal(t, expected, stack.ID)
+			}
+		})
+
 		t.Run("handles unexpected error paths", func(t *testing.T) {
 			tests := []struct {
 				name            string
@@ -3114,7 +5733,7 @@ func TestService(t *testing.T) {
 					}
 
 					svc := newTestService(
-						WithIDGenerator(newFakeIDGen(3)),
+						WithIDGenerator(mock.NewIncrementingIDGenerator(3)),
 						WithTimeGenerator(newTimeGen(now)),
 						WithStore(tt.store()),
 						WithOrganizationService(orgSVC),
@@ -3127,6 +5746,232 @@ func TestService(t *testing.T) {
 				t.Run(tt.name, fn)
 			}
 		})
+
+		t.Run("rejects duplicate urls", func(t *testing.T) {
+			store := newFakeStore(safeCreateFn)
+			svc := newTestService(
+				WithIDGenerator(mock.NewIncrementingIDGenerator(3)),
+				WithTimeGenerator(newTimeGen(now)),
+				WithStore(store),
+			)
+
+			_, err := svc.InitStack(context.Background(), 9000, Stack{
+				OrgID: 3333,
+				URLs: []string{
+					"http://example.com/pkg.yml",
+					"http://example.com/other.yml",
+					"http://example.com/pkg.yml",
+				},
+			})
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EInvalid, influxdb.ErrorCode(err))
+			assert.Contains(t, err.Error(), "http://example.com/pkg.yml")
+		})
+	})
+
+	t.Run("DeleteStack", func(t *testing.T) {
+		stack := Stack{
+			ID:    1,
+			OrgID: 3333,
+			Resources: []StackResource{
+				{APIVersion: APIVersion, ID: 2, Kind: KindBucket, Name: "rucket_1"},
+				{APIVersion: APIVersion, ID: 3, Kind: KindDashboard, Name: "dash_1"},
+			},
+		}
+
+		newFakeStore := func(deletedID *influxdb.ID) *fakeStore {
+			return &fakeStore{
+				readFn: func(ctx context.Context, id influxdb.ID) (Stack, error) {
+					if id != stack.ID {
+						return Stack{}, &influxdb.Error{Code: influxdb.ENotFound}
+					}
+					return stack, nil
+				},
+				deleteFn: func(ctx context.Context, id influxdb.ID) error {
+					*deletedID = id
+					return nil
+				},
+			}
+		}
+
+		t.Run("deletes stack resources by default", func(t *testing.T) {
+			var deletedStackID influxdb.ID
+			fakeBktSVC := mock.NewBucketService()
+			fakeDashSVC := mock.NewDashboardService()
+
+			svc := newTestService(
+				WithStore(newFakeStore(&deletedStackID)),
+				WithBucketSVC(fakeBktSVC),
+				WithDashboardSVC(fakeDashSVC),
+			)
+
+			err := svc.DeleteStack(context.Background(), stack.ID, DeleteStackOpts{})
+			require.NoError(t, err)
+
+			assert.Equal(t, stack.ID, deletedStackID)
+			assert.Equal(t, 1, fakeBktSVC.DeleteBucketCalls.Count())
+			assert.Equal(t, 1, fakeDashSVC.DeleteDashboardCalls.Count())
+		})
+
+		t.Run("retains stack resources when requested", func(t *testing.T) {
+			var deletedStackID influxdb.ID
+			fakeBktSVC := mock.NewBucketService()
+			fakeDashSVC := mock.NewDashboardService()
+
+			svc := newTestService(
+				WithStore(newFakeStore(&deletedStackID)),
+				WithBucketSVC(fakeBktSVC),
+				WithDashboardSVC(fakeDashSVC),
+			)
+
+			err := svc.DeleteStack(context.Background(), stack.ID, DeleteStackOpts{RetainResources: true})
+			require.NoError(t, err)
+
+			assert.Equal(t, stack.ID, deletedStackID)
+			assert.Zero(t, fakeBktSVC.DeleteBucketCalls.Count())
+			assert.Zero(t, fakeDashSVC.DeleteDashboardCalls.Count())
+		})
+
+		t.Run("stack does not exist", func(t *testing.T) {
+			var deletedStackID influxdb.ID
+			svc := newTestService(WithStore(newFakeStore(&deletedStackID)))
+
+			err := svc.DeleteStack(context.Background(), 9000, DeleteStackOpts{})
+			require.Error(t, err)
+			assert.Equal(t, influxdb.ENotFound, influxdb.ErrorCode(err))
+			assert.Zero(t, deletedStackID)
+		})
+	})
+
+	t.Run("Check", func(t *testing.T) {
+		newFakeStore := func(err error) *fakeStore {
+			return &fakeStore{
+				readFn: func(ctx context.Context, id influxdb.ID) (Stack, error) {
+					return Stack{}, err
+				},
+			}
+		}
+
+		t.Run("dependencies are available", func(t *testing.T) {
+			svc := newTestService(WithStore(newFakeStore(&influxdb.Error{Code: influxdb.ENotFound})))
+
+			require.NoError(t, svc.Check(context.Background()))
+		})
+
+		t.Run("store is unavailable", func(t *testing.T) {
+			svc := newTestService(WithStore(newFakeStore(errors.New("connection refused"))))
+
+			err := svc.Check(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EUnavailable, influxdb.ErrorCode(err))
+			assert.Contains(t, err.Error(), "pkger store is unavailable")
+		})
+
+		t.Run("store is not configured", func(t *testing.T) {
+			svc := newTestService()
+
+			err := svc.Check(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EUnavailable, influxdb.ErrorCode(err))
+		})
+
+		t.Run("org service is unavailable", func(t *testing.T) {
+			orgSVC := mock.NewOrganizationService()
+			orgSVC.FindOrganizationsF = func(ctx context.Context, filter influxdb.OrganizationFilter, opt ...influxdb.FindOptions) ([]*influxdb.Organization, int, error) {
+				return nil, 0, errors.New("timed out")
+			}
+			svc := newTestService(
+				WithStore(newFakeStore(nil)),
+				WithOrganizationService(orgSVC),
+			)
+
+			err := svc.Check(context.Background())
+			require.Error(t, err)
+			assert.Equal(t, influxdb.EUnavailable, influxdb.ErrorCode(err))
+			assert.Contains(t, err.Error(), "organization service is unavailable")
+		})
+	})
+
+	t.Run("Lint", func(t *testing.T) {
+		pkg := newParsedPkg(t, FromString(`
+apiVersion: influxdata.com/v2alpha1
+kind: Label
+metadata:
+  name: label_1
+spec:
+  description: label desc
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: rucket_1
+spec:
+  description: bucket desc
+  associations:
+    - kind: Label
+      name: label_1
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Bucket
+metadata:
+  name: tmp
+---
+apiVersion: influxdata.com/v2alpha1
+kind: Variable
+metadata:
+  name: var_1
+spec:
+  type: constant
+  values: [first val]
+`), EncodingYAML)
+
+		svc := newTestService()
+
+		t.Run("no rules provides no warnings", func(t *testing.T) {
+			assert.Empty(t, svc.Lint(pkg, LintRules{}))
+		})
+
+		t.Run("warns on rule violations", func(t *testing.T) {
+			warnings := svc.Lint(pkg, LintRules{
+				RequireLabels:       true,
+				RequireDescriptions: true,
+				ForbiddenNames:      []string{"tmp"},
+			})
+
+			expected := []LintWarning{
+				{
+					Kind: KindBucket,
+					Name: "tmp",
+					Rule: LintRuleForbiddenName,
+					Msg:  `name "tmp" is forbidden`,
+				},
+				{
+					Kind: KindBucket,
+					Name: "tmp",
+					Rule: LintRuleRequireDescriptions,
+					Msg:  "a description is required",
+				},
+				{
+					Kind: KindBucket,
+					Name: "tmp",
+					Rule: LintRuleRequireLabels,
+					Msg:  "at least 1 label association is required",
+				},
+				{
+					Kind: KindVariable,
+					Name: "var_1",
+					Rule: LintRuleRequireDescriptions,
+					Msg:  "a description is required",
+				},
+				{
+					Kind: KindVariable,
+					Name: "var_1",
+					Rule: LintRuleRequireLabels,
+					Msg:  "at least 1 label association is required",
+				},
+			}
+			assert.Equal(t, expected, warnings)
+		})
 	})
 }
 
@@ -3141,6 +5986,8 @@ func levelPtr(l notification.CheckLevel) *notification.CheckLevel {
 
 type fakeStore struct {
 	createFn func(ctx context.Context, stack Stack) error
+	readFn   func(ctx context.Context, id influxdb.ID) (Stack, error)
+	deleteFn func(ctx context.Context, id influxdb.ID) error
 }
 
 var _ Store = (*fakeStore)(nil)
@@ -3153,6 +6000,9 @@ func (s *fakeStore) CreateStack(ctx context.Context, stack Stack) error {
 }
 
 func (s *fakeStore) ReadStackByID(ctx context.Context, id influxdb.ID) (Stack, error) {
+	if s.readFn != nil {
+		return s.readFn(ctx, id)
+	}
 	panic("not implemented")
 }
 
@@ -3161,19 +6011,43 @@ func (s *fakeStore) UpdateStack(ctx context.Context, stack Stack) error {
 }
 
 func (s *fakeStore) DeleteStack(ctx context.Context, id influxdb.ID) error {
+	if s.deleteFn != nil {
+		return s.deleteFn(ctx, id)
+	}
 	panic("not implemented")
 }
 
-type fakeIDGen func() influxdb.ID
+type fakeBatchLabelSVC struct {
+	*mock.LabelService
+	batchCreateFn func(ctx context.Context, mappings []*influxdb.LabelMapping) error
+}
 
-func newFakeIDGen(id influxdb.ID) fakeIDGen {
-	return func() influxdb.ID {
-		return id
-	}
+var _ LabelMappingBatchCreator = (*fakeBatchLabelSVC)(nil)
+
+func (s *fakeBatchLabelSVC) BatchCreateLabelMappings(ctx context.Context, mappings []*influxdb.LabelMapping) error {
+	return s.batchCreateFn(ctx, mappings)
+}
+
+type fakeVarByNameSVC struct {
+	*mock.VariableService
+	findByNameFn func(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Variable, error)
+}
+
+var _ VariableByNameFinder = (*fakeVarByNameSVC)(nil)
+
+func (s *fakeVarByNameSVC) FindVariableByName(ctx context.Context, orgID influxdb.ID, name string) (*influxdb.Variable, error) {
+	return s.findByNameFn(ctx, orgID, name)
+}
+
+// fakePagedLabelSVC provides the find options to the find labels fn, which the
+// mock label service does not.
+type fakePagedLabelSVC struct {
+	*mock.LabelService
+	findLabelsFn func(ctx context.Context, filter influxdb.LabelFilter, opts ...influxdb.FindOptions) ([]*influxdb.Label, error)
 }
 
-func (f fakeIDGen) ID() influxdb.ID {
-	return f()
+func (s *fakePagedLabelSVC) FindLabels(ctx context.Context, filter influxdb.LabelFilter, opts ...influxdb.FindOptions) ([]*influxdb.Label, error) {
+	return s.findLabelsFn(ctx, filter, opts...)
 }
 
 type fakeTimeGen func() time.Time
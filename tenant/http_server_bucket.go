@@ -80,38 +80,50 @@ type bucket struct {
 
 // retentionRule is the retention rule action for a bucket.
 type retentionRule struct {
-	Type         string `json:"type"`
-	EverySeconds int64  `json:"everySeconds"`
+	Type                      string `json:"type"`
+	EverySeconds              int64  `json:"everySeconds"`
+	ShardGroupDurationSeconds int64  `json:"shardGroupDurationSeconds,omitempty"`
 }
 
+// RetentionPeriod returns the retention period of the rule, zero implies an
+// infinite retention period. A rule of infinite retention only carries the
+// shard group duration of the bucket.
 func (rr *retentionRule) RetentionPeriod() (time.Duration, error) {
 	t := time.Duration(rr.EverySeconds) * time.Second
-	if t < time.Second {
+	if t < 0 {
 		return t, &influxdb.Error{
 			Code: influxdb.EUnprocessableEntity,
-			Msg:  "expiration seconds must be greater than or equal to one second",
+			Msg:  "expiration seconds must be greater than or equal to zero",
 		}
 	}
 
 	return t, nil
 }
 
+// ShardGroupDuration returns the shard group duration of the rule, zero implies
+// the default shard group duration.
+func (rr *retentionRule) ShardGroupDuration() time.Duration {
+	return time.Duration(rr.ShardGroupDurationSeconds) * time.Second
+}
+
 func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 	if b == nil {
 		return nil, nil
 	}
 
 	var d time.Duration // zero value implies infinite retention policy
+	var sgd time.Duration
 
 	// Only support a single retention period for the moment
 	if len(b.RetentionRules) > 0 {
 		d = time.Duration(b.RetentionRules[0].EverySeconds) * time.Second
-		if d < time.Second {
+		if d < 0 {
 			return nil, &influxdb.Error{
 				Code: influxdb.EUnprocessableEntity,
-				Msg:  "expiration seconds must be greater than or equal to one second",
+				Msg:  "expiration seconds must be greater than or equal to zero",
 			}
 		}
+		sgd = b.RetentionRules[0].ShardGroupDuration()
 	}
 
 	return &influxdb.Bucket{
@@ -122,6 +134,7 @@ func (b *bucket) toInfluxDB() (*influxdb.Bucket, error) {
 		Name:                b.Name,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     d,
+		ShardGroupDuration:  sgd,
 		CRUDLog:             b.CRUDLog,
 	}, nil
 }
@@ -133,10 +146,12 @@ func newBucket(pb *influxdb.Bucket) *bucket {
 
 	rules := []retentionRule{}
 	rp := int64(pb.RetentionPeriod.Round(time.Second) / time.Second)
-	if rp > 0 {
+	sgd := int64(pb.ShardGroupDuration.Round(time.Second) / time.Second)
+	if rp > 0 || sgd > 0 {
 		rules = append(rules, retentionRule{
-			Type:         "expire",
-			EverySeconds: rp,
+			Type:                      "expire",
+			EverySeconds:              rp,
+			ShardGroupDurationSeconds: sgd,
 		})
 	}
 
@@ -176,14 +191,19 @@ func (b *bucketUpdate) toInfluxDB() *influxdb.BucketUpdate {
 
 	// For now, only use a single retention rule.
 	var d time.Duration
+	var sgd *time.Duration
 	if len(b.RetentionRules) > 0 {
 		d, _ = b.RetentionRules[0].RetentionPeriod()
+		if dur := b.RetentionRules[0].ShardGroupDuration(); dur > 0 {
+			sgd = &dur
+		}
 	}
 
 	return &influxdb.BucketUpdate{
-		Name:            b.Name,
-		Description:     b.Description,
-		RetentionPeriod: &d,
+		Name:               b.Name,
+		Description:        b.Description,
+		RetentionPeriod:    &d,
+		ShardGroupDuration: sgd,
 	}
 }
 
@@ -198,12 +218,15 @@ func newBucketUpdate(pb *influxdb.BucketUpdate) *bucketUpdate {
 		RetentionRules: []retentionRule{},
 	}
 
-	if pb.RetentionPeriod != nil {
-		d := int64((*pb.RetentionPeriod).Round(time.Second) / time.Second)
-		up.RetentionRules = append(up.RetentionRules, retentionRule{
-			Type:         "expire",
-			EverySeconds: d,
-		})
+	if pb.RetentionPeriod != nil || pb.ShardGroupDuration != nil {
+		rule := retentionRule{Type: "expire"}
+		if pb.RetentionPeriod != nil {
+			rule.EverySeconds = int64((*pb.RetentionPeriod).Round(time.Second) / time.Second)
+		}
+		if pb.ShardGroupDuration != nil {
+			rule.ShardGroupDurationSeconds = int64((*pb.ShardGroupDuration).Round(time.Second) / time.Second)
+		}
+		up.RetentionRules = append(up.RetentionRules, rule)
 	}
 	return up
 }
@@ -312,9 +335,10 @@ func (b *postBucketRequest) OK() error {
 
 func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 	// Only support a single retention period for the moment
-	var dur time.Duration
+	var dur, sgd time.Duration
 	if len(b.RetentionRules) > 0 {
 		dur, _ = b.RetentionRules[0].RetentionPeriod()
+		sgd = b.RetentionRules[0].ShardGroupDuration()
 	}
 
 	return &influxdb.Bucket{
@@ -324,6 +348,7 @@ func (b postBucketRequest) toInfluxDB() *influxdb.Bucket {
 		Type:                influxdb.BucketTypeUser,
 		RetentionPolicyName: b.RetentionPolicyName,
 		RetentionPeriod:     dur,
+		ShardGroupDuration:  sgd,
 	}
 }
 
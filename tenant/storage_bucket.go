@@ -381,6 +381,10 @@ func (s *Store) UpdateBucket(ctx context.Context, tx kv.Tx, id influxdb.ID, upd
 		bucket.RetentionPeriod = *upd.RetentionPeriod
 	}
 
+	if upd.ShardGroupDuration != nil {
+		bucket.ShardGroupDuration = *upd.ShardGroupDuration
+	}
+
 	v, err := marshalBucket(bucket)
 	if err != nil {
 		return nil, err
@@ -9,12 +9,12 @@ import (
 // DecodeBooleanArrayBlock decodes the boolean block from the byte slice
 // and writes the values to a.
 func DecodeBooleanArrayBlock(block []byte, a *cursors.BooleanArray) error {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockBoolean {
 		return fmt.Errorf("invalid block type: exp %d, got %d", BlockBoolean, blockType)
 	}
 
-	tb, vb, err := unpackBlock(block[1:])
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return err
 	}
@@ -30,12 +30,12 @@ func DecodeBooleanArrayBlock(block []byte, a *cursors.BooleanArray) error {
 // DecodeFloatArrayBlock decodes the float block from the byte slice
 // and writes the values to a.
 func DecodeFloatArrayBlock(block []byte, a *cursors.FloatArray) error {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockFloat64 {
 		return fmt.Errorf("invalid block type: exp %d, got %d", BlockFloat64, blockType)
 	}
 
-	tb, vb, err := unpackBlock(block[1:])
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return err
 	}
@@ -51,12 +51,12 @@ func DecodeFloatArrayBlock(block []byte, a *cursors.FloatArray) error {
 // DecodeIntegerArrayBlock decodes the integer block from the byte slice
 // and writes the values to a.
 func DecodeIntegerArrayBlock(block []byte, a *cursors.IntegerArray) error {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockInteger {
 		return fmt.Errorf("invalid block type: exp %d, got %d", BlockInteger, blockType)
 	}
 
-	tb, vb, err := unpackBlock(block[1:])
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return err
 	}
@@ -72,12 +72,12 @@ func DecodeIntegerArrayBlock(block []byte, a *cursors.IntegerArray) error {
 // DecodeUnsignedArrayBlock decodes the unsigned integer block from the byte slice
 // and writes the values to a.
 func DecodeUnsignedArrayBlock(block []byte, a *cursors.UnsignedArray) error {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockUnsigned {
 		return fmt.Errorf("invalid block type: exp %d, got %d", BlockUnsigned, blockType)
 	}
 
-	tb, vb, err := unpackBlock(block[1:])
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return err
 	}
@@ -93,12 +93,12 @@ func DecodeUnsignedArrayBlock(block []byte, a *cursors.UnsignedArray) error {
 // DecodeStringArrayBlock decodes the string block from the byte slice
 // and writes the values to a.
 func DecodeStringArrayBlock(block []byte, a *cursors.StringArray) error {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockString {
 		return fmt.Errorf("invalid block type: exp %d, got %d", BlockString, blockType)
 	}
 
-	tb, vb, err := unpackBlock(block[1:])
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return err
 	}
@@ -114,7 +114,7 @@ func DecodeStringArrayBlock(block []byte, a *cursors.StringArray) error {
 // DecodeTimestampArrayBlock decodes the timestamps from the specified
 // block, ignoring the block type and the values.
 func DecodeTimestampArrayBlock(block []byte, a *cursors.TimestampArray) error {
-	tb, _, err := unpackBlock(block[1:])
+	tb, _, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return err
 	}
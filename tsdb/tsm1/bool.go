@@ -167,3 +167,25 @@ func (e *BooleanDecoder) Read() bool {
 func (e *BooleanDecoder) Error() error {
 	return e.err
 }
+
+// booleanEncodedLen returns the number of bytes used to encode the booleans at
+// the start of b.
+func booleanEncodedLen(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, fmt.Errorf("booleanEncodedLen: not enough data for encoding type")
+	}
+
+	count, i := binary.Uvarint(b[1:])
+	if i <= 0 {
+		return 0, fmt.Errorf("booleanEncodedLen: invalid count")
+	}
+	if count > uint64(len(b))*8 {
+		return 0, fmt.Errorf("booleanEncodedLen: not enough data for %d values", count)
+	}
+
+	n := 1 + i + int((count+7)/8)
+	if n > len(b) {
+		return 0, fmt.Errorf("booleanEncodedLen: not enough data for %d values", count)
+	}
+	return n, nil
+}
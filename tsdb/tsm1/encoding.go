@@ -3,9 +3,11 @@ package tsm1
 import (
 	"encoding/binary"
 	"fmt"
+	"hash/crc32"
+	"math"
 	"runtime"
+	"sort"
 
-	"github.com/influxdata/influxdb/pkg/pool"
 	"github.com/influxdata/influxql"
 )
 
@@ -28,12 +30,16 @@ const (
 	// encodedBlockHeaderSize is the size of the header for an encoded block.  There is one
 	// byte encoding the type of the block.
 	encodedBlockHeaderSize = 1
+
+	// blockFlagCRC is set in the block type header of a block that ends with a
+	// CRC-32 checksum of the preceding bytes of the block.
+	blockFlagCRC = byte(0x80)
 )
 
 func init() {
 	// Prime the pools with one encoder/decoder for each available CPU.
 	vals := make([]interface{}, 0, runtime.NumCPU())
-	for _, p := range []*pool.Generic{
+	for _, p := range []*trackedPool{
 		timeEncoderPool, timeDecoderPool,
 		integerEncoderPool, integerDecoderPool,
 		floatDecoderPool, floatDecoderPool,
@@ -56,48 +62,53 @@ func init() {
 var (
 	// encoder pools
 
-	timeEncoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	timeEncoderPool = newTrackedPool("time_encoder", runtime.NumCPU(), func(sz int) interface{} {
 		return NewTimeEncoder(sz)
 	})
-	integerEncoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	integerEncoderPool = newTrackedPool("integer_encoder", runtime.NumCPU(), func(sz int) interface{} {
 		return NewIntegerEncoder(sz)
 	})
-	floatEncoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	floatEncoderPool = newTrackedPool("float_encoder", runtime.NumCPU(), func(sz int) interface{} {
 		return NewFloatEncoder()
 	})
-	stringEncoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	stringEncoderPool = newTrackedPool("string_encoder", runtime.NumCPU(), func(sz int) interface{} {
 		return NewStringEncoder(sz)
 	})
-	booleanEncoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	booleanEncoderPool = newTrackedPool("boolean_encoder", runtime.NumCPU(), func(sz int) interface{} {
 		return NewBooleanEncoder(sz)
 	})
 
 	// decoder pools
 
-	timeDecoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	timeDecoderPool = newTrackedPool("time_decoder", runtime.NumCPU(), func(sz int) interface{} {
 		return &TimeDecoder{}
 	})
-	integerDecoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	integerDecoderPool = newTrackedPool("integer_decoder", runtime.NumCPU(), func(sz int) interface{} {
 		return &IntegerDecoder{}
 	})
-	floatDecoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	floatDecoderPool = newTrackedPool("float_decoder", runtime.NumCPU(), func(sz int) interface{} {
 		return &FloatDecoder{}
 	})
-	stringDecoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	stringDecoderPool = newTrackedPool("string_decoder", runtime.NumCPU(), func(sz int) interface{} {
 		return &StringDecoder{}
 	})
-	booleanDecoderPool = pool.NewGeneric(runtime.NumCPU(), func(sz int) interface{} {
+	booleanDecoderPool = newTrackedPool("boolean_decoder", runtime.NumCPU(), func(sz int) interface{} {
 		return &BooleanDecoder{}
 	})
 )
 
 // Encode converts the values to a byte slice.  If there are no values,
-// this function panics.
+// this function panics. An error is returned if the values do not all
+// share the same type.
 func (a Values) Encode(buf []byte) ([]byte, error) {
 	if len(a) == 0 {
 		panic("unable to encode block type")
 	}
 
+	if err := a.checkTypes(); err != nil {
+		return nil, err
+	}
+
 	switch a[0].(type) {
 	case FloatValue:
 		return encodeFloatBlock(buf, a)
@@ -114,6 +125,178 @@ func (a Values) Encode(buf []byte) ([]byte, error) {
 	return nil, fmt.Errorf("unsupported value type %T", a[0])
 }
 
+// EncodeWithCRC converts the values to a byte slice as Encode does, appending
+// a checksum of the block that is verified when the block is decoded. Blocks
+// encoded with a checksum can only be decoded by readers aware of the checksum
+// flag.
+func (a Values) EncodeWithCRC(buf []byte) ([]byte, error) {
+	b, err := a.Encode(buf)
+	if err != nil {
+		return nil, err
+	}
+	return appendBlockCRC(b), nil
+}
+
+// EncodeWithMax encodes the values into consecutive blocks of at most
+// maxPerBlock values each, allowing blocks smaller or larger than the
+// default. A maxPerBlock of 0 or less encodes blocks of MaxPointsPerBlock
+// values. An error is returned if the values do not all share the same type.
+func (a Values) EncodeWithMax(maxPerBlock int) ([][]byte, error) {
+	if len(a) == 0 {
+		return nil, nil
+	}
+
+	if err := a.checkTypes(); err != nil {
+		return nil, err
+	}
+
+	chunks := a.Split(maxPerBlock)
+	blocks := make([][]byte, 0, len(chunks))
+	for _, chunk := range chunks {
+		b, err := chunk.Encode(nil)
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, b)
+	}
+	return blocks, nil
+}
+
+// Split divides a into consecutive chunks of at most maxPerBlock values,
+// suitable for encoding one block per chunk. The chunks share the backing
+// array of a, but are capped so that appending to one does not overwrite the
+// next. A maxPerBlock of 0 or less splits into MaxPointsPerBlock values.
+func (a Values) Split(maxPerBlock int) []Values {
+	if maxPerBlock <= 0 {
+		maxPerBlock = MaxPointsPerBlock
+	}
+	if len(a) == 0 {
+		return nil
+	}
+
+	chunks := make([]Values, 0, (len(a)+maxPerBlock-1)/maxPerBlock)
+	for i := 0; i < len(a); i += maxPerBlock {
+		end := i + maxPerBlock
+		if end > len(a) {
+			end = len(a)
+		}
+		chunks = append(chunks, a[i:end:end])
+	}
+	return chunks
+}
+
+// RepackBlocks decodes the blocks, merges their values, and encodes them into
+// new blocks of at most maxPerBlock values each, as EncodeWithMax does. The values
+// are sorted and deduplicated, a value of a later block replaces a value of an
+// earlier block with the same timestamp. An error is returned if the blocks do
+// not all share the same type.
+func RepackBlocks(blocks [][]byte, maxPerBlock int) ([][]byte, error) {
+	var (
+		typ     byte
+		values  Values
+		decoded []Value
+	)
+	for i, block := range blocks {
+		if len(block) <= encodedBlockHeaderSize {
+			return nil, fmt.Errorf("block %d is too short: got %d bytes", i, len(block))
+		}
+
+		blockType, err := BlockType(block)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %v", i, err)
+		}
+		if i == 0 {
+			typ = blockType
+		} else if blockType != typ {
+			return nil, fmt.Errorf("mixed block types at block %d: exp %s, got %s", i, BlockTypeName(typ), BlockTypeName(blockType))
+		}
+
+		decoded, err = DecodeBlock(block, decoded)
+		if err != nil {
+			return nil, fmt.Errorf("block %d: %v", i, err)
+		}
+		values = append(values, decoded...)
+	}
+
+	return values.Deduplicate().EncodeWithMax(maxPerBlock)
+}
+
+// DeduplicateReport deduplicates a as Deduplicate does, keeping the last value
+// written for each timestamp, and reports whether any duplicates were removed.
+// Values passed to Encode must be sorted and unique for the block to decode
+// correctly.
+func (a Values) DeduplicateReport() (Values, bool) {
+	n := len(a)
+	a = a.Deduplicate()
+	return a, len(a) != n
+}
+
+// checkTypes returns an error identifying the first value whose type
+// differs from the type of a[0].
+func (a Values) checkTypes() error {
+	typ, ok := blockTypeOf(a[0])
+	if !ok {
+		// unsupported types are reported by the caller
+		return nil
+	}
+
+	for i := 1; i < len(a); i++ {
+		if t, ok := blockTypeOf(a[i]); !ok || t != typ {
+			return fmt.Errorf("mixed value types at index %d: exp %T, got %T", i, a[0], a[i])
+		}
+	}
+	return nil
+}
+
+// First returns the first value of a. The bool is false if a is empty.
+func (a Values) First() (Value, bool) {
+	if len(a) == 0 {
+		return nil, false
+	}
+	return a[0], true
+}
+
+// Last returns the last value of a. The bool is false if a is empty.
+func (a Values) Last() (Value, bool) {
+	if len(a) == 0 {
+		return nil, false
+	}
+	return a[len(a)-1], true
+}
+
+// TimeRange returns the timestamps of the first and last values of a, which are the
+// minimum and maximum timestamps when a is sorted. The bool is false if a is empty.
+func (a Values) TimeRange() (min, max int64, ok bool) {
+	if len(a) == 0 {
+		return 0, 0, false
+	}
+	return a[0].UnixNano(), a[len(a)-1].UnixNano(), true
+}
+
+// ErrValuesNotSorted is returned by ContainsChecked when the values are not sorted
+// by time.
+var ErrValuesNotSorted = fmt.Errorf("values not sorted by time")
+
+// IsSorted returns true if the timestamps of a are in ascending order, as is
+// required by Contains and FindRange. Equal timestamps are considered sorted.
+func (a Values) IsSorted() bool {
+	for i := 1; i < len(a); i++ {
+		if a[i].UnixNano() < a[i-1].UnixNano() {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsChecked behaves as Contains, returning ErrValuesNotSorted rather than an
+// undefined result when the values are not sorted.
+func (a Values) ContainsChecked(min, max int64) (bool, error) {
+	if !a.IsSorted() {
+		return false, ErrValuesNotSorted
+	}
+	return a.Contains(min, max), nil
+}
+
 // Contains returns true if values exist for the time interval [min, max]
 // inclusive. The values must be sorted before calling Contains or the
 // results are undefined.
@@ -159,10 +342,93 @@ func (a Values) InfluxQLType() (influxql.DataType, error) {
 	return influxql.Unknown, fmt.Errorf("unsupported value type %T", a[0])
 }
 
+// TypeCounts returns the number of values of each block type in a. Values of
+// an unsupported type are not counted.
+func (a Values) TypeCounts() map[byte]int {
+	counts := make(map[byte]int)
+	for _, v := range a {
+		typ, ok := blockTypeOf(v)
+		if !ok {
+			continue
+		}
+		counts[typ]++
+	}
+	return counts
+}
+
+// ValuesEqual returns true if a and b hold the same number of values and
+// each pair of values is equal according to ValueEqual.
+func ValuesEqual(a, b Values) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !ValueEqual(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValueEqual returns true if a and b have the same timestamp, type and
+// value. Unlike ==, two float NaN values are considered equal, so a block
+// containing NaN compares equal to its decoded counterpart.
+func ValueEqual(a, b Value) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	if a.UnixNano() != b.UnixNano() {
+		return false
+	}
+
+	switch av := a.(type) {
+	case FloatValue:
+		bv, ok := b.(FloatValue)
+		if !ok {
+			return false
+		}
+		x, y := av.RawValue(), bv.RawValue()
+		return x == y || (math.IsNaN(x) && math.IsNaN(y))
+	case IntegerValue:
+		bv, ok := b.(IntegerValue)
+		return ok && av.RawValue() == bv.RawValue()
+	case UnsignedValue:
+		bv, ok := b.(UnsignedValue)
+		return ok && av.RawValue() == bv.RawValue()
+	case BooleanValue:
+		bv, ok := b.(BooleanValue)
+		return ok && av.RawValue() == bv.RawValue()
+	case StringValue:
+		bv, ok := b.(StringValue)
+		return ok && av.RawValue() == bv.RawValue()
+	default:
+		return a.Value() == b.Value()
+	}
+}
+
+// blockTypeOf returns the block type v would be encoded as. The bool
+// is false if v has an unsupported type.
+func blockTypeOf(v Value) (byte, bool) {
+	switch v.(type) {
+	case FloatValue:
+		return BlockFloat64, true
+	case IntegerValue:
+		return BlockInteger, true
+	case UnsignedValue:
+		return BlockUnsigned, true
+	case BooleanValue:
+		return BlockBoolean, true
+	case StringValue:
+		return BlockString, true
+	default:
+		return 0, false
+	}
+}
+
 // BlockType returns the type of value encoded in a block or an error
 // if the block type is unknown.
 func BlockType(block []byte) (byte, error) {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	switch blockType {
 	case BlockFloat64, BlockInteger, BlockUnsigned, BlockBoolean, BlockString:
 		return blockType, nil
@@ -177,13 +443,43 @@ func BlockCount(block []byte) int {
 		panic(fmt.Sprintf("count of short block: got %v, exp %v", len(block), encodedBlockHeaderSize))
 	}
 	// first byte is the block type
-	tb, _, err := unpackBlock(block[1:])
+	tb, _, err := unpackBlockWithCRC(block)
 	if err != nil {
 		panic(fmt.Sprintf("BlockCount: error unpacking block: %s", err.Error()))
 	}
 	return CountTimestamps(tb)
 }
 
+// DecodeTimestamps decodes only the timestamps of block, skipping the
+// decoding of its values.
+func DecodeTimestamps(block []byte) ([]int64, error) {
+	if len(block) <= encodedBlockHeaderSize {
+		return nil, fmt.Errorf("decode of short block: got %v, exp %v", len(block), encodedBlockHeaderSize)
+	}
+
+	if _, err := BlockType(block); err != nil {
+		return nil, err
+	}
+
+	// first byte is the block type
+	tb, _, err := unpackBlockWithCRC(block)
+	if err != nil {
+		return nil, err
+	}
+
+	a := make([]int64, 0, CountTimestamps(tb))
+
+	tdec := timeDecoderPool.Get(0).(*TimeDecoder)
+	tdec.Init(tb)
+	for tdec.Next() {
+		a = append(a, tdec.Read())
+	}
+	err = tdec.Error()
+	timeDecoderPool.Put(tdec)
+
+	return a, err
+}
+
 // DecodeBlock takes a byte slice and decodes it into values of the appropriate type
 // based on the block.
 func DecodeBlock(block []byte, vals []Value) ([]Value, error) {
@@ -256,6 +552,46 @@ func DecodeBlock(block []byte, vals []Value) ([]Value, error) {
 	}
 }
 
+// DecodeBlockN decodes the block at the start of data into vals, as DecodeBlock
+// does, and returns the number of bytes the block occupied. Unlike DecodeBlock,
+// data may hold several blocks packed back to back, the next of which starts at
+// data[consumed:].
+func DecodeBlockN(data []byte, vals []Value) (consumed int, out []Value, err error) {
+	n, err := blockLen(data)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	out, err = DecodeBlock(data[:n], vals)
+	if err != nil {
+		return 0, nil, err
+	}
+	return n, out, nil
+}
+
+// DecodeBlockRange decodes the values of block whose timestamps fall within the
+// inclusive range [min, max] into vals. The timestamps are decoded first, so the
+// values of a block without any timestamps in the range are never decoded.
+func DecodeBlockRange(block []byte, min, max int64, vals []Value) ([]Value, error) {
+	ts, err := DecodeTimestamps(block)
+	if err != nil {
+		return nil, err
+	}
+
+	lo := sort.Search(len(ts), func(i int) bool { return ts[i] >= min })
+	hi := sort.Search(len(ts), func(i int) bool { return ts[i] > max })
+	if lo >= hi {
+		return vals[:0], nil
+	}
+
+	vals, err = DecodeBlock(block, vals)
+	if err != nil {
+		return nil, err
+	}
+	n := copy(vals, vals[lo:hi])
+	return vals[:n], nil
+}
+
 func encodeFloatBlock(buf []byte, values []Value) ([]byte, error) {
 	if len(values) == 0 {
 		return nil, nil
@@ -279,6 +615,33 @@ func encodeFloatBlock(buf []byte, values []Value) ([]byte, error) {
 	return b, err
 }
 
+// EncodeFloatBlockWith encodes the float values into a block, as Values.Encode
+// does, compressing the values with c. FloatCompressionDefault produces the same
+// block as Values.Encode, FloatCompressionHigh trades encoding speed for a
+// smaller block. The block is decoded by DecodeFloatBlock regardless of c.
+func EncodeFloatBlockWith(buf []byte, values []Value, c FloatCompression) ([]byte, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	for i, v := range values {
+		if _, ok := v.(FloatValue); !ok {
+			return nil, fmt.Errorf("value at index %d is not a float: %T", i, v)
+		}
+	}
+
+	venc := getFloatEncoder(len(values))
+	venc.SetCompression(c)
+	tsenc := getTimeEncoder(len(values))
+
+	b, err := encodeFloatBlockUsing(buf, values, tsenc, venc)
+
+	putTimeEncoder(tsenc)
+	venc.SetCompression(FloatCompressionDefault)
+	putFloatEncoder(venc)
+
+	return b, err
+}
+
 func encodeFloatBlockUsing(buf []byte, values []Value, tsenc TimeEncoder, venc *FloatEncoder) ([]byte, error) {
 	tsenc.Reset()
 	venc.Reset()
@@ -310,13 +673,11 @@ func encodeFloatBlockUsing(buf []byte, values []Value, tsenc TimeEncoder, venc *
 // and appends the float values to a.
 func DecodeFloatBlock(block []byte, a *[]FloatValue) ([]FloatValue, error) {
 	// Block type is the next block, make sure we actually have a float block
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockFloat64 {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockFloat64, blockType)
 	}
-	block = block[1:]
-
-	tb, vb, err := unpackBlock(block)
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return nil, err
 	}
@@ -415,13 +776,11 @@ func encodeBooleanBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc
 // and appends the boolean values to a.
 func DecodeBooleanBlock(block []byte, a *[]BooleanValue) ([]BooleanValue, error) {
 	// Block type is the next block, make sure we actually have a float block
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockBoolean {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockBoolean, blockType)
 	}
-	block = block[1:]
-
-	tb, vb, err := unpackBlock(block)
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return nil, err
 	}
@@ -506,15 +865,13 @@ func encodeIntegerBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc
 // DecodeIntegerBlock decodes the integer block from the byte slice
 // and appends the integer values to a.
 func DecodeIntegerBlock(block []byte, a *[]IntegerValue) ([]IntegerValue, error) {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockInteger {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockInteger, blockType)
 	}
 
-	block = block[1:]
-
 	// The first 8 bytes is the minimum timestamp of the block
-	tb, vb, err := unpackBlock(block)
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return nil, err
 	}
@@ -599,15 +956,13 @@ func encodeUnsignedBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc
 // DecodeUnsignedBlock decodes the unsigned integer block from the byte slice
 // and appends the unsigned integer values to a.
 func DecodeUnsignedBlock(block []byte, a *[]UnsignedValue) ([]UnsignedValue, error) {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockUnsigned {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockUnsigned, blockType)
 	}
 
-	block = block[1:]
-
 	// The first 8 bytes is the minimum timestamp of the block
-	tb, vb, err := unpackBlock(block)
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return nil, err
 	}
@@ -692,15 +1047,13 @@ func encodeStringBlockUsing(buf []byte, values []Value, tenc TimeEncoder, venc S
 // DecodeStringBlock decodes the string block from the byte slice
 // and appends the string values to a.
 func DecodeStringBlock(block []byte, a *[]StringValue) ([]StringValue, error) {
-	blockType := block[0]
+	blockType := block[0] &^ blockFlagCRC
 	if blockType != BlockString {
 		return nil, fmt.Errorf("invalid block type: exp %d, got %d", BlockString, blockType)
 	}
 
-	block = block[1:]
-
 	// The first 8 bytes is the minimum timestamp of the block
-	tb, vb, err := unpackBlock(block)
+	tb, vb, err := unpackBlockWithCRC(block)
 	if err != nil {
 		return nil, err
 	}
@@ -789,6 +1142,96 @@ func unpackBlock(buf []byte) (ts, values []byte, err error) {
 	return
 }
 
+// blockLen returns the length of the block at the start of data. The length of
+// the values of a block is not packed with it, so the values are walked until
+// every value of the block's timestamps is accounted for.
+func blockLen(data []byte) (int, error) {
+	if len(data) <= encodedBlockHeaderSize {
+		return 0, fmt.Errorf("length of short block: got %v, exp %v", len(data), encodedBlockHeaderSize)
+	}
+
+	blockType, err := BlockType(data)
+	if err != nil {
+		return 0, err
+	}
+
+	tsLen, i := binary.Uvarint(data[1:])
+	if i <= 0 {
+		return 0, fmt.Errorf("blockLen: unable to read timestamp block length")
+	}
+	if tsLen > uint64(len(data)) || 1+i+int(tsLen) > len(data) {
+		return 0, fmt.Errorf("blockLen: not enough data for timestamp")
+	}
+	tsIdx := 1 + i + int(tsLen)
+	count := CountTimestamps(data[1+i : tsIdx])
+
+	var valuesLen int
+	switch blockType {
+	case BlockFloat64:
+		valuesLen, err = floatEncodedLen(data[tsIdx:])
+	case BlockInteger, BlockUnsigned:
+		valuesLen, err = integerEncodedLen(data[tsIdx:], count)
+	case BlockBoolean:
+		valuesLen, err = booleanEncodedLen(data[tsIdx:])
+	case BlockString:
+		valuesLen, err = stringEncodedLen(data[tsIdx:])
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	n := tsIdx + valuesLen
+	if data[0]&blockFlagCRC != 0 {
+		n += crc32.Size
+	}
+	if n > len(data) {
+		return 0, fmt.Errorf("blockLen: not enough data for values")
+	}
+	return n, nil
+}
+
+// appendBlockCRC flags the block type header of the packed block and appends
+// a CRC-32 checksum of the flagged block.
+func appendBlockCRC(block []byte) []byte {
+	block[0] |= blockFlagCRC
+	var checksum [crc32.Size]byte
+	binary.BigEndian.PutUint32(checksum[:], crc32.ChecksumIEEE(block))
+	return append(block, checksum[:]...)
+}
+
+// unpackBlockWithCRC unpacks the block, including its type header. The checksum
+// of a block encoded by EncodeWithCRC is verified, while blocks encoded without
+// a checksum are unpacked as is.
+func unpackBlockWithCRC(block []byte) (ts, values []byte, err error) {
+	if block[0]&blockFlagCRC == 0 {
+		return unpackBlock(block[1:])
+	}
+
+	if len(block) < encodedBlockHeaderSize+crc32.Size {
+		err = fmt.Errorf("unpackBlock: not enough data for checksum")
+		return
+	}
+	end := len(block) - crc32.Size
+	if exp, got := binary.BigEndian.Uint32(block[end:]), crc32.ChecksumIEEE(block[:end]); exp != got {
+		err = fmt.Errorf("unpackBlock: checksum mismatch: exp %08x, got %08x", exp, got)
+		return
+	}
+	return unpackBlock(block[1:end])
+}
+
+// VerifyBlock validates the structure of block and, when the block carries
+// a checksum, that the checksum matches the block contents.
+func VerifyBlock(block []byte) error {
+	if len(block) <= encodedBlockHeaderSize {
+		return fmt.Errorf("verify of short block: got %v, exp %v", len(block), encodedBlockHeaderSize)
+	}
+	if _, err := BlockType(block); err != nil {
+		return err
+	}
+	_, _, err := unpackBlockWithCRC(block)
+	return err
+}
+
 // ZigZagEncode converts a int64 to a uint64 by zig zagging negative and positive values
 // across even and odd numbers.  Eg. [0,-1,1,-2] becomes [0, 1, 2, 3].
 func ZigZagEncode(x int64) uint64 {
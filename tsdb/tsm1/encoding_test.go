@@ -1,9 +1,13 @@
 package tsm1_test
 
 import (
+	"bytes"
+	"encoding/binary"
 	"fmt"
+	"math"
 	"math/rand"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -36,6 +40,53 @@ func TestEncoding_FloatBlock(t *testing.T) {
 	}
 }
 
+func TestEncoding_EncodeFloatBlockWith(t *testing.T) {
+	// a large change followed by slowly changing values, which the high
+	// compression mode encodes with a narrower window
+	times := getTimes(100, 60, time.Second)
+	values := make([]tsm1.Value, len(times))
+	for i, t := range times {
+		v := -1234.5678 + float64(i%4)*0.25
+		if i == 0 {
+			v = 1
+		}
+		values[i] = tsm1.NewValue(t, v)
+	}
+
+	exp, err := tsm1.Values(values).Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	def, err := tsm1.EncodeFloatBlockWith(nil, values, tsm1.FloatCompressionDefault)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(def, exp) {
+		t.Fatalf("unexpected default block: got %v, exp %v", def, exp)
+	}
+
+	high, err := tsm1.EncodeFloatBlockWith(nil, values, tsm1.FloatCompressionHigh)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(high) >= len(def) {
+		t.Fatalf("expected high compression block to be smaller: got %d, default %d", len(high), len(def))
+	}
+
+	decodedValues, err := tsm1.DecodeBlock(high, nil)
+	if err != nil {
+		t.Fatalf("unexpected error decoding block: %v", err)
+	}
+	if !reflect.DeepEqual(decodedValues, values) {
+		t.Fatalf("unexpected results:\n\tgot: %s\n\texp: %s\n", spew.Sdump(decodedValues), spew.Sdump(values))
+	}
+
+	if _, err := tsm1.EncodeFloatBlockWith(nil, []tsm1.Value{tsm1.NewValue(0, int64(1))}, tsm1.FloatCompressionHigh); err == nil {
+		t.Fatal("expected error encoding an integer value")
+	}
+}
+
 func TestEncoding_FloatBlock_ZeroTime(t *testing.T) {
 	values := make([]tsm1.Value, 3)
 	for i := 0; i < 3; i++ {
@@ -293,6 +344,23 @@ func TestEncoding_BlockType(t *testing.T) {
 	}
 }
 
+func TestEncoding_MixedTypes(t *testing.T) {
+	values := []tsm1.Value{
+		tsm1.NewValue(0, float64(1)),
+		tsm1.NewValue(1, float64(2)),
+		tsm1.NewValue(2, int64(3)),
+	}
+
+	_, err := tsm1.Values(values).Encode(nil)
+	if err == nil {
+		t.Fatalf("expected error encoding mixed value types, got nil")
+	}
+
+	if got, exp := err.Error(), "mixed value types at index 2"; !strings.HasPrefix(got, exp) {
+		t.Fatalf("unexpected error: got %q, exp prefix %q", got, exp)
+	}
+}
+
 func TestEncoding_Count(t *testing.T) {
 	tests := []struct {
 		value     interface{}
@@ -320,6 +388,181 @@ func TestEncoding_Count(t *testing.T) {
 	}
 }
 
+func TestEncoding_DecodeTimestamps(t *testing.T) {
+	times := getTimes(1000, 60, time.Second)
+	tests := []struct {
+		name  string
+		value func(i int) interface{}
+	}{
+		{name: "float", value: func(i int) interface{} { return float64(i) }},
+		{name: "integer", value: func(i int) interface{} { return int64(i) }},
+		{name: "unsigned", value: func(i int) interface{} { return uint64(i) }},
+		{name: "boolean", value: func(i int) interface{} { return i%2 == 0 }},
+		{name: "string", value: func(i int) interface{} { return fmt.Sprintf("%d", i) }},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			values := make([]tsm1.Value, len(times))
+			for i, ts := range times {
+				values[i] = tsm1.NewValue(ts, test.value(i))
+			}
+
+			b, err := tsm1.Values(values).Encode(nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			got, err := tsm1.DecodeTimestamps(b)
+			if err != nil {
+				t.Fatalf("unexpected error decoding timestamps: %v", err)
+			}
+
+			if !cmp.Equal(got, times) {
+				t.Fatalf("unexpected timestamps: -got/+exp\n%s", cmp.Diff(got, times))
+			}
+		})
+	}
+}
+
+func TestEncoding_DecodeTimestamps_ShortBlock(t *testing.T) {
+	if _, err := tsm1.DecodeTimestamps([]byte{tsm1.BlockFloat64}); err == nil {
+		t.Fatalf("expected error decoding short block, got nil")
+	}
+}
+
+func TestEncoding_DecodeBlockRange(t *testing.T) {
+	values := make(tsm1.Values, 10)
+	for i := range values {
+		values[i] = tsm1.NewValue(int64(i*10), float64(i))
+	}
+
+	b, err := values.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cases := []struct {
+		n        string
+		min, max int64
+		exp      tsm1.Values
+	}{
+		{"all", 0, 90, values},
+		{"covers", -10, 100, values},
+		{"inclusive", 20, 40, values[2:5]},
+		{"between timestamps", 15, 45, values[2:5]},
+		{"single", 50, 50, values[5:6]},
+		{"before", -20, -10, tsm1.Values{}},
+		{"after", 100, 200, tsm1.Values{}},
+		{"gap", 51, 59, tsm1.Values{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(fmt.Sprintf("%s[%d,%d]", tc.n, tc.min, tc.max), func(t *testing.T) {
+			got, err := tsm1.DecodeBlockRange(b, tc.min, tc.max, make([]tsm1.Value, 0, 10))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(tsm1.Values(got), tc.exp) {
+				t.Fatalf("unexpected values: got %v, exp %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestEncoding_DecodeBlockRange_SkipsValues(t *testing.T) {
+	values := make(tsm1.Values, 10)
+	for i := range values {
+		values[i] = tsm1.NewValue(int64(i*10), fmt.Sprintf("value %d", i))
+	}
+
+	b, err := values.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// corrupt the values, following the timestamps and the encoding type
+	// of the values, leaving the timestamps intact
+	tsLen, n := binary.Uvarint(b[1:])
+	for i := 1 + n + int(tsLen) + 1; i < len(b); i++ {
+		b[i] = 0xff
+	}
+	if _, err := tsm1.DecodeBlock(b, nil); err == nil {
+		t.Fatal("expected error decoding the corrupt values")
+	}
+
+	got, err := tsm1.DecodeBlockRange(b, 100, 200, nil)
+	if err != nil {
+		t.Fatalf("unexpected error decoding a range missing the block: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("unexpected values: got %v, exp none", got)
+	}
+
+	if _, err := tsm1.DecodeBlockRange(b, 20, 40, nil); err == nil {
+		t.Fatal("expected error decoding the corrupt values of a range in the block")
+	}
+}
+
+func TestEncoding_DecodeBlockN(t *testing.T) {
+	times := getTimes(1000, 60, time.Second)
+	valueFns := []func(i int) interface{}{
+		func(i int) interface{} { return float64(i) * 1.5 },
+		func(i int) interface{} { return int64(i) },
+		func(i int) interface{} { return int64(i * i) },
+		func(i int) interface{} { return rand.Int63() - rand.Int63() },
+		func(i int) interface{} { return uint64(i) },
+		func(i int) interface{} { return i%3 == 0 },
+		func(i int) interface{} { return fmt.Sprintf("%d", i) },
+		func(i int) interface{} { return strings.Repeat("value", i%50) },
+	}
+
+	var (
+		data []byte
+		exp  []tsm1.Values
+	)
+	for _, fn := range valueFns {
+		values := make(tsm1.Values, len(times))
+		for i, ts := range times {
+			values[i] = tsm1.NewValue(ts, fn(i))
+		}
+
+		b, err := values.Encode(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		data = append(data, b...)
+		exp = append(exp, values)
+	}
+
+	for i := range exp {
+		n, got, err := tsm1.DecodeBlockN(data, nil)
+		if err != nil {
+			t.Fatalf("unexpected error decoding block %d: %v", i, err)
+		}
+		if !reflect.DeepEqual(tsm1.Values(got), exp[i]) {
+			t.Fatalf("unexpected values of block %d: got %v, exp %v", i, got, exp[i])
+		}
+		data = data[n:]
+	}
+
+	if got, exp := len(data), 0; got != exp {
+		t.Fatalf("unexpected bytes remaining: got %d, exp %d", got, exp)
+	}
+}
+
+func TestEncoding_DecodeBlockN_ShortBlock(t *testing.T) {
+	values := tsm1.Values{tsm1.NewValue(0, "value"), tsm1.NewValue(1, "value")}
+	b, err := values.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, _, err := tsm1.DecodeBlockN(b[:len(b)-1], nil); err == nil {
+		t.Fatalf("expected error decoding short block, got nil")
+	}
+}
+
 func TestValues_MergeFloat(t *testing.T) {
 	tests := []struct {
 		a, b, exp []tsm1.Value
@@ -614,6 +857,461 @@ func TestValues_Contains(t *testing.T) {
 	}
 }
 
+func TestValues_FirstLast(t *testing.T) {
+	vals := tsm1.Values{
+		tsm1.NewValue(1, float64(1)),
+		tsm1.NewValue(2, float64(2)),
+		tsm1.NewValue(3, float64(3)),
+	}
+
+	if got, ok := vals.First(); !ok || got.UnixNano() != 1 {
+		t.Fatalf("unexpected first value: got %v, ok %t", got, ok)
+	}
+	if got, ok := vals.Last(); !ok || got.UnixNano() != 3 {
+		t.Fatalf("unexpected last value: got %v, ok %t", got, ok)
+	}
+
+	if got, ok := tsm1.Values(nil).First(); ok || got != nil {
+		t.Fatalf("expected no first value for empty values, got %v", got)
+	}
+	if got, ok := tsm1.Values(nil).Last(); ok || got != nil {
+		t.Fatalf("expected no last value for empty values, got %v", got)
+	}
+}
+
+func TestValues_TimeRange(t *testing.T) {
+	vals := tsm1.Values{
+		tsm1.NewValue(1, float64(1)),
+		tsm1.NewValue(2, float64(2)),
+		tsm1.NewValue(3, float64(3)),
+	}
+
+	if min, max, ok := vals.TimeRange(); !ok || min != 1 || max != 3 {
+		t.Fatalf("unexpected time range: got [%d, %d], ok %t", min, max, ok)
+	}
+	if min, max, ok := vals[1:2].TimeRange(); !ok || min != 2 || max != 2 {
+		t.Fatalf("unexpected time range of single value: got [%d, %d], ok %t", min, max, ok)
+	}
+	if _, _, ok := tsm1.Values(nil).TimeRange(); ok {
+		t.Fatal("expected no time range for empty values")
+	}
+}
+
+func TestValues_TypeCounts(t *testing.T) {
+	vals := tsm1.Values{
+		tsm1.NewValue(1, float64(1)),
+		tsm1.NewValue(2, int64(1)),
+		tsm1.NewValue(3, float64(2)),
+		tsm1.NewValue(4, "string"),
+		tsm1.NewValue(5, true),
+		tsm1.NewValue(6, uint64(1)),
+		tsm1.NewValue(7, float64(3)),
+	}
+
+	exp := map[byte]int{
+		tsm1.BlockFloat64:  3,
+		tsm1.BlockInteger:  1,
+		tsm1.BlockUnsigned: 1,
+		tsm1.BlockBoolean:  1,
+		tsm1.BlockString:   1,
+	}
+	if got := vals.TypeCounts(); !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected type counts: -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+
+	if got := tsm1.Values(nil).TypeCounts(); len(got) != 0 {
+		t.Fatalf("expected no type counts for empty values, got %v", got)
+	}
+}
+
+func TestValuesEqual(t *testing.T) {
+	vals := tsm1.Values{
+		tsm1.NewValue(1, math.Inf(-1)),
+		tsm1.NewValue(2, float64(2)),
+		tsm1.NewValue(3, math.Inf(1)),
+	}
+
+	b, err := vals.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := tsm1.DecodeBlock(b, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !tsm1.ValuesEqual(decoded, vals) {
+		t.Fatalf("expected decoded values to equal encoded values: got %v, exp %v", decoded, vals)
+	}
+
+	tests := []struct {
+		name string
+		a, b tsm1.Values
+		exp  bool
+	}{
+		{name: "empty", exp: true},
+		{
+			name: "different lengths",
+			a:    tsm1.Values{tsm1.NewValue(1, int64(1))},
+			exp:  false,
+		},
+		{
+			name: "different timestamps",
+			a:    tsm1.Values{tsm1.NewValue(1, int64(1))},
+			b:    tsm1.Values{tsm1.NewValue(2, int64(1))},
+			exp:  false,
+		},
+		{
+			name: "different types",
+			a:    tsm1.Values{tsm1.NewValue(1, int64(1))},
+			b:    tsm1.Values{tsm1.NewValue(1, uint64(1))},
+			exp:  false,
+		},
+		{
+			name: "different values",
+			a:    tsm1.Values{tsm1.NewValue(1, "a")},
+			b:    tsm1.Values{tsm1.NewValue(1, "b")},
+			exp:  false,
+		},
+		{
+			name: "NaN values",
+			a:    tsm1.Values{tsm1.NewValue(1, math.NaN())},
+			b:    tsm1.Values{tsm1.NewValue(1, math.NaN())},
+			exp:  true,
+		},
+		{
+			name: "NaN and number",
+			a:    tsm1.Values{tsm1.NewValue(1, math.NaN())},
+			b:    tsm1.Values{tsm1.NewValue(1, float64(0))},
+			exp:  false,
+		},
+		{
+			name: "all types",
+			a: tsm1.Values{
+				tsm1.NewValue(1, float64(1)),
+				tsm1.NewValue(2, int64(1)),
+				tsm1.NewValue(3, uint64(1)),
+				tsm1.NewValue(4, true),
+				tsm1.NewValue(5, "a"),
+			},
+			b: tsm1.Values{
+				tsm1.NewValue(1, float64(1)),
+				tsm1.NewValue(2, int64(1)),
+				tsm1.NewValue(3, uint64(1)),
+				tsm1.NewValue(4, true),
+				tsm1.NewValue(5, "a"),
+			},
+			exp: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tsm1.ValuesEqual(tt.a, tt.b); got != tt.exp {
+				t.Fatalf("unexpected result: got %t, exp %t", got, tt.exp)
+			}
+		})
+	}
+}
+
+func TestValues_DeduplicateReport(t *testing.T) {
+	cases := []struct {
+		n       string
+		values  tsm1.Values
+		exp     tsm1.Values
+		removed bool
+	}{
+		{"empty", tsm1.Values{}, tsm1.Values{}, false},
+		{
+			"sorted",
+			tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(2, int64(2))},
+			tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(2, int64(2))},
+			false,
+		},
+		{
+			"unsorted",
+			tsm1.Values{tsm1.NewValue(2, int64(2)), tsm1.NewValue(1, int64(1))},
+			tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(2, int64(2))},
+			false,
+		},
+		{
+			"duplicates keep last",
+			tsm1.Values{tsm1.NewValue(2, int64(2)), tsm1.NewValue(1, int64(1)), tsm1.NewValue(2, int64(3))},
+			tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(2, int64(3))},
+			true,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.n, func(t *testing.T) {
+			got, removed := tc.values.DeduplicateReport()
+			if !reflect.DeepEqual(got, tc.exp) {
+				t.Fatalf("unexpected values: got %v, exp %v", got, tc.exp)
+			}
+			if removed != tc.removed {
+				t.Fatalf("unexpected removed: got %v, exp %v", removed, tc.removed)
+			}
+		})
+	}
+}
+
+func TestValues_IsSorted(t *testing.T) {
+	cases := []struct {
+		n      string
+		values tsm1.Values
+		exp    bool
+	}{
+		{"empty", tsm1.Values{}, true},
+		{"single", tsm1.Values{tsm1.NewValue(1, int64(1))}, true},
+		{"sorted", tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(2, int64(2)), tsm1.NewValue(3, int64(3))}, true},
+		{"duplicates", tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(1, int64(2))}, true},
+		{"unsorted", tsm1.Values{tsm1.NewValue(1, int64(1)), tsm1.NewValue(3, int64(3)), tsm1.NewValue(2, int64(2))}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.n, func(t *testing.T) {
+			if got := tc.values.IsSorted(); got != tc.exp {
+				t.Fatalf("unexpected value: got %v, exp %v", got, tc.exp)
+			}
+		})
+	}
+}
+
+func TestValues_ContainsChecked(t *testing.T) {
+	sorted := tsm1.Values{tsm1.NewValue(10, int64(1)), tsm1.NewValue(20, int64(2)), tsm1.NewValue(30, int64(3))}
+
+	got, err := sorted.ContainsChecked(15, 25)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !got {
+		t.Fatalf("unexpected value: got %v, exp %v", got, true)
+	}
+
+	got, err = sorted.ContainsChecked(31, 40)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got {
+		t.Fatalf("unexpected value: got %v, exp %v", got, false)
+	}
+
+	unsorted := tsm1.Values{tsm1.NewValue(30, int64(3)), tsm1.NewValue(10, int64(1))}
+	if _, err := unsorted.ContainsChecked(15, 25); err != tsm1.ErrValuesNotSorted {
+		t.Fatalf("unexpected error: got %v, exp %v", err, tsm1.ErrValuesNotSorted)
+	}
+}
+
+func TestRepackBlocks(t *testing.T) {
+	encode := func(values ...tsm1.Value) []byte {
+		b, err := tsm1.Values(values).Encode(nil)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return b
+	}
+
+	blocks := [][]byte{
+		encode(tsm1.NewValue(1, 1.0), tsm1.NewValue(3, 3.0), tsm1.NewValue(5, 5.0)),
+		encode(tsm1.NewValue(2, 2.0), tsm1.NewValue(3, 30.0), tsm1.NewValue(6, 6.0)),
+		encode(tsm1.NewValue(4, 4.0)),
+	}
+
+	got, err := tsm1.RepackBlocks(blocks, 4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("unexpected block count: got %d, exp %d", len(got), 2)
+	}
+
+	var values []tsm1.Value
+	for _, b := range got {
+		decoded, err := tsm1.DecodeBlock(b, nil)
+		if err != nil {
+			t.Fatalf("unexpected error decoding block: %v", err)
+		}
+		values = append(values, decoded...)
+	}
+
+	exp := []tsm1.Value{
+		tsm1.NewValue(1, 1.0),
+		tsm1.NewValue(2, 2.0),
+		tsm1.NewValue(3, 30.0),
+		tsm1.NewValue(4, 4.0),
+		tsm1.NewValue(5, 5.0),
+		tsm1.NewValue(6, 6.0),
+	}
+	if !reflect.DeepEqual(values, exp) {
+		t.Fatalf("unexpected results:\n\tgot: %s\n\texp: %s\n", spew.Sdump(values), spew.Sdump(exp))
+	}
+}
+
+func TestRepackBlocks_MixedTypes(t *testing.T) {
+	fb, err := tsm1.Values{tsm1.NewValue(1, 1.0)}.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ib, err := tsm1.Values{tsm1.NewValue(2, int64(2))}.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, err := tsm1.RepackBlocks([][]byte{fb, ib}, 0); err == nil {
+		t.Fatal("expected error repacking mixed block types")
+	}
+}
+
+func TestValues_Split(t *testing.T) {
+	vals := make(tsm1.Values, 10)
+	for i := range vals {
+		vals[i] = tsm1.NewValue(int64(i), float64(i))
+	}
+
+	cases := []struct {
+		n    string
+		max  int
+		exp  []int
+		vals tsm1.Values
+	}{
+		{"even", 5, []int{5, 5}, vals},
+		{"remainder", 4, []int{4, 4, 2}, vals},
+		{"single chunk", 10, []int{10}, vals},
+		{"larger than values", 20, []int{10}, vals},
+		{"default size", 0, []int{10}, vals},
+		{"empty", 5, nil, nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.n, func(t *testing.T) {
+			chunks := tc.vals.Split(tc.max)
+
+			var got []int
+			var joined tsm1.Values
+			for _, chunk := range chunks {
+				got = append(got, len(chunk))
+				joined = append(joined, chunk...)
+			}
+			if !cmp.Equal(got, tc.exp) {
+				t.Fatalf("unexpected chunk sizes: -got/+exp\n%s", cmp.Diff(got, tc.exp))
+			}
+			if !reflect.DeepEqual(joined, tc.vals) {
+				t.Fatalf("unexpected values: got %v, exp %v", joined, tc.vals)
+			}
+		})
+	}
+
+	t.Run("appending to a chunk does not overwrite the next", func(t *testing.T) {
+		chunks := vals.Split(5)
+		_ = append(chunks[0], tsm1.NewValue(100, float64(100)))
+		if got := chunks[1][0].UnixNano(); got != 5 {
+			t.Fatalf("unexpected first value of second chunk: got %d, exp 5", got)
+		}
+	})
+}
+
+func TestValues_EncodeWithMax(t *testing.T) {
+	vals := make(tsm1.Values, 10)
+	for i := range vals {
+		vals[i] = tsm1.NewValue(int64(i), fmt.Sprintf("value %d", i))
+	}
+
+	blocks, err := vals.EncodeWithMax(4)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var got []int
+	var decoded tsm1.Values
+	for _, b := range blocks {
+		got = append(got, tsm1.BlockCount(b))
+		dec, err := tsm1.DecodeBlock(b, nil)
+		if err != nil {
+			t.Fatalf("unexpected error decoding block: %v", err)
+		}
+		decoded = append(decoded, dec...)
+	}
+	if exp := []int{4, 4, 2}; !cmp.Equal(got, exp) {
+		t.Fatalf("unexpected block counts: -got/+exp\n%s", cmp.Diff(got, exp))
+	}
+	if !tsm1.ValuesEqual(decoded, vals) {
+		t.Fatalf("unexpected values: got %v, exp %v", decoded, vals)
+	}
+
+	blocks, err = vals.EncodeWithMax(0)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(blocks) != 1 || tsm1.BlockCount(blocks[0]) != len(vals) {
+		t.Fatalf("expected a single block of %d values with the default max", len(vals))
+	}
+
+	mixed := append(tsm1.Values{tsm1.NewValue(100, int64(1))}, vals...)
+	if _, err := mixed.EncodeWithMax(1); err == nil {
+		t.Fatal("expected error encoding mixed value types across blocks")
+	}
+
+	if blocks, err := tsm1.Values(nil).EncodeWithMax(4); err != nil || blocks != nil {
+		t.Fatalf("expected no blocks for empty values, got %v, err %v", blocks, err)
+	}
+}
+
+func TestValues_EncodeWithCRC(t *testing.T) {
+	values := make(tsm1.Values, 100)
+	for i := range values {
+		values[i] = tsm1.NewValue(int64(i), float64(i))
+	}
+
+	block, err := values.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	crcBlock, err := values.EncodeWithCRC(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := tsm1.VerifyBlock(block); err != nil {
+		t.Fatalf("unexpected error verifying block without a checksum: %v", err)
+	}
+	if err := tsm1.VerifyBlock(crcBlock); err != nil {
+		t.Fatalf("unexpected error verifying block with a checksum: %v", err)
+	}
+
+	if got, err := tsm1.BlockType(crcBlock); err != nil || got != tsm1.BlockFloat64 {
+		t.Fatalf("unexpected block type: got %d, err %v", got, err)
+	}
+	if got, exp := tsm1.BlockCount(crcBlock), len(values); got != exp {
+		t.Fatalf("unexpected block count: got %d, exp %d", got, exp)
+	}
+
+	decoded, err := tsm1.DecodeBlock(crcBlock, nil)
+	if err != nil {
+		t.Fatalf("unexpected error decoding block: %v", err)
+	}
+	if !reflect.DeepEqual(tsm1.Values(decoded), values) {
+		t.Fatalf("unexpected values: got %v, exp %v", decoded, values)
+	}
+
+	data := append(append([]byte(nil), crcBlock...), block...)
+	n, _, err := tsm1.DecodeBlockN(data, nil)
+	if err != nil {
+		t.Fatalf("unexpected error decoding block with a checksum from many blocks: %v", err)
+	}
+	if got, exp := n, len(crcBlock); got != exp {
+		t.Fatalf("unexpected bytes consumed: got %d, exp %d", got, exp)
+	}
+
+	corrupt := append([]byte(nil), crcBlock...)
+	corrupt[len(corrupt)/2] ^= 0xff
+
+	if err := tsm1.VerifyBlock(corrupt); err == nil {
+		t.Fatal("expected error verifying corrupt block")
+	}
+	if _, err := tsm1.DecodeBlock(corrupt, nil); err == nil {
+		t.Fatal("expected error decoding corrupt block")
+	}
+}
+
 func TestIntegerValues_Merge(t *testing.T) {
 	integerValue := func(t int64, f int64) tsm1.IntegerValue {
 		return tsm1.NewValue(t, f).(tsm1.IntegerValue)
@@ -1516,6 +2214,28 @@ func BenchmarkDecodeBlock_Boolean_TypeSpecific(b *testing.B) {
 	}
 }
 
+func BenchmarkDecodeTimestamps(b *testing.B) {
+	valueCount := 1000
+	times := getTimes(valueCount, 60, time.Second)
+	values := make([]tsm1.Value, len(times))
+	for i, t := range times {
+		values[i] = tsm1.NewValue(t, float64(i))
+	}
+
+	bytes, err := tsm1.Values(values).Encode(nil)
+	if err != nil {
+		b.Fatalf("unexpected error: %v", err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, err = tsm1.DecodeTimestamps(bytes)
+		if err != nil {
+			b.Fatalf("unexpected error decoding timestamps: %v", err)
+		}
+	}
+}
+
 func BenchmarkDecodeBooleanBlock(b *testing.B) {
 	cases := []int{
 		5,
@@ -25,11 +25,31 @@ const floatCompressedGorilla = 1
 // uvnan is the constant returned from math.NaN().
 const uvnan = 0x7FF8000000000001
 
+// FloatCompression selects how a FloatEncoder chooses the window of meaningful
+// bits each value is written with. Every mode produces the same format, blocks
+// are decoded by the FloatDecoder regardless of the mode they were encoded with.
+type FloatCompression int
+
+const (
+	// FloatCompressionDefault reuses the window of the previous value whenever
+	// the value fits within it. This is the fastest mode and the mode used to
+	// encode the values of TSM blocks.
+	FloatCompressionDefault FloatCompression = iota
+
+	// FloatCompressionHigh writes a new window whenever it takes fewer bits than
+	// reusing the window of the previous value. Values that change slowly after
+	// a large change, such as sensor data, encode to smaller blocks at the cost
+	// of an additional comparison per value.
+	FloatCompressionHigh
+)
+
 // FloatEncoder encodes multiple float64s into a byte slice.
 type FloatEncoder struct {
 	val float64
 	err error
 
+	compression FloatCompression
+
 	leading  uint64
 	trailing uint64
 
@@ -68,6 +88,12 @@ func (s *FloatEncoder) Reset() {
 	s.first = true
 }
 
+// SetCompression sets the compression of the values written to the encoder.
+// The compression is kept by Reset.
+func (s *FloatEncoder) SetCompression(c FloatCompression) {
+	s.compression = c
+}
+
 // Bytes returns a copy of the underlying byte buffer used in the encoder.
 func (s *FloatEncoder) Bytes() ([]byte, error) {
 	return s.buf.Bytes(), s.err
@@ -114,8 +140,7 @@ func (s *FloatEncoder) Write(v float64) {
 			leading = 31
 		}
 
-		// TODO(dgryski): check if it's 'cheaper' to reset the leading/trailing bits instead
-		if s.leading != ^uint64(0) && leading >= s.leading && trailing >= s.trailing {
+		if s.leading != ^uint64(0) && leading >= s.leading && trailing >= s.trailing && !s.cheaperToReset(leading, trailing) {
 			s.bw.WriteBit(bitstream.Zero)
 			s.bw.WriteBits(vDelta>>s.trailing, 64-int(s.leading)-int(s.trailing))
 		} else {
@@ -138,6 +163,18 @@ func (s *FloatEncoder) Write(v float64) {
 	s.val = v
 }
 
+// cheaperToReset returns true if the high compression mode is set and writing
+// the control bits of a new window with the given leading and trailing zeros
+// takes fewer bits than writing the value within the window of the previous one.
+func (s *FloatEncoder) cheaperToReset(leading, trailing uint64) bool {
+	if s.compression != FloatCompressionHigh {
+		return false
+	}
+	reuse := 64 - s.leading - s.trailing
+	reset := 5 + 6 + (64 - leading - trailing)
+	return reset < reuse
+}
+
 // FloatDecoder decodes a byte slice into multiple float64 values.
 type FloatDecoder struct {
 	val uint64
@@ -278,3 +315,27 @@ func (it *FloatDecoder) Values() float64 {
 func (it *FloatDecoder) Error() error {
 	return it.err
 }
+
+// floatEncodedLen returns the number of bytes used to encode the floats at the
+// start of b. The length is not stored with the values, so they are decoded up
+// to the NaN that marks their end.
+func floatEncodedLen(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, fmt.Errorf("floatEncodedLen: not enough data for encoding type")
+	}
+
+	var dec FloatDecoder
+	if err := dec.SetBytes(b); err != nil {
+		return 0, err
+	}
+	for dec.Next() {
+	}
+	if err := dec.Error(); err != nil {
+		return 0, err
+	}
+
+	// The bit reader may have buffered bits past the end of the values. The
+	// encoder pads the last byte with zeros.
+	bits := (len(b)-1-len(dec.br.data))*8 - int(dec.br.buf.n)
+	return 1 + (bits+7)/8, nil
+}
@@ -322,3 +322,55 @@ func (d *IntegerDecoder) decodeUncompressed() {
 	d.n = 1
 	d.bytes = d.bytes[8:]
 }
+
+// integerEncodedLen returns the number of bytes used to encode the n integers
+// at the start of b.
+func integerEncodedLen(b []byte, n int) (int, error) {
+	if n == 0 {
+		return 0, nil
+	}
+	if len(b) == 0 {
+		return 0, fmt.Errorf("integerEncodedLen: not enough data for encoding type")
+	}
+
+	var i int
+	switch b[0] >> 4 {
+	case intUncompressed:
+		i = 1 + n*8
+	case intCompressedSimple:
+		// The first value is written unencoded, the rest are packed into 8 byte
+		// simple8b words until all n values are accounted for.
+		i = 1 + 8
+		for remaining := n - 1; remaining > 0; i += 8 {
+			if len(b) < i+8 {
+				return 0, fmt.Errorf("integerEncodedLen: not enough data for packed value")
+			}
+			count, err := simple8b.Count(binary.BigEndian.Uint64(b[i : i+8]))
+			if err != nil {
+				return 0, err
+			}
+			remaining -= count
+		}
+	case intCompressedRLE:
+		// The starting value is followed by the delta value and the number of
+		// times the delta repeats.
+		i = 1 + 8
+		for j := 0; j < 2; j++ {
+			if len(b) < i {
+				return 0, fmt.Errorf("integerEncodedLen: not enough data to decode RLE value")
+			}
+			_, sz := binary.Uvarint(b[i:])
+			if sz <= 0 {
+				return 0, fmt.Errorf("integerEncodedLen: invalid RLE value")
+			}
+			i += sz
+		}
+	default:
+		return 0, fmt.Errorf("unknown encoding %v", b[0]>>4)
+	}
+
+	if i > len(b) {
+		return 0, fmt.Errorf("integerEncodedLen: not enough data for %d values", n)
+	}
+	return i, nil
+}
@@ -0,0 +1,45 @@
+package tsm1
+
+// PackedBlockIterator iterates over the blocks of a buffer holding several
+// encoded blocks packed back to back. Unlike BlockIterator, which walks the
+// index of a TSM file, it finds where each block ends by walking the block
+// itself, and provides raw access to the block bytes without decoding them.
+type PackedBlockIterator struct {
+	data  []byte
+	block []byte
+	err   error
+}
+
+// NewPackedBlockIterator returns an iterator over the blocks packed in data.
+func NewPackedBlockIterator(data []byte) *PackedBlockIterator {
+	return &PackedBlockIterator{data: data}
+}
+
+// Next returns true if there are more blocks to iterate through. It returns
+// false once every block has been read or a block could not be read, in
+// which case Err returns the reason.
+func (b *PackedBlockIterator) Next() bool {
+	b.block = nil
+	if b.err != nil || len(b.data) == 0 {
+		return false
+	}
+
+	n, err := blockLen(b.data)
+	if err != nil {
+		b.err = err
+		return false
+	}
+	b.block, b.data = b.data[:n:n], b.data[n:]
+	return true
+}
+
+// Block returns the bytes of the current block. The returned slice refers to
+// the buffer being iterated and is valid until that buffer is modified.
+func (b *PackedBlockIterator) Block() []byte {
+	return b.block
+}
+
+// Err returns any error encountered during iteration.
+func (b *PackedBlockIterator) Err() error {
+	return b.err
+}
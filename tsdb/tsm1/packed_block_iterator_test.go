@@ -0,0 +1,69 @@
+package tsm1
+
+import "testing"
+
+func TestPackedBlockIterator(t *testing.T) {
+	blocks := []Values{
+		{NewValue(0, float64(1)), NewValue(1, float64(2))},
+		{NewValue(0, int64(1)), NewValue(1, int64(2)), NewValue(2, int64(3))},
+		{NewValue(0, true)},
+		{NewValue(0, "a"), NewValue(1, "b"), NewValue(2, "c"), NewValue(3, "d")},
+		{NewValue(0, uint64(1)), NewValue(1, uint64(2))},
+	}
+
+	var data []byte
+	for _, values := range blocks {
+		b, err := values.Encode(nil)
+		if err != nil {
+			t.Fatalf("unexpected error encoding: %v", err)
+		}
+		data = append(data, b...)
+	}
+
+	iter := NewPackedBlockIterator(data)
+	var i int
+	for iter.Next() {
+		if i >= len(blocks) {
+			t.Fatalf("unexpected block %d", i)
+		}
+
+		block := iter.Block()
+		exp, _ := blockTypeOf(blocks[i][0])
+		if got, err := BlockType(block); err != nil || got != exp {
+			t.Fatalf("unexpected type of block %d: got %d, exp %d, err %v", i, got, exp, err)
+		}
+		if got, exp := BlockCount(block), len(blocks[i]); got != exp {
+			t.Fatalf("unexpected count of block %d: got %d, exp %d", i, got, exp)
+		}
+		i++
+	}
+
+	if err := iter.Err(); err != nil {
+		t.Fatalf("unexpected error iterating: %v", err)
+	}
+	if got, exp := i, len(blocks); got != exp {
+		t.Fatalf("block count mismatch: got %d, exp %d", got, exp)
+	}
+}
+
+func TestPackedBlockIterator_ShortBlock(t *testing.T) {
+	values := Values{NewValue(0, int64(1)), NewValue(1, int64(2))}
+	b, err := values.Encode(nil)
+	if err != nil {
+		t.Fatalf("unexpected error encoding: %v", err)
+	}
+
+	data := append(append([]byte(nil), b...), b[:len(b)-1]...)
+	iter := NewPackedBlockIterator(data)
+
+	var n int
+	for iter.Next() {
+		n++
+	}
+	if got, exp := n, 1; got != exp {
+		t.Fatalf("block count mismatch: got %d, exp %d", got, exp)
+	}
+	if iter.Err() == nil {
+		t.Fatal("expected error iterating short block")
+	}
+}
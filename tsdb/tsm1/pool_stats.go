@@ -0,0 +1,88 @@
+package tsm1
+
+import (
+	"os"
+	"sort"
+	"sync/atomic"
+
+	"github.com/influxdata/influxdb/pkg/pool"
+)
+
+// poolDebugEnabled enables tracking of the gets and puts of the encoder and
+// decoder pools, as reported by PoolStats.
+var poolDebugEnabled = os.Getenv("INFLUXDB_EXP_TSM1_POOL_DEBUG") != ""
+
+// trackedPools are all the pools created with newTrackedPool.
+var trackedPools []*trackedPool
+
+// trackedPool is a pool.Generic that counts its gets and puts when pool
+// debugging is enabled. An encoder or decoder that is taken from the pool
+// and never returned shows up as a get without a matching put.
+type trackedPool struct {
+	// gets and puts are accessed atomically and must remain 64-bit aligned.
+	gets int64
+	puts int64
+
+	*pool.Generic
+	name string
+}
+
+func newTrackedPool(name string, max int, fn func(sz int) interface{}) *trackedPool {
+	p := &trackedPool{
+		Generic: pool.NewGeneric(max, fn),
+		name:    name,
+	}
+	trackedPools = append(trackedPools, p)
+	return p
+}
+
+// Get returns an item from the pool or a new instance if the pool is empty.
+func (p *trackedPool) Get(sz int) interface{} {
+	if poolDebugEnabled {
+		atomic.AddInt64(&p.gets, 1)
+	}
+	return p.Generic.Get(sz)
+}
+
+// Put returns an item back to the pool.
+func (p *trackedPool) Put(c interface{}) {
+	if poolDebugEnabled {
+		atomic.AddInt64(&p.puts, 1)
+	}
+	p.Generic.Put(c)
+}
+
+// PoolStat reports the gets and puts of one of the encoder or decoder pools.
+type PoolStat struct {
+	Name string
+	Gets int64
+	Puts int64
+}
+
+// Outstanding returns the number of items taken from the pool that have not
+// been returned to it. A count that keeps growing indicates a leak.
+func (s PoolStat) Outstanding() int64 {
+	return s.Gets - s.Puts
+}
+
+// PoolStats returns the gets and puts of every encoder and decoder pool, ordered
+// by pool name. The counts are only tracked when the INFLUXDB_EXP_TSM1_POOL_DEBUG
+// environment variable is set, otherwise PoolStats returns nil.
+func PoolStats() []PoolStat {
+	if !poolDebugEnabled {
+		return nil
+	}
+
+	stats := make([]PoolStat, 0, len(trackedPools))
+	for _, p := range trackedPools {
+		stats = append(stats, PoolStat{
+			Name: p.name,
+			Gets: atomic.LoadInt64(&p.gets),
+			Puts: atomic.LoadInt64(&p.puts),
+		})
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].Name < stats[j].Name
+	})
+	return stats
+}
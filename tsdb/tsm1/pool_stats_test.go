@@ -0,0 +1,53 @@
+package tsm1
+
+import (
+	"testing"
+)
+
+func TestPoolStats(t *testing.T) {
+	if got := PoolStats(); !poolDebugEnabled && got != nil {
+		t.Fatalf("expected no pool stats with pool debugging disabled, got %v", got)
+	}
+
+	enabled := poolDebugEnabled
+	poolDebugEnabled = true
+	defer func() { poolDebugEnabled = enabled }()
+
+	outstanding := func(name string) int64 {
+		t.Helper()
+		for _, s := range PoolStats() {
+			if s.Name == name {
+				return s.Outstanding()
+			}
+		}
+		t.Fatalf("no stats for pool %q", name)
+		return 0
+	}
+
+	before := outstanding("time_encoder")
+
+	putTimeEncoder(getTimeEncoder(MaxPointsPerBlock))
+	if got := outstanding("time_encoder"); got != before {
+		t.Fatalf("unexpected outstanding time encoders: got %d, exp %d", got, before)
+	}
+
+	leaked := getTimeEncoder(MaxPointsPerBlock)
+	if got, exp := outstanding("time_encoder"), before+1; got != exp {
+		t.Fatalf("unexpected outstanding time encoders: got %d, exp %d", got, exp)
+	}
+
+	putTimeEncoder(leaked)
+	if got := outstanding("time_encoder"); got != before {
+		t.Fatalf("unexpected outstanding time encoders: got %d, exp %d", got, before)
+	}
+
+	stats := PoolStats()
+	if len(stats) != 10 {
+		t.Fatalf("unexpected number of pools: got %d, exp 10", len(stats))
+	}
+	for i := 1; i < len(stats); i++ {
+		if stats[i-1].Name >= stats[i].Name {
+			t.Fatalf("pool stats not ordered by name: %q before %q", stats[i-1].Name, stats[i].Name)
+		}
+	}
+}
@@ -127,3 +127,64 @@ func (e *StringDecoder) Read() string {
 func (e *StringDecoder) Error() error {
 	return e.err
 }
+
+// stringEncodedLen returns the number of bytes used to encode the strings at
+// the start of b. The elements of the snappy block holding the strings are
+// walked, without decompressing them, until the decoded length is reached.
+func stringEncodedLen(b []byte) (int, error) {
+	if len(b) == 0 {
+		return 0, fmt.Errorf("stringEncodedLen: not enough data for encoding type")
+	}
+
+	src := b[1:]
+	dLen, s := binary.Uvarint(src)
+	if s <= 0 {
+		return 0, fmt.Errorf("stringEncodedLen: invalid decoded length")
+	}
+
+	for d := uint64(0); d < dLen; {
+		if s >= len(src) {
+			return 0, fmt.Errorf("stringEncodedLen: not enough data for snappy block")
+		}
+
+		var length int
+		switch src[s] & 0x03 {
+		case 0x00:
+			// Literals of more than 60 bytes store their length minus one in
+			// the 1 to 4 bytes following the tag.
+			x := uint64(src[s] >> 2)
+			s++
+			if x >= 60 {
+				k := int(x) - 59
+				if s+k > len(src) {
+					return 0, fmt.Errorf("stringEncodedLen: not enough data for snappy block")
+				}
+				x = 0
+				for j := 0; j < k; j++ {
+					x |= uint64(src[s+j]) << (8 * uint(j))
+				}
+				s += k
+			}
+			if x >= uint64(len(src)) {
+				return 0, fmt.Errorf("stringEncodedLen: not enough data for snappy block")
+			}
+			length = int(x) + 1
+			s += length
+		case 0x01:
+			length = 4 + int(src[s]>>2&0x07)
+			s += 2
+		case 0x02:
+			length = 1 + int(src[s]>>2)
+			s += 3
+		default:
+			length = 1 + int(src[s]>>2)
+			s += 5
+		}
+		d += uint64(length)
+	}
+
+	if s > len(src) {
+		return 0, fmt.Errorf("stringEncodedLen: not enough data for snappy block")
+	}
+	return 1 + s, nil
+}